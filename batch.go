@@ -0,0 +1,352 @@
+package nsojsonrpcrequestergo
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// DefaultBatchMaxSize is the most calls sent in one batch request unless SetMaxSize is used
+const DefaultBatchMaxSize = 100
+
+// BatchCallResult holds the result of one call in a Batch
+type BatchCallResult struct {
+	// Method is the JSON-RPC method of the call
+	Method string
+	// Response is the response to the call
+	Response *NsoJsonResponse
+	// Err is the error of the call, like a *NsoRpcError, or nil
+	Err error
+}
+
+// Batch queues calls on a NSO Transaction and sends them as JSON-RPC 2.0 batch requests
+// a batch request is sent once, a failed one is not retried using the RetryPolicy of SetRetryPolicy
+// and an expired session is not logged in again by SetAutoRelogin, the error is returned to the caller
+// the calls are sent with the handle the transaction has when Send runs
+type Batch struct {
+	trans   *Transaction
+	calls   []rpcParam
+	maxSize int
+}
+
+// Method to create a Batch for the NSO Transaction
+func (trans *Transaction) NewBatch() *Batch {
+	return &Batch{trans: trans, maxSize: DefaultBatchMaxSize}
+
+}
+
+// Method to create a Batch for the current NSO Transaction
+func (config *NsoJsonRpcConfig) NewBatch() (*Batch, error) {
+	trans, err := config.transaction()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return trans.NewBatch(), nil
+
+}
+
+// Method to set the most calls sent in one batch request
+// more calls are split over several requests
+//   :values size: 1 or more
+func (b *Batch) SetMaxSize(size int) error {
+	if size < 1 {
+		return errors.New("batch max size must be at least 1")
+	}
+
+	b.maxSize = size
+
+	return nil
+
+}
+
+// Method to get the number of queued calls
+func (b *Batch) Len() int {
+	return len(b.calls)
+
+}
+
+// Method to queue a call
+//   :values method: The JSON-RPC method
+//   :values params: The params without the transaction handle
+func (b *Batch) add(method string, params map[string]interface{}) int {
+	b.calls = append(b.calls, rpcParam{
+		"jsonrpc": "2.0",
+		"method":  method,
+		"params":  params,
+	})
+
+	return len(b.calls) - 1
+
+}
+
+// Method to queue getting a leaf value, the index of the call is returned
+//   :values path: A key path
+//   :values checkDefault: true to check for default value false to not
+func (b *Batch) GetValue(path string, checkDefault bool) int {
+	return b.add("get_value", map[string]interface{}{
+		"path":          path,
+		"check_default": checkDefault,
+	})
+
+}
+
+// Method to queue getting multiple leaf values, the index of the call is returned
+//   :values path: A key path
+//   :values leafs: A array of leafs
+//   :values checkDefault: true to check for default value false to not
+func (b *Batch) GetValues(path string, leafs []string, checkDefault bool) int {
+	return b.add("get_values", map[string]interface{}{
+		"path":          path,
+		"check_default": checkDefault,
+		"leafs":         leafs,
+	})
+
+}
+
+// Method to queue checking if a leaf exists, the index of the call is returned
+//   :values path: A key path
+func (b *Batch) Exists(path string) int {
+	return b.add("exists", map[string]interface{}{
+		"path": path,
+	})
+
+}
+
+// Method to queue setting a leaf value, the index of the call is returned
+//   :values path: A key path
+//   :values value: The value to set
+//   :values dryRun: true to only check the value false to set it
+func (b *Batch) SetValue(path string, value interface{}, dryRun bool) int {
+	return b.add("set_value", map[string]interface{}{
+		"path":   path,
+		"value":  value,
+		"dryrun": dryRun,
+	})
+
+}
+
+// Method to queue creating a leaf, the index of the call is returned
+//   :values path: A key path
+func (b *Batch) Create(path string) int {
+	return b.add("create", map[string]interface{}{
+		"path": path,
+	})
+
+}
+
+// Method to queue deleting a leaf, the index of the call is returned
+//   :values path: A key path
+func (b *Batch) Delete(path string) int {
+	return b.add("delete", map[string]interface{}{
+		"path": path,
+	})
+
+}
+
+// Method to send the queued calls, the results are in the order the calls were queued
+// the queue is empty afterwards
+func (b *Batch) Send() ([]BatchCallResult, error) {
+	results, err := b.SendContext(context.Background())
+
+	if err != nil {
+		return results, err
+	}
+
+	return results, nil
+}
+
+// Method to send the queued calls using a context, the results are in the order the calls were queued
+// the queue is empty afterwards
+// an error is returned when a batch request fails, the errors of single calls are in the results
+//   :values ctx: A context.Context to cancel the request
+func (b *Batch) SendContext(ctx context.Context) ([]BatchCallResult, error) {
+	calls := b.calls
+	b.calls = nil
+
+	err := b.trans.nsocon.checkTransactionLost(b.trans)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// The handle is read now as a re-login after the calls were queued changes the handle of a read transaction
+	th := b.trans.handle()
+
+	for _, call := range calls {
+		call["params"].(map[string]interface{})["th"] = th
+	}
+
+	results := make([]BatchCallResult, 0, len(calls))
+
+	for start := 0; start < len(calls); start += b.maxSize {
+		end := start + b.maxSize
+		if end > len(calls) {
+			end = len(calls)
+		}
+
+		chunk, err := b.trans.nsocon.sendBatchContext(ctx, calls[start:end])
+
+		results = append(results, chunk...)
+
+		if err != nil {
+			return results, err
+		}
+	}
+
+	return results, nil
+
+}
+
+// Method to send calls as one JSON-RPC batch request
+//   :values ctx: A context.Context to cancel the request
+//   :values params: The rpcParam of each call
+func (nsoJson *nsoJsonConnection) sendBatchContext(ctx context.Context, params []rpcParam) ([]BatchCallResult, error) {
+	secretValues := false
+
+	for _, param := range params {
+		id := nsoJson.startRequest(param)
+		defer nsoJson.finishRequest(id)
+
+		secretValues = secretValues || isSecretParam(param)
+	}
+
+	// The batch is logged with the id and method of each call so a failure can be matched to them
+	batchArgs := batchLogArgs(params)
+
+	request, err := nsoJson.getJsonRequest(params)
+
+	if err != nil {
+		return nil, err
+	}
+
+	nsoJson.dumpWireArgs("request", batchArgs, request.Body, secretValues)
+
+	start := time.Now()
+
+	transportResponse, err := nsoJson.transport.Send(ctx, request)
+
+	if err != nil {
+		nsoJson.logger.Warn("nso json-rpc request failed", append(batchArgs, "duration", time.Since(start), "error", err)...)
+		return nil, &transportError{err: err}
+	}
+
+	nsoJson.dumpWireArgs("response", batchArgs, transportResponse.Body, secretValues)
+
+	bodies, err := nsoJson.checkBatchResponse(transportResponse)
+
+	if err != nil {
+		nsoJson.logRequestArgs(batchArgs, time.Since(start), transportResponse.StatusCode, err)
+		return nil, err
+	}
+
+	duration := time.Since(start)
+	results := make([]BatchCallResult, 0, len(params))
+
+	for _, param := range params {
+		method, _ := param["method"].(string)
+		id, _ := param["id"].(int)
+
+		result := BatchCallResult{Method: method}
+
+		body, ok := bodies[id]
+
+		if ok {
+			result.Response, result.Err = nsoJson.checkResponse(param, &TransportResponse{StatusCode: transportResponse.StatusCode, Header: transportResponse.Header, Body: body})
+		} else {
+			result.Err = fmt.Errorf("no response in the batch for request id %d for %s", id, method)
+		}
+
+		nsoJson.logRequest(method, id, duration, transportResponse.StatusCode, result.Err)
+
+		results = append(results, result)
+	}
+
+	return results, nil
+
+}
+
+// batchLogArgs gets the log members that name a batch request, the id and method of each call
+//   :values params: The rpcParam of each call
+func batchLogArgs(params []rpcParam) []interface{} {
+	ids := make([]int, 0, len(params))
+	methods := make([]string, 0, len(params))
+
+	for _, param := range params {
+		id, _ := param["id"].(int)
+		method, _ := param["method"].(string)
+
+		ids = append(ids, id)
+		methods = append(methods, method)
+	}
+
+	return []interface{}{"method", "batch", "ids", ids, "methods", methods}
+
+}
+
+// Method to check a batch response and split it into the response of each call by id
+//   :values transportResponse: The *TransportResponse that came back
+func (nsoJson *nsoJsonConnection) checkBatchResponse(transportResponse *TransportResponse) (map[int]json.RawMessage, error) {
+	statusCode := transportResponse.StatusCode
+
+	if statusCode < 200 || statusCode > 299 {
+		return nil, &HTTPStatusError{StatusCode: statusCode, Method: "batch"}
+	}
+
+	body := bytes.TrimSpace(transportResponse.Body)
+
+	// A server that can not handle the batch answers with a single error that has no request id
+	if len(body) == 0 || body[0] != '[' {
+		var envelope nsoJsonEnvelope
+
+		err := json.Unmarshal(body, &envelope)
+
+		if err != nil {
+			return nil, fmt.Errorf("could not decode the JSON-RPC batch response: %w", err)
+		}
+
+		if len(envelope.Error) == 0 || string(envelope.Error) == "null" {
+			return nil, errors.New("expected a JSON-RPC batch response")
+		}
+
+		id := 0
+		if envelope.ID != nil {
+			id = *envelope.ID
+		}
+
+		rpcErr, err := newNsoRpcError("batch", id, envelope.Error)
+
+		if err != nil {
+			return nil, fmt.Errorf("could not decode the JSON-RPC error for batch: %w", err)
+		}
+
+		return nil, rpcErr
+	}
+
+	var members []json.RawMessage
+
+	err := json.Unmarshal(body, &members)
+
+	if err != nil {
+		return nil, fmt.Errorf("could not decode the JSON-RPC batch response: %w", err)
+	}
+
+	bodies := make(map[int]json.RawMessage)
+
+	for _, member := range members {
+		var envelope nsoJsonEnvelope
+
+		if json.Unmarshal(member, &envelope) != nil || envelope.ID == nil {
+			continue
+		}
+
+		bodies[*envelope.ID] = member
+	}
+
+	return bodies, nil
+
+}
@@ -0,0 +1,227 @@
+package nsojsonrpcrequestergo
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/btr1975/nsojsonrpcrequestergo/nsomock"
+)
+
+// countingTransport counts the requests sent through a Transport
+type countingTransport struct {
+	transport Transport
+	sent      int
+}
+
+func (c *countingTransport) Send(ctx context.Context, request *TransportRequest) (*TransportResponse, error) {
+	c.sent++
+
+	return c.transport.Send(ctx, request)
+}
+
+func Test_Batch_Send(t *testing.T) {
+	server := nsomock.NewServer()
+	defer server.Close()
+
+	server.SetValue("/ncs:devices/device{r1}/address", "10.0.0.1")
+
+	transport := &countingTransport{transport: NewHTTPTransport(false)}
+
+	config, _ := NewNsoJsonRpcConfigWithTransport("http", server.Host(), server.Port(), "admin", "admin", transport)
+	_ = config.NsoLogin()
+	_, _ = config.NewTransaction("read_write", "private", "", "reuse")
+
+	batch, err := config.NewBatch()
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	if batch.SetMaxSize(0) == nil {
+		t.Errorf("expected an error for a max size of 0")
+	}
+	_ = batch.SetMaxSize(2)
+
+	batch.SetValue("/ncs:devices/device{r2}/address", "10.0.0.2", false)
+	batch.GetValue("/ncs:devices/device{r1}/address", false)
+	batch.GetValue("/ncs:devices/device{r9}/address", false)
+	batch.Exists("/ncs:devices/device{r2}")
+	batch.GetValue("/ncs:devices/device{r2}/address", false)
+
+	if batch.Len() != 5 {
+		t.Errorf("expected %v got %v", 5, batch.Len())
+	}
+
+	transport.sent = 0
+
+	results, err := batch.Send()
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	if transport.sent != 3 {
+		t.Errorf("expected %v requests got %v", 3, transport.sent)
+	}
+
+	if batch.Len() != 0 {
+		t.Errorf("expected %v got %v", 0, batch.Len())
+	}
+
+	scenarios := []struct {
+		method string
+		result map[string]interface{}
+		rcvErr bool
+	}{
+		{method: "set_value", result: map[string]interface{}{}},
+		{method: "get_value", result: map[string]interface{}{"value": "10.0.0.1"}},
+		{method: "get_value", rcvErr: true},
+		{method: "exists", result: map[string]interface{}{"exists": true}},
+		{method: "get_value", result: map[string]interface{}{"value": "10.0.0.2"}},
+	}
+
+	if len(results) != len(scenarios) {
+		t.Fatalf("expected %v got %v", len(scenarios), len(results))
+	}
+
+	for i, scenario := range scenarios {
+		if results[i].Method != scenario.method {
+			t.Errorf("expected %v got %v", scenario.method, results[i].Method)
+		}
+
+		var rpcErr *NsoRpcError
+		if scenario.rcvErr {
+			if !errors.As(results[i].Err, &rpcErr) || rpcErr.Type != "data.not_found" {
+				t.Errorf("expected %v got %v", "data.not_found", results[i].Err)
+			}
+			continue
+		}
+
+		if results[i].Err != nil || !reflect.DeepEqual(results[i].Response.Result, scenario.result) {
+			t.Errorf("expected %v got %v %v", scenario.result, results[i].Response, results[i].Err)
+		}
+	}
+
+}
+
+// staticTransport answers every request with the same reply
+type staticTransport struct {
+	status int
+	body   string
+	err    error
+}
+
+func (s *staticTransport) Send(ctx context.Context, request *TransportRequest) (*TransportResponse, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+
+	return &TransportResponse{StatusCode: s.status, Body: []byte(s.body)}, nil
+}
+
+func Test_Batch_Send_errors(t *testing.T) {
+	scenarios := []struct {
+		transport *staticTransport
+		rcvError  error
+	}{
+		{transport: &staticTransport{status: 503}, rcvError: errors.New("nso server returned HTTP status 503 for batch")},
+		{transport: &staticTransport{err: errors.New("connection reset")}, rcvError: errors.New("connection reset")},
+		{transport: &staticTransport{status: 200, body: `{"jsonrpc": "2.0", "result": {}}`}, rcvError: errors.New("expected a JSON-RPC batch response")},
+		{transport: &staticTransport{status: 200, body: `{"jsonrpc": "2.0", "error": {"code": -32600, "type": "rpc.request.invalid", "message": "Invalid request"}}`}, rcvError: errors.New("nso json-rpc error in batch: rpc.request.invalid: Invalid request (code -32600)")},
+		{transport: &staticTransport{status: 200, body: `{"jsonrpc": "2.0", "id": 5, "error": {"code": -32000, "type": "session.invalid_sessionid", "message": "Invalid sessionid"}}`}, rcvError: errors.New("nso json-rpc error in batch: session.invalid_sessionid: Invalid sessionid (code -32000)")},
+		{transport: &staticTransport{status: 200, body: `{"jsonrpc": "2.0", "id": null, "error": {"code": -32700, "type": "rpc.request.parse_error", "message": "Parse error"}}`}, rcvError: errors.New("nso json-rpc error in batch: rpc.request.parse_error: Parse error (code -32700)")},
+		{transport: &staticTransport{status: 200, body: `not json`}, rcvError: errors.New("could not decode the JSON-RPC batch response: invalid character 'o' in literal null (expecting 'u')")},
+		{transport: &staticTransport{status: 200, body: `[]`}, rcvError: nil},
+	}
+
+	for _, scenario := range scenarios {
+		config, _ := NewNsoJsonRpcConfigWithTransport("http", "192.168.1.1", 8080, "admin", "admin", scenario.transport)
+		trans := newTransaction(config.nsocon, 1, "running", "read", "", "", "")
+
+		batch := trans.NewBatch()
+		batch.GetValue("/ncs:devices/device{r1}/address", false)
+
+		results, err := batch.Send()
+		if err != scenario.rcvError {
+			if err == nil || scenario.rcvError == nil || err.Error() != scenario.rcvError.Error() {
+				t.Errorf("expected error %v got %v", scenario.rcvError, err)
+			}
+		}
+
+		// A call without a response in the batch gets an error of its own
+		if err == nil && (len(results) != 1 || results[0].Err == nil) {
+			t.Errorf("expected an error for the call got %v", results)
+		}
+	}
+
+}
+
+func Test_Batch_Send_afterRelogin(t *testing.T) {
+	server := nsomock.NewServer()
+	defer server.Close()
+
+	server.SetValue("/ncs:devices/device{r1}/address", "10.0.0.1")
+
+	config, _ := NewNsoJsonRpcConfig("http", server.Host(), server.Port(), "admin", "admin", false)
+	config.SetAutoRelogin(true)
+
+	_ = config.NsoLogin()
+	trans, _ := config.NewTransaction("read", "private", "", "reuse")
+
+	batch := trans.NewBatch()
+	batch.GetValue("/ncs:devices/device{r1}/address", false)
+
+	server.ExpireSessions()
+
+	// The re-login starts the read transaction again with a new handle
+	_, _ = trans.Exists("/ncs:devices/device{r1}")
+
+	results, err := batch.Send()
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	if results[0].Err != nil || results[0].Response.Result["value"] != "10.0.0.1" {
+		t.Errorf("expected %v got %v", "10.0.0.1", results[0])
+	}
+
+}
+
+func Test_Batch_Send_logs(t *testing.T) {
+	logger := &testRecordLogger{}
+
+	config, _ := NewNsoJsonRpcConfigWithTransport("http", "192.168.1.1", 8080, "admin", "admin", &staticTransport{err: errors.New("connection reset")})
+	config.SetLogger(logger)
+	config.SetWireDump(true)
+	trans := newTransaction(config.nsocon, 1, "running", "read", "", "", "")
+
+	batch := trans.NewBatch()
+	batch.Exists("/ncs:devices/device{r1}")
+	batch.GetValue("/ncs:devices/device{r1}/address", false)
+
+	_, err := batch.Send()
+	if err == nil {
+		t.Fatalf("expected an error got %v", err)
+	}
+
+	// Both the wire dump and the failure name every call of the batch
+	if len(logger.entries) != 2 {
+		t.Fatalf("expected %v got %v", 2, logger.entries)
+	}
+
+	for _, entry := range logger.entries {
+		ids, _ := entry.args["ids"].([]int)
+		if len(ids) != 2 || ids[1] != ids[0]+1 {
+			t.Errorf("expected %v got %v", "two ids", entry.args["ids"])
+		}
+
+		if !reflect.DeepEqual(entry.args["methods"], []string{"exists", "get_value"}) {
+			t.Errorf("expected %v got %v", []string{"exists", "get_value"}, entry.args["methods"])
+		}
+	}
+
+	if logger.entries[1].level != "warn" || logger.entries[1].args["error"] == nil {
+		t.Errorf("expected %v got %v", "a failure warning", logger.entries[1])
+	}
+
+}
@@ -0,0 +1,391 @@
+package nsojsonrpcrequestergo
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+)
+
+// ErrCassetteMismatch is returned by a ReplayTransport when no recorded request matches
+var ErrCassetteMismatch = errors.New("no recorded nso json-rpc request matches")
+
+// volatileParams are params left out when matching a request, like the random comet id
+var volatileParams = []string{"comet_id"}
+
+// CassetteEntry holds one recorded request and response, a cassette file has one per line
+type CassetteEntry struct {
+	Method     string          `json:"method"`
+	Params     json.RawMessage `json:"params,omitempty"`
+	StatusCode int             `json:"status_code"`
+	Response   json.RawMessage `json:"response"`
+}
+
+// newCassetteEntry creates a CassetteEntry with the secrets redacted
+//   :values request: The *TransportRequest sent
+//   :values response: The *TransportResponse received
+func newCassetteEntry(request *TransportRequest, response *TransportResponse) (*CassetteEntry, error) {
+	method, params, secretValues, err := cassetteRequest(request.Body)
+
+	if err != nil {
+		return nil, err
+	}
+
+	entry := &CassetteEntry{Method: method, Params: params, StatusCode: response.StatusCode}
+
+	// A body that is not JSON, like an error page, is kept as a JSON string
+	if json.Valid(response.Body) {
+		entry.Response = json.RawMessage(redactBody(response.Body, secretValues))
+	} else {
+		entry.Response, err = json.Marshal(string(response.Body))
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return entry, nil
+
+}
+
+// cassetteRequest gets the method and the redacted params of a request body
+// the params are returned in a form that can be compared
+// a batch has the method batch and the method and params of each call as its params
+//   :values body: The JSON-RPC request body
+func cassetteRequest(body []byte) (string, json.RawMessage, bool, error) {
+	var data interface{}
+
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+
+	err := decoder.Decode(&data)
+
+	if err != nil {
+		return "", nil, false, err
+	}
+
+	var method string
+	var params interface{}
+	var secretValues bool
+
+	switch request := data.(type) {
+	case map[string]interface{}:
+		method, params, secretValues = cassetteCall(request)
+
+	case []interface{}:
+		var calls []interface{}
+
+		for _, member := range request {
+			call, _ := member.(map[string]interface{})
+			callMethod, callParams, callSecretValues := cassetteCall(call)
+
+			calls = append(calls, map[string]interface{}{"method": callMethod, "params": callParams})
+			secretValues = secretValues || callSecretValues
+		}
+
+		method, params = "batch", calls
+
+	default:
+		return "", nil, false, errors.New("a JSON-RPC request must be an object or an array")
+	}
+
+	if params == nil {
+		return method, nil, secretValues, nil
+	}
+
+	raw, err := json.Marshal(params)
+
+	if err != nil {
+		return "", nil, false, err
+	}
+
+	return method, raw, secretValues, nil
+
+}
+
+// cassetteCall gets the method and the redacted params of a single call
+//   :values param: The decoded call
+func cassetteCall(param rpcParam) (string, interface{}, bool) {
+	method, _ := param["method"].(string)
+	secretValues := isSecretParam(param)
+
+	params, ok := param["params"]
+
+	if !ok {
+		return method, nil, secretValues
+	}
+
+	params = redactValue(params, secretValues)
+
+	if paramsMap, ok := params.(map[string]interface{}); ok {
+		for _, name := range volatileParams {
+			delete(paramsMap, name)
+		}
+	}
+
+	return method, params, secretValues
+
+}
+
+// RecordingTransport is a Transport that writes every request and response to a JSONL cassette file
+// passwords and other secrets are redacted
+type RecordingTransport struct {
+	transport Transport
+	lock      sync.Mutex
+	file      *os.File
+	encoder   *json.Encoder
+}
+
+// Constructor for a RecordingTransport, the cassette file is created or truncated
+//   :values transport: The Transport that reaches the NSO Server, like a *HTTPTransport
+//   :values path: The path of the cassette file
+func NewRecordingTransport(transport Transport, path string) (*RecordingTransport, error) {
+	if transport == nil {
+		return &RecordingTransport{}, errors.New("a transport is required")
+	}
+
+	file, err := os.Create(path)
+
+	if err != nil {
+		return &RecordingTransport{}, err
+	}
+
+	return &RecordingTransport{transport: transport, file: file, encoder: json.NewEncoder(file)}, nil
+
+}
+
+// Method to send a JSON-RPC envelope and record it
+//   :values ctx: A context.Context to cancel the request
+//   :values request: A *TransportRequest
+func (t *RecordingTransport) Send(ctx context.Context, request *TransportRequest) (*TransportResponse, error) {
+	response, err := t.transport.Send(ctx, request)
+
+	if err != nil {
+		return response, err
+	}
+
+	entry, err := newCassetteEntry(request, response)
+
+	if err != nil {
+		return response, err
+	}
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	err = t.encoder.Encode(entry)
+
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+
+}
+
+// Method to close the cassette file
+func (t *RecordingTransport) Close() error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	return t.file.Close()
+
+}
+
+// ReplayTransport is a Transport that answers from a cassette file written by a RecordingTransport
+// a request is matched on its method and params, each recorded response is used once in order
+type ReplayTransport struct {
+	lock    sync.Mutex
+	entries []*CassetteEntry
+	played  []bool
+}
+
+// Constructor for a ReplayTransport from a cassette file
+//   :values path: The path of the cassette file
+func NewReplayTransport(path string) (*ReplayTransport, error) {
+	file, err := os.Open(path)
+
+	if err != nil {
+		return &ReplayTransport{}, err
+	}
+
+	defer file.Close()
+
+	var entries []*CassetteEntry
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+
+	for line := 1; scanner.Scan(); line++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+
+		entry := &CassetteEntry{}
+
+		err = json.Unmarshal(scanner.Bytes(), entry)
+
+		if err != nil {
+			return &ReplayTransport{}, fmt.Errorf("could not read cassette line %d: %w", line, err)
+		}
+
+		if entry.Method == "" {
+			return &ReplayTransport{}, fmt.Errorf("cassette line %d has no method", line)
+		}
+
+		entries = append(entries, entry)
+	}
+
+	err = scanner.Err()
+
+	if err != nil {
+		return &ReplayTransport{}, err
+	}
+
+	return &ReplayTransport{entries: entries, played: make([]bool, len(entries))}, nil
+
+}
+
+// Method to answer a JSON-RPC envelope from the cassette
+// the response id is set to the request id
+//   :values ctx: A context.Context to cancel the request
+//   :values request: A *TransportRequest
+func (t *ReplayTransport) Send(ctx context.Context, request *TransportRequest) (*TransportResponse, error) {
+	ids, err := requestIDs(request.Body)
+
+	if err != nil {
+		return nil, err
+	}
+
+	method, params, _, err := cassetteRequest(request.Body)
+
+	if err != nil {
+		return nil, err
+	}
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	for i, entry := range t.entries {
+		if t.played[i] || entry.Method != method || !bytes.Equal(entry.Params, params) {
+			continue
+		}
+
+		t.played[i] = true
+
+		body, err := replayBody(entry.Response, ids)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return &TransportResponse{StatusCode: entry.StatusCode, Header: make(http.Header), Body: body}, nil
+	}
+
+	return nil, fmt.Errorf("%w: %s with params %s", ErrCassetteMismatch, method, params)
+
+}
+
+// requestIDs gets the raw ids of a request, a batch has one for each call
+//   :values body: The JSON-RPC request body
+func requestIDs(body []byte) ([]json.RawMessage, error) {
+	type envelope struct {
+		ID json.RawMessage `json:"id"`
+	}
+
+	if trimmed := bytes.TrimSpace(body); len(trimmed) > 0 && trimmed[0] == '[' {
+		var calls []envelope
+
+		err := json.Unmarshal(trimmed, &calls)
+
+		if err != nil {
+			return nil, err
+		}
+
+		ids := make([]json.RawMessage, 0, len(calls))
+		for _, call := range calls {
+			ids = append(ids, call.ID)
+		}
+
+		return ids, nil
+	}
+
+	var call envelope
+
+	err := json.Unmarshal(body, &call)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return []json.RawMessage{call.ID}, nil
+
+}
+
+// replayBody gets the response body of a CassetteEntry with the ids of the request
+// the responses of a batch get the ids in the order of the calls
+//   :values response: The recorded response
+//   :values ids: The raw ids of the request
+func replayBody(response json.RawMessage, ids []json.RawMessage) ([]byte, error) {
+	var text string
+
+	// A body that was not JSON was recorded as a JSON string
+	if json.Unmarshal(response, &text) == nil {
+		return []byte(text), nil
+	}
+
+	var members []map[string]json.RawMessage
+
+	batch := bytes.HasPrefix(bytes.TrimSpace(response), []byte("["))
+
+	if batch {
+		err := json.Unmarshal(response, &members)
+
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		members = append(members, nil)
+
+		err := json.Unmarshal(response, &members[0])
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for i, member := range members {
+		if _, ok := member["id"]; ok && i < len(ids) && len(ids[i]) > 0 {
+			member["id"] = ids[i]
+		}
+	}
+
+	if batch {
+		return json.Marshal(members)
+	}
+
+	return json.Marshal(members[0])
+
+}
+
+// Method to get the recorded entries that have not been played
+func (t *ReplayTransport) Unplayed() []CassetteEntry {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	var unplayed []CassetteEntry
+
+	for i, entry := range t.entries {
+		if !t.played[i] {
+			unplayed = append(unplayed, *entry)
+		}
+	}
+
+	return unplayed
+
+}
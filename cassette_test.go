@@ -0,0 +1,186 @@
+package nsojsonrpcrequestergo
+
+import (
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/btr1975/nsojsonrpcrequestergo/nsomock"
+)
+
+// cassetteCalls runs the same calls against a config for recording and replaying
+func cassetteCalls(config *NsoJsonRpcConfig) (string, error) {
+	err := config.NsoLogin()
+
+	if err != nil {
+		return "", err
+	}
+
+	trans, err := config.NewTransaction("read_write", "private", "", "reuse")
+
+	if err != nil {
+		return "", err
+	}
+
+	_, err = trans.SetValue("/ncs:devices/device{r1}/address", "10.0.0.10", false)
+
+	if err != nil {
+		return "", err
+	}
+
+	_, err = trans.SetValue("/ncs:devices/authgroups/group{default}/default-map/remote-password", "DevicePass", false)
+
+	if err != nil {
+		return "", err
+	}
+
+	_, err = trans.Commit(false, "", false)
+
+	if err != nil {
+		return "", err
+	}
+
+	response, err := trans.GetValue("/ncs:devices/device{r1}/address", false)
+
+	if err != nil {
+		return "", err
+	}
+
+	return response.Result["value"].(string), config.NsoLogout()
+
+}
+
+func Test_RecordingTransport_ReplayTransport(t *testing.T) {
+	server := nsomock.NewServer(nsomock.WithUser("admin", "Sup3rS3cret"))
+	defer server.Close()
+
+	cassette := filepath.Join(t.TempDir(), "cassette.jsonl")
+
+	recorder, err := NewRecordingTransport(NewHTTPTransport(false), cassette)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	config, _ := NewNsoJsonRpcConfigWithTransport("http", server.Host(), server.Port(), "admin", "Sup3rS3cret", recorder)
+
+	recorded, err := cassetteCalls(config)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	_ = recorder.Close()
+
+	data, _ := ioutil.ReadFile(cassette)
+	for _, secret := range []string{"Sup3rS3cret", "DevicePass"} {
+		if strings.Contains(string(data), secret) {
+			t.Errorf("expected %v to be redacted in %v", secret, string(data))
+		}
+	}
+
+	replayer, err := NewReplayTransport(cassette)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	// The password is redacted so any password matches
+	config, _ = NewNsoJsonRpcConfigWithTransport("http", "192.168.1.1", 8080, "admin", "other", replayer)
+
+	replayed, err := cassetteCalls(config)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	if replayed != recorded {
+		t.Errorf("expected %v got %v", recorded, replayed)
+	}
+
+	if len(replayer.Unplayed()) != 0 {
+		t.Errorf("expected %v got %v", 0, replayer.Unplayed())
+	}
+
+	_, err = config.GetValue("/ncs:devices/device{r2}/address", false)
+	if !errors.Is(err, ErrCassetteMismatch) {
+		t.Errorf("expected error %v got %v", ErrCassetteMismatch, err)
+	}
+
+}
+
+func Test_RecordingTransport_ReplayTransport_batch(t *testing.T) {
+	server := nsomock.NewServer()
+	defer server.Close()
+
+	server.SetValue("/ncs:devices/device{r1}/address", "10.0.0.1")
+
+	cassette := filepath.Join(t.TempDir(), "cassette.jsonl")
+
+	batchCalls := func(config *NsoJsonRpcConfig) ([]BatchCallResult, error) {
+		err := config.NsoLogin()
+
+		if err != nil {
+			return nil, err
+		}
+
+		trans, err := config.NewTransaction("read", "private", "", "reuse")
+
+		if err != nil {
+			return nil, err
+		}
+
+		batch := trans.NewBatch()
+		batch.Exists("/ncs:devices/device{r1}")
+		batch.GetValue("/ncs:devices/device{r1}/address", false)
+
+		return batch.Send()
+	}
+
+	recorder, _ := NewRecordingTransport(NewHTTPTransport(false), cassette)
+	config, _ := NewNsoJsonRpcConfigWithTransport("http", server.Host(), server.Port(), "admin", "admin", recorder)
+
+	recorded, err := batchCalls(config)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	_ = recorder.Close()
+
+	replayer, _ := NewReplayTransport(cassette)
+	config, _ = NewNsoJsonRpcConfigWithTransport("http", "192.168.1.1", 8080, "admin", "admin", replayer)
+
+	replayed, err := batchCalls(config)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	if len(replayed) != 2 || replayed[1].Response.Result["value"] != "10.0.0.1" {
+		t.Errorf("expected %v got %v", recorded, replayed)
+	}
+
+	if len(replayer.Unplayed()) != 0 {
+		t.Errorf("expected %v got %v", 0, replayer.Unplayed())
+	}
+
+}
+
+func Test_NewReplayTransport(t *testing.T) {
+	scenarios := []struct {
+		cassette string
+		rcvError error
+	}{
+		{cassette: `{"method": "login", "params": {"user": "admin"}, "status_code": 200, "response": {"jsonrpc": "2.0", "id": 1, "result": {}}}` + "\n\n", rcvError: nil},
+		{cassette: `{"request_id": "1", "title": "not a cassette"}`, rcvError: errors.New("cassette line 1 has no method")},
+		{cassette: `{"method": "login"}` + "\nnot json", rcvError: errors.New("could not read cassette line 2: invalid character 'o' in literal null (expecting 'u')")},
+	}
+
+	for _, scenario := range scenarios {
+		cassette := filepath.Join(t.TempDir(), "cassette.jsonl")
+		_ = ioutil.WriteFile(cassette, []byte(scenario.cassette), 0600)
+
+		_, err := NewReplayTransport(cassette)
+		if err != scenario.rcvError {
+			if err == nil || scenario.rcvError == nil || err.Error() != scenario.rcvError.Error() {
+				t.Errorf("expected error %v got %v", scenario.rcvError, err)
+			}
+		}
+	}
+
+}
@@ -0,0 +1,469 @@
+package nsojsonrpcrequestergo
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// Client holds a single NSO session shared by its Config and Comet views
+type Client struct {
+	nsocon *nsoJsonConnection
+	config *NsoJsonRpcConfig
+	comet  *NsoJsonRpcComet
+}
+
+// ClientOption sets an option on a Client
+type ClientOption func(*clientOptions) error
+
+// clientOptions holds the options given to NewClient
+type clientOptions struct {
+	username, password string
+	sslVerify          bool
+	tlsConfig          *tls.Config
+	tlsOptions         TLSOptions
+	timeout            time.Duration
+	headers            http.Header
+	autoRelogin        bool
+	retryPolicy        *RetryPolicy
+	logger             Logger
+	wireDump           bool
+	transport          Transport
+	httpOptionSet      bool
+}
+
+// Option to set the username and password to login with
+//   :values username: A username
+//   :values password: A password
+func WithCredentials(username string, password string) ClientOption {
+	return func(o *clientOptions) error {
+		o.username = username
+		o.password = password
+
+		return nil
+	}
+
+}
+
+// Option to set if the server certificate is verified, the default is true
+//   :values sslVerify: true to verify SSL, false not to
+func WithSSLVerify(sslVerify bool) ClientOption {
+	return func(o *clientOptions) error {
+		o.sslVerify = sslVerify
+		o.httpOptionSet = true
+
+		return nil
+	}
+
+}
+
+// Option to set the TLS configuration of the default transport
+//   :values tlsConfig: A *tls.Config it is cloned
+func WithTLSConfig(tlsConfig *tls.Config) ClientOption {
+	return func(o *clientOptions) error {
+		if tlsConfig == nil {
+			return errors.New("tls config can not be nil")
+		}
+
+		o.tlsConfig = tlsConfig.Clone()
+		o.httpOptionSet = true
+
+		return nil
+	}
+
+}
+
+// Option to trust the CAs in PEM files instead of the system CAs
+//   :values caFiles: One or more PEM files
+func WithCAFiles(caFiles ...string) ClientOption {
+	return func(o *clientOptions) error {
+		if len(caFiles) == 0 {
+			return errors.New("at least one CA file is required")
+		}
+
+		o.tlsOptions.CAFiles = append(o.tlsOptions.CAFiles, caFiles...)
+		o.httpOptionSet = true
+
+		return nil
+	}
+
+}
+
+// Option to set a client certificate for mTLS
+//   :values certFile: A PEM certificate file
+//   :values keyFile: A PEM key file
+func WithClientCertificate(certFile string, keyFile string) ClientOption {
+	return func(o *clientOptions) error {
+		o.tlsOptions.CertFile = certFile
+		o.tlsOptions.KeyFile = keyFile
+		o.httpOptionSet = true
+
+		return nil
+	}
+
+}
+
+// Option to set the minimum TLS version
+//   :values version: A TLS version like tls.VersionTLS12
+func WithMinTLSVersion(version uint16) ClientOption {
+	return func(o *clientOptions) error {
+		o.tlsOptions.MinVersion = version
+		o.httpOptionSet = true
+
+		return nil
+	}
+
+}
+
+// Option to override the name the server certificate is verified against
+//   :values serverName: The name in the server certificate
+func WithServerName(serverName string) ClientOption {
+	return func(o *clientOptions) error {
+		o.tlsOptions.ServerName = serverName
+		o.httpOptionSet = true
+
+		return nil
+	}
+
+}
+
+// Option to pin the server certificate by SHA-256 fingerprint
+// one certificate of the server chain has to match a fingerprint
+//   :values fingerprints: SHA-256 fingerprints in hex, colons are allowed
+func WithPinnedCertificates(fingerprints ...string) ClientOption {
+	return func(o *clientOptions) error {
+		if len(fingerprints) == 0 {
+			return errors.New("at least one fingerprint is required")
+		}
+
+		o.tlsOptions.PinnedSHA256 = append(o.tlsOptions.PinnedSHA256, fingerprints...)
+		o.httpOptionSet = true
+
+		return nil
+	}
+
+}
+
+// Option to set the timeout of each HTTP request of the default transport
+// a comet poll waits on the server so keep the timeout above the poll time
+//   :values timeout: A time.Duration, 0 is no timeout
+func WithTimeout(timeout time.Duration) ClientOption {
+	return func(o *clientOptions) error {
+		if timeout < 0 {
+			return errors.New("timeout can not be negative")
+		}
+
+		o.timeout = timeout
+		o.httpOptionSet = true
+
+		return nil
+	}
+
+}
+
+// Option to add a header sent with every request
+//   :values key: The header name
+//   :values value: The header value
+func WithHeader(key string, value string) ClientOption {
+	return func(o *clientOptions) error {
+		o.headers.Add(key, value)
+
+		return nil
+	}
+
+}
+
+// Option to login again when NSO says the session is invalid, see SetAutoRelogin
+func WithAutoRelogin() ClientOption {
+	return func(o *clientOptions) error {
+		o.autoRelogin = true
+
+		return nil
+	}
+
+}
+
+// Option to set the RetryPolicy, see SetRetryPolicy
+//   :values policy: A *RetryPolicy
+func WithRetryPolicy(policy *RetryPolicy) ClientOption {
+	return func(o *clientOptions) error {
+		if policy == nil {
+			return errors.New("retry policy can not be nil")
+		}
+
+		err := policy.Validate()
+
+		if err != nil {
+			return err
+		}
+
+		o.retryPolicy = policy
+
+		return nil
+	}
+
+}
+
+// Option to set the Logger
+//   :values logger: A Logger, a *slog.Logger works
+func WithLogger(logger Logger) ClientOption {
+	return func(o *clientOptions) error {
+		if logger == nil {
+			return errors.New("logger can not be nil")
+		}
+
+		o.logger = logger
+
+		return nil
+	}
+
+}
+
+// Option to log the full request and response bodies at debug level, see SetWireDump
+func WithWireDump() ClientOption {
+	return func(o *clientOptions) error {
+		o.wireDump = true
+
+		return nil
+	}
+
+}
+
+// Option to set the Transport, it can not be used with the TLS or timeout options
+//   :values transport: The Transport used to reach the NSO Server
+func WithTransport(transport Transport) ClientOption {
+	return func(o *clientOptions) error {
+		if transport == nil {
+			return errors.New("a transport is required")
+		}
+
+		o.transport = transport
+
+		return nil
+	}
+
+}
+
+// Constructor for a Client
+//   :values baseURL: The NSO Server URL like https://nso.example.com:8888
+//   :values opts: ClientOption values like WithCredentials
+func NewClient(baseURL string, opts ...ClientOption) (*Client, error) {
+	options := &clientOptions{sslVerify: true, headers: make(http.Header), logger: noopLogger{}}
+
+	for _, opt := range opts {
+		err := opt(options)
+
+		if err != nil {
+			return &Client{}, err
+		}
+	}
+
+	protocol, host, port, err := parseBaseURL(baseURL)
+
+	if err != nil {
+		return &Client{}, err
+	}
+
+	transport := options.transport
+
+	if transport != nil && options.httpOptionSet {
+		return &Client{}, errors.New("tls and timeout options can not be used with WithTransport")
+	}
+
+	if transport == nil {
+		transport, err = newClientHTTPTransport(options)
+
+		if err != nil {
+			return &Client{}, err
+		}
+	}
+
+	nsoJson, err := newNsoJsonConnectionWithTransport(protocol, host, port, options.username, options.password, options.sslVerify, transport)
+
+	if err != nil {
+		return &Client{}, err
+	}
+
+	nsoJson.headers = options.headers
+	nsoJson.logger = options.logger
+	nsoJson.wireDump = options.wireDump
+	nsoJson.autoRelogin = options.autoRelogin
+	nsoJson.retryPolicy = options.retryPolicy
+
+	client := &Client{nsocon: nsoJson}
+	client.config = &NsoJsonRpcConfig{nsocon: nsoJson}
+	client.comet = newNsoJsonRpcComet(nsoJson, true)
+
+	return client, nil
+
+}
+
+// parseBaseURL splits a base URL into a protocol, host, and port
+// the port defaults to 80 for http and 443 for https
+//   :values baseURL: The NSO Server URL
+func parseBaseURL(baseURL string) (string, string, int, error) {
+	u, err := url.Parse(baseURL)
+
+	if err != nil {
+		return "", "", 0, err
+	}
+
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return "", "", 0, errors.New("only http, and https is supported")
+	}
+
+	if u.Path != "" && u.Path != "/" && u.Path != "/jsonrpc" {
+		return "", "", 0, fmt.Errorf("base url path %s is not supported", u.Path)
+	}
+
+	port := 80
+	if u.Scheme == "https" {
+		port = 443
+	}
+
+	if u.Port() != "" {
+		port, err = strconv.Atoi(u.Port())
+
+		if err != nil {
+			return "", "", 0, errors.New("valid port range between 1 and 65535")
+		}
+	}
+
+	return u.Scheme, u.Hostname(), port, nil
+
+}
+
+// newClientHTTPTransport creates the default HTTPTransport from the client options
+//   :values options: The *clientOptions
+func newClientHTTPTransport(options *clientOptions) (*HTTPTransport, error) {
+	tlsConfig := options.tlsConfig
+
+	if tlsConfig == nil {
+		tlsConfig = &tls.Config{}
+	}
+
+	err := options.tlsOptions.apply(tlsConfig)
+
+	if err != nil {
+		return &HTTPTransport{}, err
+	}
+
+	if !options.sslVerify {
+		tlsConfig.InsecureSkipVerify = true
+	}
+
+	roundTripper := http.DefaultTransport.(*http.Transport).Clone()
+	roundTripper.TLSClientConfig = tlsConfig
+
+	return NewHTTPTransportFromClient(&http.Client{Transport: roundTripper, Timeout: options.timeout}), nil
+
+}
+
+// Method to get the Config view of the client
+func (client *Client) Config() *NsoJsonRpcConfig {
+	return client.config
+
+}
+
+// Method to get the Comet view of the client
+// the comet logs in only if the session is not logged in and does not logout when stopped
+func (client *Client) Comet() *NsoJsonRpcComet {
+	return client.comet
+
+}
+
+// Method to set if an abort is sent to NSO when a request context is cancelled
+//   :values enabled: true to send an abort false to not
+func (client *Client) SetAbortOnCancel(enabled bool) {
+	client.nsocon.SetAbortOnCancel(enabled)
+
+}
+
+// Method to set if the session logs in again when NSO says it is invalid
+// read transactions are started again and idempotent requests are sent once more
+// a request on a lost read_write transaction returns ErrTransactionLost
+//   :values enabled: true to login again false to not
+func (client *Client) SetAutoRelogin(enabled bool) {
+	client.nsocon.SetAutoRelogin(enabled)
+
+}
+
+// Method to set the Logger, nil turns logging off
+//   :values logger: A Logger, a *slog.Logger works
+func (client *Client) SetLogger(logger Logger) {
+	client.nsocon.SetLogger(logger)
+
+}
+
+// Method to set if the full request and response bodies are logged at debug level
+// passwords and other secrets are redacted
+//   :values enabled: true to log the bodies false to not
+func (client *Client) SetWireDump(enabled bool) {
+	client.nsocon.SetWireDump(enabled)
+
+}
+
+// Method to set the RetryPolicy, nil turns retries off
+// non-idempotent methods like commit are only retried when they are in the policy Methods
+//   :values policy: A *RetryPolicy
+func (client *Client) SetRetryPolicy(policy *RetryPolicy) error {
+	err := client.nsocon.SetRetryPolicy(policy)
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+
+}
+
+// Method to login to the NSO Server
+func (client *Client) NsoLogin() error {
+	err := client.NsoLoginContext(context.Background())
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Method to login to the NSO Server using a context
+//   :values ctx: A context.Context to cancel the request
+func (client *Client) NsoLoginContext(ctx context.Context) error {
+	err := client.nsocon.NsoLoginContext(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Method to logout to the NSO Server
+func (client *Client) NsoLogout() error {
+	err := client.NsoLogoutContext(context.Background())
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Method to logout to the NSO Server using a context
+//   :values ctx: A context.Context to cancel the request
+func (client *Client) NsoLogoutContext(ctx context.Context) error {
+	err := client.nsocon.NsoLogoutContext(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
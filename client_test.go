@@ -0,0 +1,156 @@
+package nsojsonrpcrequestergo
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// testLogger records the messages it is given
+type testLogger struct {
+	lock     sync.Mutex
+	messages []string
+}
+
+func (l *testLogger) record(msg string) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.messages = append(l.messages, msg)
+}
+
+func (l *testLogger) Debug(msg string, args ...interface{}) { l.record(msg) }
+
+func (l *testLogger) Info(msg string, args ...interface{}) { l.record(msg) }
+
+func (l *testLogger) Warn(msg string, args ...interface{}) { l.record(msg) }
+
+func (l *testLogger) Error(msg string, args ...interface{}) { l.record(msg) }
+
+func Test_NewClient_sharedSession(t *testing.T) {
+	var called []string
+	var lock sync.Mutex
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		method, _ := body["method"].(string)
+
+		lock.Lock()
+		called = append(called, method)
+		lock.Unlock()
+
+		if r.Header.Get("X-Test") != "yes" {
+			t.Errorf("expected %v got %v", "yes", r.Header.Get("X-Test"))
+		}
+
+		result := "{}"
+		switch method {
+		case "login":
+			http.SetCookie(w, &http.Cookie{Name: "sessionid", Value: "abc"})
+		case "new_trans":
+			result = `{"th": 1}`
+		}
+
+		if cookie, err := r.Cookie("sessionid"); method != "login" && (err != nil || cookie.Value != "abc") {
+			t.Errorf("expected the session cookie for %v", method)
+		}
+
+		_, _ = w.Write([]byte(fmt.Sprintf(`{"jsonrpc": "2.0", "id": %v, "result": %s}`, body["id"], result)))
+	}))
+	defer server.Close()
+
+	logger := &testLogger{}
+
+	client, err := NewClient(server.URL, WithCredentials("admin", "admin"), WithHeader("X-Test", "yes"), WithTimeout(time.Minute), WithLogger(logger))
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	err = client.NsoLogin()
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	_, err = client.Config().NewTransaction("read", "private", "", "reuse")
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	err = client.Comet().StartComet()
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	err = client.Comet().StopComet()
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	expect := []string{"login", "new_trans", "new_trans", "comet", "comet", "delete_trans"}
+	if !reflect.DeepEqual(called, expect) {
+		t.Errorf("expected %v got %v", expect, called)
+	}
+
+	if client.Config().LastRequestID() != client.Comet().LastRequestID() {
+		t.Errorf("expected %v got %v", client.Config().LastRequestID(), client.Comet().LastRequestID())
+	}
+
+	if len(logger.messages) != len(expect) {
+		t.Errorf("expected %v got %v", len(expect), len(logger.messages))
+	}
+
+}
+
+func Test_NewClient_badOptions(t *testing.T) {
+	scenarios := []struct {
+		baseURL  string
+		opts     []ClientOption
+		rcvError error
+	}{
+		{baseURL: "ftp://127.0.0.1", rcvError: errors.New("only http, and https is supported")},
+		{baseURL: "http://127.0.0.1:8080/other", rcvError: errors.New("base url path /other is not supported")},
+		{baseURL: "http://127.0.0.1:70000", rcvError: errors.New("valid port range between 1 and 65535")},
+		{baseURL: "http://127.0.0.1", opts: []ClientOption{WithTimeout(-1)}, rcvError: errors.New("timeout can not be negative")},
+		{baseURL: "http://127.0.0.1", opts: []ClientOption{WithLogger(nil)}, rcvError: errors.New("logger can not be nil")},
+		{baseURL: "http://127.0.0.1", opts: []ClientOption{WithTransport(nil)}, rcvError: errors.New("a transport is required")},
+		{baseURL: "http://127.0.0.1", opts: []ClientOption{WithTransport(&fakeTransport{}), WithSSLVerify(false)}, rcvError: errors.New("tls and timeout options can not be used with WithTransport")},
+	}
+
+	for _, scenario := range scenarios {
+		_, err := NewClient(scenario.baseURL, scenario.opts...)
+		if err == nil || err.Error() != scenario.rcvError.Error() {
+			t.Errorf("expected error %v got %v", scenario.rcvError, err)
+		}
+	}
+
+}
+
+func Test_parseBaseURL(t *testing.T) {
+	scenarios := []struct {
+		baseURL, protocol, host string
+		port                    int
+	}{
+		{baseURL: "http://127.0.0.1", protocol: "http", host: "127.0.0.1", port: 80},
+		{baseURL: "https://127.0.0.1", protocol: "https", host: "127.0.0.1", port: 443},
+		{baseURL: "https://127.0.0.1:8888/jsonrpc", protocol: "https", host: "127.0.0.1", port: 8888},
+		{baseURL: "https://[2001:db8::1]:8888", protocol: "https", host: "2001:db8::1", port: 8888},
+		{baseURL: "https://nso.example.com", protocol: "https", host: "nso.example.com", port: 443},
+	}
+
+	for _, scenario := range scenarios {
+		protocol, host, port, err := parseBaseURL(scenario.baseURL)
+		if err != nil {
+			t.Errorf("unexpected error %v", err)
+		}
+		if protocol != scenario.protocol || host != scenario.host || port != scenario.port {
+			t.Errorf("expected %v %v %v got %v %v %v", scenario.protocol, scenario.host, scenario.port, protocol, host, port)
+		}
+	}
+
+}
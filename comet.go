@@ -1,61 +1,229 @@
 package nsojsonrpcrequestergo
 
 import (
+	"context"
 	"errors"
 	"fmt"
-	"github.com/imroc/req"
 	"math/rand"
+	"sync"
+	"time"
 )
 
+// The kinds of comet subscriptions, a CometEvent has the kind of the subscription it came from
+const (
+	CometKindChanges      = "changes"
+	CometKindPollLeaf     = "poll_leaf"
+	CometKindCDBOper      = "cdboper"
+	CometKindUpgrade      = "upgrade"
+	CometKindJSONRpcBatch = "jsonrpc_batch"
+	CometKindMessages     = "messages"
+)
+
+// SubscribeOptions holds the options every subscribe method has
+type SubscribeOptions struct {
+	// Handle is the handle to use instead of one NSO picks, empty for NSO to pick
+	Handle string
+}
+
+// Method to add the options to the params of a subscribe request
+//   :values params: The params of the request
+func (options SubscribeOptions) params(params map[string]interface{}) map[string]interface{} {
+	if options.Handle != "" {
+		params["handle"] = options.Handle
+	}
+
+	return params
+
+}
+
+// SubscribeChangesOptions holds the options of a changes subscription
+type SubscribeChangesOptions struct {
+	SubscribeOptions
+	// SkipLocalChanges leaves out the changes made by this session
+	SkipLocalChanges bool
+	// HideChanges sends a notification without the changes, only that something changed
+	HideChanges bool
+	// HideValues sends the changes without their values
+	HideValues bool
+}
+
+// Method to add the options to the params of a subscribe_changes request
+//   :values params: The params of the request
+func (options SubscribeChangesOptions) params(params map[string]interface{}) map[string]interface{} {
+	params = options.SubscribeOptions.params(params)
+
+	if options.SkipLocalChanges {
+		params["skip_local_changes"] = true
+	}
+
+	if options.HideChanges {
+		params["hide_changes"] = true
+	}
+
+	if options.HideValues {
+		params["hide_values"] = true
+	}
+
+	return params
+
+}
+
 // NsoJsonRpcComet holds a NSO JSON RPC comet needs
 type NsoJsonRpcComet struct {
-	nsocon       nsoJsonConnection
+	nsocon       *nsoJsonConnection
 	cometStarted bool
 	cometID      string
+	lock         sync.Mutex
 	handles      []string
+	subs         map[string]*Subscription
+	trans        *Transaction
+	shared       bool
+	events       *cometEventLoop
+	reconnect    *RetryPolicy
+	sinks        []*sinkRunner
 }
 
 // Constructor for a NsoJsonRpcComet
 //   :values protocol: http, https
-//   :values ip: a IPv4 address, or a CNAME
+//   :values ip: a IPv4 address, a IPv6 address, or a hostname
 //   :values port: 1 to 65535
 //   :values username: A username
 //   :values password: A password
 //   :values sslVerify: true to verify SSL, false not to
 func NewNsoJsonRpcComet(protocol string, ip string, port int, username string, password string, sslVerify bool) (*NsoJsonRpcComet, error) {
-	cometID := fmt.Sprintf("remote-comet-%d", rand.Intn(65000-1+1)+1)
-
 	nsoJson, err := newNsoJsonConnection(protocol, ip, port, username, password, sslVerify)
 
 	if err != nil {
 		return &NsoJsonRpcComet{}, err
 	}
 
-	return &NsoJsonRpcComet{nsocon: *nsoJson, cometStarted: false, cometID: cometID}, nil
+	return newNsoJsonRpcComet(nsoJson, false), nil
+
+}
+
+// Constructor for a NsoJsonRpcComet using a Transport
+// TLS settings are up to the Transport
+//   :values protocol: http, https
+//   :values ip: a IPv4 address, a IPv6 address, or a hostname
+//   :values port: 1 to 65535
+//   :values username: A username
+//   :values password: A password
+//   :values transport: The Transport used to reach the NSO Server
+func NewNsoJsonRpcCometWithTransport(protocol string, ip string, port int, username string, password string, transport Transport) (*NsoJsonRpcComet, error) {
+	nsoJson, err := newNsoJsonConnectionWithTransport(protocol, ip, port, username, password, true, transport)
+
+	if err != nil {
+		return &NsoJsonRpcComet{}, err
+	}
+
+	return newNsoJsonRpcComet(nsoJson, false), nil
+
+}
+
+// Constructor for a NsoJsonRpcComet on an existing connection
+// a shared comet reuses the session login and does not logout when stopped
+//   :values nsoJson: The *nsoJsonConnection to use
+//   :values shared: true if the session is shared with other clients
+func newNsoJsonRpcComet(nsoJson *nsoJsonConnection, shared bool) *NsoJsonRpcComet {
+	random := rand.New(rand.NewSource(time.Now().UnixNano()))
+	cometID := fmt.Sprintf("remote-comet-%d", random.Intn(65000-1+1)+1)
+
+	return &NsoJsonRpcComet{nsocon: nsoJson, cometStarted: false, cometID: cometID, subs: make(map[string]*Subscription), shared: shared}
+
+}
+
+// Method to set if an abort is sent to NSO when a request context is cancelled
+//   :values enabled: true to send an abort false to not
+func (com *NsoJsonRpcComet) SetAbortOnCancel(enabled bool) {
+	com.nsocon.SetAbortOnCancel(enabled)
+
+}
+
+// Method to set if the session logs in again when NSO says it is invalid
+// read transactions are started again and idempotent requests are sent once more
+// a request on a lost read_write transaction returns ErrTransactionLost
+//   :values enabled: true to login again false to not
+func (com *NsoJsonRpcComet) SetAutoRelogin(enabled bool) {
+	com.nsocon.SetAutoRelogin(enabled)
+
+}
+
+// Method to set the Logger, nil turns logging off
+//   :values logger: A Logger, a *slog.Logger works
+func (com *NsoJsonRpcComet) SetLogger(logger Logger) {
+	com.nsocon.SetLogger(logger)
+
+}
+
+// Method to set if the full request and response bodies are logged at debug level
+// passwords and other secrets are redacted
+//   :values enabled: true to log the bodies false to not
+func (com *NsoJsonRpcComet) SetWireDump(enabled bool) {
+	com.nsocon.SetWireDump(enabled)
+
+}
+
+// Method to set the RetryPolicy, nil turns retries off
+// non-idempotent methods like commit are only retried when they are in the policy Methods
+//   :values policy: A *RetryPolicy
+func (com *NsoJsonRpcComet) SetRetryPolicy(policy *RetryPolicy) error {
+	err := com.nsocon.SetRetryPolicy(policy)
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+
+}
+
+// Method to get the id of the last request sent
+func (com *NsoJsonRpcComet) LastRequestID() int {
+	return com.nsocon.LastRequestID()
+
+}
+
+// Method to get the ids of all requests waiting on a response
+func (com *NsoJsonRpcComet) InFlightRequestIDs() []int {
+	return com.nsocon.InFlightRequestIDs()
 
 }
 
 func (com *NsoJsonRpcComet) StartComet() error {
-	err := com.checkCometState(false)
+	err := com.StartCometContext(context.Background())
 
 	if err != nil {
 		return err
 	}
 
-	com.cometStarted = true
-	err = com.nsocon.NsoLogin()
+	return nil
+}
+
+func (com *NsoJsonRpcComet) StartCometContext(ctx context.Context) error {
+	err := com.checkCometState(false)
 
 	if err != nil {
 		return err
 	}
 
-	err = com.nsocon.NewTransaction("read", "private", "", "reuse")
+	com.setCometStarted(true)
+
+	// A shared session may already be logged in by another client
+	if !com.shared || !com.nsocon.isLoggedIn() {
+		err = com.nsocon.NsoLoginContext(ctx)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	com.trans, err = com.nsocon.NewTransactionContext(ctx, "read", "private", "", "reuse")
 
 	if err != nil {
 		return err
 	}
 
-	_, err = com.comet()
+	_, err = com.comet(ctx)
 
 	if err != nil {
 		return err
@@ -66,241 +234,390 @@ func (com *NsoJsonRpcComet) StartComet() error {
 }
 
 func (com *NsoJsonRpcComet) StopComet() error {
+	err := com.StopCometContext(context.Background())
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (com *NsoJsonRpcComet) StopCometContext(ctx context.Context) error {
 	err := com.checkCometState(true)
 
 	if err != nil {
 		return err
 	}
 
-	err = com.unsubscribe()
+	com.stopEvents()
+
+	err = com.unsubscribe(ctx)
 
 	if err != nil {
 		return err
 	}
 
-	_, err = com.comet()
+	_, err = com.comet(ctx)
 
 	if err != nil {
 		return err
 	}
 
-	err = com.nsocon.NsoLogout()
+	if com.shared {
+		err = com.trans.DeleteTransactionContext(ctx)
+	} else {
+		err = com.nsocon.NsoLogoutContext(ctx)
+	}
 
 	if err != nil {
 		return err
 	}
 
-	com.cometStarted = false
+	com.setCometStarted(false)
 
 	return nil
 
 }
 
-func (com *NsoJsonRpcComet) CometPoll() (*req.Resp, error) {
-	response, err := com.comet()
+func (com *NsoJsonRpcComet) CometPoll() (*NsoJsonResponse, error) {
+	response, err := com.CometPollContext(context.Background())
 
 	if err != nil {
 		return response, err
 	}
 
-	// Need to add something about returning result
+	return response, nil
+}
+
+func (com *NsoJsonRpcComet) CometPollContext(ctx context.Context) (*NsoJsonResponse, error) {
+	response, err := com.comet(ctx)
+
+	if err != nil {
+		return response, err
+	}
 
 	return response, nil
 
 }
 
-func (com *NsoJsonRpcComet) SubscribeChanges(path string) (*req.Resp, error) {
-	param := req.Param{
-		"jsonrpc": "2.0",
-		"id":      com.nsocon.id,
-		"method":  "subscribe_changes",
-		"params": map[string]interface{}{
-			"comet_id": com.cometID,
-			"path":     path,
-		},
+func (com *NsoJsonRpcComet) SubscribeChanges(path string) (*Subscription, error) {
+	subscription, err := com.SubscribeChangesContext(context.Background(), path)
+
+	if err != nil {
+		return subscription, err
 	}
 
-	response, err := com.nsocon.sendPost(param)
+	return subscription, nil
+}
+
+func (com *NsoJsonRpcComet) SubscribeChangesContext(ctx context.Context, path string) (*Subscription, error) {
+	subscription, err := com.SubscribeChangesWithOptionsContext(ctx, path, SubscribeChangesOptions{})
 
 	if err != nil {
-		return response, err
+		return subscription, err
 	}
 
-	respData := NewNsoJsonResponse()
+	return subscription, nil
 
-	newHandle, err := respData.GetCometHandle(response)
+}
+
+// Method to subscribe to changes under a path with options
+//   :values path: A key path
+//   :values options: A SubscribeChangesOptions
+func (com *NsoJsonRpcComet) SubscribeChangesWithOptions(path string, options SubscribeChangesOptions) (*Subscription, error) {
+	subscription, err := com.SubscribeChangesWithOptionsContext(context.Background(), path, options)
 
 	if err != nil {
-		return response, err
+		return subscription, err
 	}
 
-	com.handles = append(com.handles, newHandle)
+	return subscription, nil
+}
 
-	response, err = com.startSubscription(newHandle)
+// Method to subscribe to changes under a path with options using a context
+//   :values ctx: A context.Context to cancel the request
+//   :values path: A key path
+//   :values options: A SubscribeChangesOptions
+func (com *NsoJsonRpcComet) SubscribeChangesWithOptionsContext(ctx context.Context, path string, options SubscribeChangesOptions) (*Subscription, error) {
+	subscription, err := com.subscribe(ctx, CometKindChanges, options.params(map[string]interface{}{
+		"path": path,
+	}))
 
 	if err != nil {
-		return response, err
+		return subscription, err
 	}
 
-	return response, nil
+	return subscription, nil
 
 }
 
-func (com *NsoJsonRpcComet) SubscribePollLeaf(path string, interval int) (*req.Resp, error) {
-	param := req.Param{
-		"jsonrpc": "2.0",
-		"id":      com.nsocon.id,
-		"method":  "subscribe_poll_leaf",
-		"params": map[string]interface{}{
-			"comet_id": com.cometID,
-			"path":     path,
-			"interval": interval,
-		},
+func (com *NsoJsonRpcComet) SubscribePollLeaf(path string, interval int) (*Subscription, error) {
+	subscription, err := com.SubscribePollLeafContext(context.Background(), path, interval)
+
+	if err != nil {
+		return subscription, err
 	}
 
-	response, err := com.nsocon.sendPost(param)
+	return subscription, nil
+}
+
+func (com *NsoJsonRpcComet) SubscribePollLeafContext(ctx context.Context, path string, interval int) (*Subscription, error) {
+	subscription, err := com.SubscribePollLeafWithOptionsContext(ctx, path, interval, SubscribeOptions{})
 
 	if err != nil {
-		return response, err
+		return subscription, err
 	}
 
-	respData := NewNsoJsonResponse()
+	return subscription, nil
+
+}
 
-	newHandle, err := respData.GetCometHandle(response)
+// Method to subscribe to the value of a leaf with options
+//   :values path: A key path to a leaf
+//   :values interval: The poll interval in seconds
+//   :values options: A SubscribeOptions
+func (com *NsoJsonRpcComet) SubscribePollLeafWithOptions(path string, interval int, options SubscribeOptions) (*Subscription, error) {
+	subscription, err := com.SubscribePollLeafWithOptionsContext(context.Background(), path, interval, options)
 
 	if err != nil {
-		return response, err
+		return subscription, err
 	}
 
-	com.handles = append(com.handles, newHandle)
+	return subscription, nil
+}
 
-	response, err = com.startSubscription(newHandle)
+// Method to subscribe to the value of a leaf with options using a context
+//   :values ctx: A context.Context to cancel the request
+//   :values path: A key path to a leaf
+//   :values interval: The poll interval in seconds
+//   :values options: A SubscribeOptions
+func (com *NsoJsonRpcComet) SubscribePollLeafWithOptionsContext(ctx context.Context, path string, interval int, options SubscribeOptions) (*Subscription, error) {
+	subscription, err := com.subscribe(ctx, CometKindPollLeaf, options.params(map[string]interface{}{
+		"path":     path,
+		"interval": interval,
+	}))
 
 	if err != nil {
-		return response, err
+		return subscription, err
 	}
 
-	return response, nil
+	return subscription, nil
 
 }
 
-func (com *NsoJsonRpcComet) SubscribeCDBOper(path string) (*req.Resp, error) {
-	param := req.Param{
-		"jsonrpc": "2.0",
-		"id":      com.nsocon.id,
-		"method":  "subscribe_cdboper",
-		"params": map[string]interface{}{
-			"comet_id": com.cometID,
-			"path":     path,
-		},
+func (com *NsoJsonRpcComet) SubscribeCDBOper(path string) (*Subscription, error) {
+	subscription, err := com.SubscribeCDBOperContext(context.Background(), path)
+
+	if err != nil {
+		return subscription, err
 	}
 
-	response, err := com.nsocon.sendPost(param)
+	return subscription, nil
+}
+
+func (com *NsoJsonRpcComet) SubscribeCDBOperContext(ctx context.Context, path string) (*Subscription, error) {
+	subscription, err := com.SubscribeCDBOperWithOptionsContext(ctx, path, SubscribeOptions{})
 
 	if err != nil {
-		return response, err
+		return subscription, err
+	}
+
+	return subscription, nil
+
+}
+
+// Method to subscribe to changes to operational data under a path with options
+//   :values path: A key path
+//   :values options: A SubscribeOptions
+func (com *NsoJsonRpcComet) SubscribeCDBOperWithOptions(path string, options SubscribeOptions) (*Subscription, error) {
+	subscription, err := com.SubscribeCDBOperWithOptionsContext(context.Background(), path, options)
+
+	if err != nil {
+		return subscription, err
 	}
 
-	respData := NewNsoJsonResponse()
+	return subscription, nil
+}
 
-	newHandle, err := respData.GetCometHandle(response)
+// Method to subscribe to changes to operational data under a path with options using a context
+//   :values ctx: A context.Context to cancel the request
+//   :values path: A key path
+//   :values options: A SubscribeOptions
+func (com *NsoJsonRpcComet) SubscribeCDBOperWithOptionsContext(ctx context.Context, path string, options SubscribeOptions) (*Subscription, error) {
+	subscription, err := com.subscribe(ctx, CometKindCDBOper, options.params(map[string]interface{}{
+		"path": path,
+	}))
 
 	if err != nil {
-		return response, err
+		return subscription, err
 	}
 
-	com.handles = append(com.handles, newHandle)
+	return subscription, nil
+
+}
 
-	response, err = com.startSubscription(newHandle)
+func (com *NsoJsonRpcComet) SubscribeUpgrade() (*Subscription, error) {
+	subscription, err := com.SubscribeUpgradeContext(context.Background())
 
 	if err != nil {
-		return response, err
+		return subscription, err
 	}
 
-	return response, nil
+	return subscription, nil
+}
+
+func (com *NsoJsonRpcComet) SubscribeUpgradeContext(ctx context.Context) (*Subscription, error) {
+	subscription, err := com.SubscribeUpgradeWithOptionsContext(ctx, SubscribeOptions{})
+
+	if err != nil {
+		return subscription, err
+	}
+
+	return subscription, nil
 
 }
 
-func (com *NsoJsonRpcComet) SubscribeUpgrade() (*req.Resp, error) {
-	param := req.Param{
-		"jsonrpc": "2.0",
-		"id":      com.nsocon.id,
-		"method":  "subscribe_upgrade",
-		"params": map[string]interface{}{
-			"comet_id": com.cometID,
-		},
+// Method to subscribe to upgrade messages with options
+//   :values options: A SubscribeOptions
+func (com *NsoJsonRpcComet) SubscribeUpgradeWithOptions(options SubscribeOptions) (*Subscription, error) {
+	subscription, err := com.SubscribeUpgradeWithOptionsContext(context.Background(), options)
+
+	if err != nil {
+		return subscription, err
 	}
 
-	response, err := com.nsocon.sendPost(param)
+	return subscription, nil
+}
+
+// Method to subscribe to upgrade messages with options using a context
+//   :values ctx: A context.Context to cancel the request
+//   :values options: A SubscribeOptions
+func (com *NsoJsonRpcComet) SubscribeUpgradeWithOptionsContext(ctx context.Context, options SubscribeOptions) (*Subscription, error) {
+	subscription, err := com.subscribe(ctx, CometKindUpgrade, options.params(map[string]interface{}{}))
 
 	if err != nil {
-		return response, err
+		return subscription, err
 	}
 
-	respData := NewNsoJsonResponse()
+	return subscription, nil
 
-	newHandle, err := respData.GetCometHandle(response)
+}
+
+func (com *NsoJsonRpcComet) SubscribeJSONRpcBatch() (*Subscription, error) {
+	subscription, err := com.SubscribeJSONRpcBatchContext(context.Background())
 
 	if err != nil {
-		return response, err
+		return subscription, err
 	}
 
-	com.handles = append(com.handles, newHandle)
+	return subscription, nil
+}
 
-	response, err = com.startSubscription(newHandle)
+func (com *NsoJsonRpcComet) SubscribeJSONRpcBatchContext(ctx context.Context) (*Subscription, error) {
+	subscription, err := com.SubscribeJSONRpcBatchWithOptionsContext(ctx, SubscribeOptions{})
 
 	if err != nil {
-		return response, err
+		return subscription, err
 	}
 
-	return response, nil
+	return subscription, nil
 
 }
 
-func (com *NsoJsonRpcComet) SubscribeJSONRpcBatch() (*req.Resp, error) {
-	param := req.Param{
-		"jsonrpc": "2.0",
-		"id":      com.nsocon.id,
-		"method":  "subscribe_jsonrpc_batch",
-		"params": map[string]interface{}{
-			"comet_id": com.cometID,
-		},
+// Method to subscribe to the results of JSON-RPC batch requests with options
+//   :values options: A SubscribeOptions
+func (com *NsoJsonRpcComet) SubscribeJSONRpcBatchWithOptions(options SubscribeOptions) (*Subscription, error) {
+	subscription, err := com.SubscribeJSONRpcBatchWithOptionsContext(context.Background(), options)
+
+	if err != nil {
+		return subscription, err
 	}
 
-	response, err := com.nsocon.sendPost(param)
+	return subscription, nil
+}
+
+// Method to subscribe to the results of JSON-RPC batch requests with options using a context
+//   :values ctx: A context.Context to cancel the request
+//   :values options: A SubscribeOptions
+func (com *NsoJsonRpcComet) SubscribeJSONRpcBatchWithOptionsContext(ctx context.Context, options SubscribeOptions) (*Subscription, error) {
+	subscription, err := com.subscribe(ctx, CometKindJSONRpcBatch, options.params(map[string]interface{}{}))
 
 	if err != nil {
-		return response, err
+		return subscription, err
 	}
 
-	respData := NewNsoJsonResponse()
+	return subscription, nil
 
-	newHandle, err := respData.GetCometHandle(response)
+}
+
+// Method to subscribe to NSO system messages
+func (com *NsoJsonRpcComet) SubscribeMessages() (*Subscription, error) {
+	subscription, err := com.SubscribeMessagesContext(context.Background())
 
 	if err != nil {
-		return response, err
+		return subscription, err
 	}
 
-	com.handles = append(com.handles, newHandle)
+	return subscription, nil
+}
+
+// Method to subscribe to NSO system messages using a context
+//   :values ctx: A context.Context to cancel the request
+func (com *NsoJsonRpcComet) SubscribeMessagesContext(ctx context.Context) (*Subscription, error) {
+	subscription, err := com.SubscribeMessagesWithOptionsContext(ctx, SubscribeOptions{})
+
+	if err != nil {
+		return subscription, err
+	}
 
-	response, err = com.startSubscription(newHandle)
+	return subscription, nil
+
+}
+
+// Method to subscribe to NSO system messages with options
+//   :values options: A SubscribeOptions
+func (com *NsoJsonRpcComet) SubscribeMessagesWithOptions(options SubscribeOptions) (*Subscription, error) {
+	subscription, err := com.SubscribeMessagesWithOptionsContext(context.Background(), options)
+
+	if err != nil {
+		return subscription, err
+	}
+
+	return subscription, nil
+}
+
+// Method to subscribe to NSO system messages with options using a context
+//   :values ctx: A context.Context to cancel the request
+//   :values options: A SubscribeOptions
+func (com *NsoJsonRpcComet) SubscribeMessagesWithOptionsContext(ctx context.Context, options SubscribeOptions) (*Subscription, error) {
+	subscription, err := com.subscribe(ctx, CometKindMessages, options.params(map[string]interface{}{}))
+
+	if err != nil {
+		return subscription, err
+	}
+
+	return subscription, nil
+
+}
+
+func (com *NsoJsonRpcComet) GetSubscriptions() (*NsoJsonResponse, error) {
+	response, err := com.GetSubscriptionsContext(context.Background())
 
 	if err != nil {
 		return response, err
 	}
 
 	return response, nil
-
 }
 
-func (com *NsoJsonRpcComet) GetSubscriptions() (*req.Resp, error) {
-	param := req.Param{
+func (com *NsoJsonRpcComet) GetSubscriptionsContext(ctx context.Context) (*NsoJsonResponse, error) {
+	param := rpcParam{
 		"jsonrpc": "2.0",
-		"id":      com.nsocon.id,
 		"method":  "get_subscriptions",
 	}
 
-	response, err := com.nsocon.sendPost(param)
+	response, err := com.nsocon.sendPostContext(ctx, param)
 
 	if err != nil {
 		return response, err
@@ -310,17 +627,121 @@ func (com *NsoJsonRpcComet) GetSubscriptions() (*req.Resp, error) {
 
 }
 
-func (com *NsoJsonRpcComet) comet() (*req.Resp, error) {
-	param := req.Param{
+// Method to subscribe on the comet and start the subscription
+//   :values ctx: A context.Context to cancel the request
+//   :values kind: The kind of subscription, like CometKindChanges
+//   :values params: The params without the comet id
+func (com *NsoJsonRpcComet) subscribe(ctx context.Context, kind string, params map[string]interface{}) (*Subscription, error) {
+	newHandle, err := com.sendSubscribe(ctx, kind, params)
+
+	if err != nil {
+		return nil, err
+	}
+
+	path, _ := params["path"].(string)
+	subscription := &Subscription{comet: com, handle: newHandle, kind: kind, path: path, params: params}
+
+	subscription.response, err = com.startSubscription(ctx, newHandle)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// The handle is only kept once it started so StopComet and a reconnect do not renew a broken subscription
+	com.lock.Lock()
+	com.handles = append(com.handles, newHandle)
+	com.subs[newHandle] = subscription
+	com.lock.Unlock()
+
+	return subscription, nil
+
+}
+
+// Method to send a subscribe request and get the new handle
+//   :values ctx: A context.Context to cancel the request
+//   :values kind: The kind of subscription, like CometKindChanges
+//   :values params: The params without the comet id, they are not changed
+func (com *NsoJsonRpcComet) sendSubscribe(ctx context.Context, kind string, params map[string]interface{}) (string, error) {
+	sendParams := map[string]interface{}{"comet_id": com.cometID}
+	for key, value := range params {
+		sendParams[key] = value
+	}
+
+	param := rpcParam{
+		"jsonrpc": "2.0",
+		"method":  "subscribe_" + kind,
+		"params":  sendParams,
+	}
+
+	response, err := com.nsocon.sendPostContext(ctx, param)
+
+	if err != nil {
+		return "", err
+	}
+
+	newHandle, err := response.GetCometHandle()
+
+	if err != nil {
+		return "", err
+	}
+
+	return newHandle, nil
+
+}
+
+// Method to get the Subscription of a handle, nil if it is not known
+//   :values handle: A comet handle
+func (com *NsoJsonRpcComet) subscription(handle string) *Subscription {
+	com.lock.Lock()
+	defer com.lock.Unlock()
+
+	return com.subs[handle]
+
+}
+
+// Method to check if a handle is still subscribed
+//   :values handle: A comet handle
+func (com *NsoJsonRpcComet) hasHandle(handle string) bool {
+	com.lock.Lock()
+	defer com.lock.Unlock()
+
+	for _, h := range com.handles {
+		if h == handle {
+			return true
+		}
+	}
+
+	return false
+
+}
+
+// Method to forget a handle that was unsubscribed
+//   :values handle: A comet handle
+func (com *NsoJsonRpcComet) removeHandle(handle string) {
+	com.lock.Lock()
+	defer com.lock.Unlock()
+
+	for i, h := range com.handles {
+		if h == handle {
+			com.handles = append(com.handles[:i], com.handles[i+1:]...)
+			break
+		}
+	}
+
+	delete(com.subs, handle)
+
+}
+
+func (com *NsoJsonRpcComet) comet(ctx context.Context) (*NsoJsonResponse, error) {
+	param := rpcParam{
 		"jsonrpc": "2.0",
-		"id":      com.nsocon.id,
 		"method":  "comet",
 		"params": map[string]interface{}{
 			"comet_id": com.cometID,
 		},
 	}
 
-	response, err := com.nsocon.sendPost(param)
+	response, err := com.nsocon.sendPostContext(ctx, param)
 
 	if err != nil {
 		return response, err
@@ -330,17 +751,16 @@ func (com *NsoJsonRpcComet) comet() (*req.Resp, error) {
 
 }
 
-func (com *NsoJsonRpcComet) startSubscription(handle string) (*req.Resp, error) {
-	param := req.Param{
+func (com *NsoJsonRpcComet) startSubscription(ctx context.Context, handle string) (*NsoJsonResponse, error) {
+	param := rpcParam{
 		"jsonrpc": "2.0",
-		"id":      com.nsocon.id,
 		"method":  "start_subscription",
 		"params": map[string]interface{}{
 			"handle": handle,
 		},
 	}
 
-	response, err := com.nsocon.sendPost(param)
+	response, err := com.nsocon.sendPostContext(ctx, param)
 
 	if err != nil {
 		return response, err
@@ -350,23 +770,41 @@ func (com *NsoJsonRpcComet) startSubscription(handle string) (*req.Resp, error)
 
 }
 
-func (com *NsoJsonRpcComet) unsubscribe() error {
-	for _, handle := range com.handles {
-		param := req.Param{
-			"jsonrpc": "2.0",
-			"id":      com.nsocon.id,
-			"method":  "unsubscribe",
-			"params": map[string]interface{}{
-				"handle": handle,
-			},
-		}
+func (com *NsoJsonRpcComet) unsubscribe(ctx context.Context) error {
+	com.lock.Lock()
+	handles := append([]string(nil), com.handles...)
+	com.lock.Unlock()
 
-		_, err := com.nsocon.sendPost(param)
+	for _, handle := range handles {
+		err := com.unsubscribeHandle(ctx, handle)
 
 		if err != nil {
 			return err
 		}
 
+		com.removeHandle(handle)
+	}
+
+	return nil
+
+}
+
+// Method to unsubscribe a single handle
+//   :values ctx: A context.Context to cancel the request
+//   :values handle: A comet handle
+func (com *NsoJsonRpcComet) unsubscribeHandle(ctx context.Context, handle string) error {
+	param := rpcParam{
+		"jsonrpc": "2.0",
+		"method":  "unsubscribe",
+		"params": map[string]interface{}{
+			"handle": handle,
+		},
+	}
+
+	_, err := com.nsocon.sendPostContext(ctx, param)
+
+	if err != nil {
+		return err
 	}
 
 	return nil
@@ -374,6 +812,8 @@ func (com *NsoJsonRpcComet) unsubscribe() error {
 }
 
 func (com *NsoJsonRpcComet) checkCometState(wantedState bool) error {
+	com.lock.Lock()
+	defer com.lock.Unlock()
 
 	if com.cometStarted != wantedState {
 		if com.cometStarted == true {
@@ -388,3 +828,13 @@ func (com *NsoJsonRpcComet) checkCometState(wantedState bool) error {
 	return nil
 
 }
+
+// Method to set if the comet is running
+//   :values started: true when the comet is running false when it is not
+func (com *NsoJsonRpcComet) setCometStarted(started bool) {
+	com.lock.Lock()
+	defer com.lock.Unlock()
+
+	com.cometStarted = started
+
+}
@@ -0,0 +1,347 @@
+package nsojsonrpcrequestergo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/btr1975/nsojsonrpcrequestergo/nsomock"
+)
+
+// nextCometEvent waits on a CometEvent from the channel
+func nextCometEvent(t *testing.T, events <-chan CometEvent) (CometEvent, bool) {
+	select {
+	case event, ok := <-events:
+		return event, ok
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timed out waiting on a comet event")
+	}
+
+	return CometEvent{}, false
+}
+
+func Test_NsoJsonRpcComet_Events(t *testing.T) {
+	server := nsomock.NewServer(nsomock.WithPollTimeout(50 * time.Millisecond))
+	defer server.Close()
+
+	server.SetValue("/ncs:devices/device{r1}/address", "10.0.0.1")
+
+	comet, _ := NewNsoJsonRpcComet("http", server.Host(), server.Port(), "admin", "admin", false)
+
+	_, err := comet.Events(context.Background())
+	if err == nil || err.Error() != "comet is not running" {
+		t.Errorf("expected error %v got %v", "comet is not running", err)
+	}
+
+	_ = comet.StartComet()
+	_, _ = comet.SubscribePollLeaf("/ncs:devices/device{r1}/address", 1)
+
+	events, err := comet.Events(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	_, err = comet.Events(context.Background())
+	if err == nil || err.Error() != "comet events are already running" {
+		t.Errorf("expected error %v got %v", "comet events are already running", err)
+	}
+
+	event, _ := nextCometEvent(t, events)
+	if event.Kind != CometKindPollLeaf || event.Err != nil {
+		t.Errorf("expected %v got %v", CometKindPollLeaf, event)
+	}
+
+	_, _ = comet.SubscribeChanges("/ncs:devices")
+
+	config, _ := NewNsoJsonRpcConfig("http", server.Host(), server.Port(), "admin", "admin", false)
+	_ = config.NsoLogin()
+	trans, _ := config.NewTransaction("read_write", "private", "", "reuse")
+	_, _ = trans.SetValue("/ncs:devices/device{r1}/address", "10.0.0.10", false)
+	_, _ = trans.Commit(false, "", false)
+
+	kinds := make(map[string]json.RawMessage)
+	for len(kinds) < 2 {
+		event, _ = nextCometEvent(t, events)
+		kinds[event.Kind] = event.Message
+	}
+
+	var value struct {
+		Value string `json:"value"`
+	}
+	_ = json.Unmarshal(kinds[CometKindPollLeaf], &value)
+	if value.Value != "10.0.0.10" {
+		t.Errorf("expected %v got %v", "10.0.0.10", value.Value)
+	}
+
+	if _, ok := kinds[CometKindChanges]; !ok {
+		t.Errorf("expected %v got %v", CometKindChanges, kinds)
+	}
+
+	err = comet.StopComet()
+	if err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	_, ok := nextCometEvent(t, events)
+	if ok {
+		t.Errorf("expected %v got %v", "a closed channel", ok)
+	}
+
+}
+
+func Test_NsoJsonRpcComet_Events_stop(t *testing.T) {
+	server := nsomock.NewServer(nsomock.WithPollTimeout(50 * time.Millisecond))
+	defer server.Close()
+
+	comet, _ := NewNsoJsonRpcComet("http", server.Host(), server.Port(), "admin", "admin", false)
+	_ = comet.StartComet()
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	events, _ := comet.Events(ctx)
+	cancel()
+
+	_, ok := nextCometEvent(t, events)
+	if ok {
+		t.Errorf("expected %v got %v", "a closed channel", ok)
+	}
+
+	// A cancelled loop can be started again
+	events, err := comet.Events(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	server.ExpireSessions()
+
+	event, _ := nextCometEvent(t, events)
+	var rpcError *NsoRpcError
+	if !errors.As(event.Err, &rpcError) {
+		t.Errorf("expected %v got %v", "a *NsoRpcError", event.Err)
+	}
+
+	_, ok = nextCometEvent(t, events)
+	if ok {
+		t.Errorf("expected %v got %v", "a closed channel", ok)
+	}
+
+}
+
+func Test_NsoJsonRpcComet_SetAutoReconnect(t *testing.T) {
+	server := nsomock.NewServer(nsomock.WithPollTimeout(50 * time.Millisecond))
+	defer server.Close()
+
+	comet, _ := NewNsoJsonRpcComet("http", server.Host(), server.Port(), "admin", "admin", false)
+
+	err := comet.SetAutoReconnect(&RetryPolicy{MaxAttempts: 0})
+	if err == nil || err.Error() != "max attempts must be at least 1" {
+		t.Errorf("expected error %v got %v", "max attempts must be at least 1", err)
+	}
+
+	_ = comet.SetAutoReconnect(&RetryPolicy{MaxAttempts: 2, InitialBackoff: 10 * time.Millisecond})
+	_ = comet.StartComet()
+
+	subscription, _ := comet.SubscribeChanges("/ncs:devices")
+	oldHandle := subscription.Handle()
+
+	events, _ := comet.Events(context.Background())
+
+	server.ExpireSessions()
+
+	event, _ := nextCometEvent(t, events)
+	if event.Kind != CometKindResubscribed || event.Err != nil {
+		t.Fatalf("expected %v got %v", CometKindResubscribed, event)
+	}
+
+	if subscription.Handle() == oldHandle {
+		t.Errorf("expected a new handle got %v", subscription.Handle())
+	}
+
+	config, _ := NewNsoJsonRpcConfig("http", server.Host(), server.Port(), "admin", "admin", false)
+	_ = config.NsoLogin()
+	trans, _ := config.NewTransaction("read_write", "private", "", "reuse")
+	_, _ = trans.SetValue("/ncs:devices/device{r1}/address", "10.0.0.1", false)
+	_, _ = trans.Commit(false, "", false)
+
+	event, _ = nextCometEvent(t, events)
+	if event.Handle != subscription.Handle() || event.Kind != CometKindChanges {
+		t.Errorf("expected %v got %v", subscription.Handle(), event)
+	}
+
+	// A reconnect that keeps failing stops the loop with the error
+	server.FailNext("login", "rpc.method.failed")
+	server.FailNext("login", "rpc.method.failed")
+	server.ExpireSessions()
+
+	event, _ = nextCometEvent(t, events)
+	var rpcError *NsoRpcError
+	if !errors.As(event.Err, &rpcError) || rpcError.Method != "login" {
+		t.Errorf("expected %v got %v", "a login error", event.Err)
+	}
+
+	_, ok := nextCometEvent(t, events)
+	if ok {
+		t.Errorf("expected %v got %v", "a closed channel", ok)
+	}
+
+}
+
+func Test_NsoJsonRpcComet_SetAutoReconnect_failingSubscription(t *testing.T) {
+	server := nsomock.NewServer(nsomock.WithPollTimeout(50 * time.Millisecond))
+	defer server.Close()
+
+	comet, _ := NewNsoJsonRpcComet("http", server.Host(), server.Port(), "admin", "admin", false)
+	_ = comet.SetAutoReconnect(&RetryPolicy{MaxAttempts: 3, InitialBackoff: 10 * time.Millisecond})
+	_ = comet.StartComet()
+
+	_, _ = comet.SubscribePollLeaf("/ncs:devices/device{r1}/address", 1)
+	_, _ = comet.SubscribeChanges("/ncs:devices")
+
+	events, _ := comet.Events(context.Background())
+
+	// The poll leaf sends its value when it starts
+	_, _ = nextCometEvent(t, events)
+
+	before := len(server.Calls())
+
+	for i := 0; i < 3; i++ {
+		server.FailNext("subscribe_changes", "rpc.method.failed")
+	}
+	server.ExpireSessions()
+
+	event, _ := nextCometEvent(t, events)
+	var rpcError *NsoRpcError
+	if !errors.As(event.Err, &rpcError) || rpcError.Method != "subscribe_changes" {
+		t.Errorf("expected %v got %v", "a subscribe_changes error", event.Err)
+	}
+
+	counts := make(map[string]int)
+	for _, call := range server.Calls()[before:] {
+		counts[call]++
+	}
+
+	// Only the broken session is logged in again and the renewed poll leaf is not subscribed again
+	expect := map[string]int{"login": 1, "new_trans": 1, "subscribe_poll_leaf": 1, "start_subscription": 1, "subscribe_changes": 3}
+	for method, count := range expect {
+		if counts[method] != count {
+			t.Errorf("expected %v %v got %v", count, method, counts)
+		}
+	}
+
+}
+
+// cometBlipTransport fails the next failures comet polls like a network error and sends the rest on
+type cometBlipTransport struct {
+	Transport
+	lock     sync.Mutex
+	failures int
+}
+
+func (c *cometBlipTransport) Send(ctx context.Context, request *TransportRequest) (*TransportResponse, error) {
+	var body struct {
+		Method string `json:"method"`
+	}
+	_ = json.Unmarshal(request.Body, &body)
+
+	c.lock.Lock()
+	fail := body.Method == "comet" && c.failures > 0
+	if fail {
+		c.failures--
+	}
+	c.lock.Unlock()
+
+	if fail {
+		return nil, errors.New("connection reset by peer")
+	}
+
+	return c.Transport.Send(ctx, request)
+}
+
+func Test_NsoJsonRpcComet_SetAutoReconnect_networkError(t *testing.T) {
+	server := nsomock.NewServer(nsomock.WithPollTimeout(50 * time.Millisecond))
+	defer server.Close()
+
+	transport := &cometBlipTransport{Transport: NewHTTPTransport(false)}
+	client, _ := NewClient(server.URL(), WithCredentials("admin", "admin"), WithTransport(transport))
+
+	_ = client.NsoLogin()
+	trans, _ := client.Config().NewTransaction("read_write", "private", "", "reuse")
+
+	comet := client.Comet()
+	_ = comet.SetAutoReconnect(&RetryPolicy{MaxAttempts: 2, InitialBackoff: 10 * time.Millisecond, RetryOn: RetryOnNetworkError})
+	_ = comet.StartComet()
+
+	subscription, _ := comet.SubscribeChanges("/ncs:devices")
+	oldHandle := subscription.Handle()
+
+	before := len(server.Calls())
+
+	transport.lock.Lock()
+	transport.failures = 1
+	transport.lock.Unlock()
+
+	events, _ := comet.Events(context.Background())
+
+	event, _ := nextCometEvent(t, events)
+	if event.Kind != CometKindResubscribed || event.Err != nil {
+		t.Fatalf("expected %v got %v", CometKindResubscribed, event)
+	}
+
+	// The session still works so it is not logged in again and the old handle is stopped
+	counts := make(map[string]int)
+	for _, call := range server.Calls()[before:] {
+		counts[call]++
+	}
+
+	expect := map[string]int{"login": 0, "new_trans": 0, "unsubscribe": 1, "subscribe_changes": 1, "start_subscription": 1}
+	for method, count := range expect {
+		if counts[method] != count {
+			t.Errorf("expected %v %v got %v", count, method, counts)
+		}
+	}
+
+	// The read_write transaction of the config is not lost
+	_, _ = trans.SetValue("/ncs:devices/device{r1}/address", "10.0.0.1", false)
+	_, err := trans.Commit(false, "", false)
+	if err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	event, _ = nextCometEvent(t, events)
+	if event.Handle != subscription.Handle() || event.Handle == oldHandle || event.Kind != CometKindChanges {
+		t.Errorf("expected %v got %v", subscription.Handle(), event)
+	}
+
+	_ = comet.StopComet()
+
+}
+
+func Test_NsoJsonRpcComet_checkCometState_concurrent(t *testing.T) {
+	server := nsomock.NewServer(nsomock.WithPollTimeout(50 * time.Millisecond))
+	defer server.Close()
+
+	comet, _ := NewNsoJsonRpcComet("http", server.Host(), server.Port(), "admin", "admin", false)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			_ = comet.checkCometState(true)
+		}
+	}()
+
+	// Run with -race, the state is read above while it is written here
+	_ = comet.StartComet()
+	_ = comet.StopComet()
+
+	<-done
+
+	err := comet.checkCometState(false)
+	if err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+
+}
@@ -0,0 +1,210 @@
+package nsojsonrpcrequestergo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+)
+
+// cometEventBuffer is how many events are held on the channel before the poll loop waits on the reader
+const cometEventBuffer = 16
+
+// CometEvent holds one comet notification
+type CometEvent struct {
+	// Handle is the handle of the subscription that sent the notification
+	Handle string
+	// Kind is the kind of subscription, like CometKindChanges, empty if the handle is not known
+	Kind string
+	// Path is the path of the subscription, empty if it has none
+	Path string
+	// Message is the raw notification message
+	Message json.RawMessage
+	// Err is set on the last event when the poll loop stopped on an error
+	Err error
+}
+
+// cometEventLoop holds what is needed to stop a running poll loop
+type cometEventLoop struct {
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// Method to get the comet notifications on a channel
+// a background loop long-polls the comet until StopComet is called or ctx is cancelled
+// the channel is closed when the loop stops, if it stopped on an error the last event has Err set
+// events of a Subscription with a handler go to the handler instead of the channel
+// with SetAutoReconnect a broken session is fixed and an event of kind CometKindResubscribed is sent
+//   :values ctx: A context.Context to stop the loop
+func (com *NsoJsonRpcComet) Events(ctx context.Context) (<-chan CometEvent, error) {
+	err := com.checkCometState(true)
+
+	if err != nil {
+		return nil, err
+	}
+
+	com.lock.Lock()
+	defer com.lock.Unlock()
+
+	if com.events != nil {
+		return nil, errors.New("comet events are already running")
+	}
+
+	loopCtx, cancel := context.WithCancel(ctx)
+	loop := &cometEventLoop{cancel: cancel, done: make(chan struct{})}
+	events := make(chan CometEvent, cometEventBuffer)
+
+	com.events = loop
+
+	go com.pollEvents(loopCtx, loop, events)
+
+	return events, nil
+
+}
+
+// Method to long-poll the comet and send each notification on the events channel
+//   :values ctx: A context.Context to stop the loop
+//   :values loop: The *cometEventLoop of this loop
+//   :values events: The channel to send the events on
+func (com *NsoJsonRpcComet) pollEvents(ctx context.Context, loop *cometEventLoop, events chan<- CometEvent) {
+	defer close(loop.done)
+	defer close(events)
+	defer com.clearEvents(loop)
+
+	for {
+		generation := com.nsocon.sessionGeneration()
+
+		response, err := com.comet(ctx)
+
+		if err != nil && ctx.Err() == nil && canReconnect(com.reconnectPolicy(), err) {
+			err = com.reconnectWithPolicy(ctx, com.reconnectPolicy(), generation, err)
+
+			if err == nil {
+				select {
+				case events <- CometEvent{Kind: CometKindResubscribed}:
+				case <-ctx.Done():
+					return
+				}
+
+				continue
+			}
+		}
+
+		if err == nil {
+			var decoded []CometEvent
+
+			decoded, err = com.decodeEvents(response)
+
+			for _, event := range decoded {
+				if com.dispatch(event) {
+					continue
+				}
+
+				select {
+				case events <- event:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+
+		if ctx.Err() != nil {
+			return
+		}
+
+		if err != nil {
+			com.nsocon.logger.Warn("nso comet poll loop stopped", "comet_id", com.cometID, "error", err)
+			select {
+			case events <- CometEvent{Err: err}:
+			case <-ctx.Done():
+			}
+			return
+		}
+	}
+
+}
+
+// Method to hand an event to the handler of its Subscription
+// false is returned if the subscription has no handler
+//   :values event: The CometEvent
+func (com *NsoJsonRpcComet) dispatch(event CometEvent) bool {
+	subscription := com.subscription(event.Handle)
+
+	if subscription == nil {
+		return false
+	}
+
+	handler := subscription.getHandler()
+
+	if handler == nil {
+		return false
+	}
+
+	err := handler(event)
+
+	if err != nil {
+		com.nsocon.logger.Warn("nso comet notification could not be decoded", "handle", event.Handle, "kind", event.Kind, "error", err)
+	}
+
+	return true
+
+}
+
+// Method to decode the result of a comet poll into events
+//   :values response: The *NsoJsonResponse of a comet request
+func (com *NsoJsonRpcComet) decodeEvents(response *NsoJsonResponse) ([]CometEvent, error) {
+	var body struct {
+		Result []struct {
+			Handle  string          `json:"handle"`
+			Message json.RawMessage `json:"message"`
+		} `json:"result"`
+	}
+
+	err := response.ToJSON(&body)
+
+	if err != nil {
+		return nil, err
+	}
+
+	events := make([]CometEvent, 0, len(body.Result))
+
+	for _, result := range body.Result {
+		event := CometEvent{Handle: result.Handle, Message: result.Message}
+
+		if subscription := com.subscription(result.Handle); subscription != nil {
+			event.Kind = subscription.kind
+			event.Path = subscription.path
+		}
+
+		events = append(events, event)
+	}
+
+	return events, nil
+
+}
+
+// Method to forget a poll loop that stopped
+//   :values loop: The *cometEventLoop that stopped
+func (com *NsoJsonRpcComet) clearEvents(loop *cometEventLoop) {
+	com.lock.Lock()
+	defer com.lock.Unlock()
+
+	if com.events == loop {
+		com.events = nil
+	}
+
+}
+
+// Method to stop the poll loop if it runs and wait for it to finish
+func (com *NsoJsonRpcComet) stopEvents() {
+	com.lock.Lock()
+	loop := com.events
+	com.lock.Unlock()
+
+	if loop == nil {
+		return
+	}
+
+	loop.cancel()
+	<-loop.done
+
+}
@@ -0,0 +1,206 @@
+package nsojsonrpcrequestergo
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// CometKindResubscribed is the kind of the event sent after the comet reconnected and resubscribed
+// notifications sent while the comet was down are lost
+const CometKindResubscribed = "resubscribed"
+
+// Method to set how the Events loop reconnects when the session breaks, nil turns it off
+// on an invalid session a reconnect logs in again, starts the comet transaction again, and subscribes every Subscription again
+// on the failures in the policy RetryOn the session is kept and every Subscription is subscribed again on it
+// MaxAttempts is the most reconnects tried in a row before the loop stops with the error
+//   :values policy: A *RetryPolicy
+func (com *NsoJsonRpcComet) SetAutoReconnect(policy *RetryPolicy) error {
+	if policy != nil {
+		err := policy.Validate()
+
+		if err != nil {
+			return err
+		}
+	}
+
+	com.lock.Lock()
+	defer com.lock.Unlock()
+
+	com.reconnect = policy
+
+	return nil
+
+}
+
+// Method to get the reconnect policy, nil if the comet does not reconnect
+func (com *NsoJsonRpcComet) reconnectPolicy() *RetryPolicy {
+	com.lock.Lock()
+	defer com.lock.Unlock()
+
+	return com.reconnect
+
+}
+
+// Method to check if a failed comet poll can be fixed by a reconnect
+//   :values policy: The reconnect *RetryPolicy
+//   :values err: The error the poll failed with
+func canReconnect(policy *RetryPolicy, err error) bool {
+	if policy == nil {
+		return false
+	}
+
+	return errors.Is(err, ErrInvalidSession) || policy.retries(err)
+
+}
+
+// renewal holds the subscriptions already renewed on the current session during a reconnect
+type renewal struct {
+	fresh        bool
+	unsubscribed map[*Subscription]bool
+	subscribed   map[*Subscription]bool
+	started      map[*Subscription]bool
+}
+
+// Constructor for an empty renewal
+//   :values fresh: true when the session was just logged in and the old handles are gone
+func newRenewal(fresh bool) *renewal {
+	return &renewal{
+		fresh:        fresh,
+		unsubscribed: make(map[*Subscription]bool),
+		subscribed:   make(map[*Subscription]bool),
+		started:      make(map[*Subscription]bool),
+	}
+
+}
+
+// Method to reconnect using the policy until it works, the attempts run out, or ctx is cancelled
+// the session is only logged in again when it is invalid, as logging in again loses the read_write transactions
+// of the connection, a failed attempt retries the subscriptions not yet renewed
+//   :values ctx: A context.Context to cancel the reconnect
+//   :values policy: The reconnect *RetryPolicy
+//   :values generation: The session generation the failed poll was sent with
+//   :values pollErr: The error the poll failed with
+func (com *NsoJsonRpcComet) reconnectWithPolicy(ctx context.Context, policy *RetryPolicy, generation int, pollErr error) error {
+	err := pollErr
+	relogin := errors.Is(pollErr, ErrInvalidSession)
+	renewed := newRenewal(false)
+
+	for attempt := 1; attempt <= policy.MaxAttempts; attempt++ {
+		if attempt > 1 {
+			timer := time.NewTimer(policy.backoff(attempt - 1))
+
+			select {
+			case <-timer.C:
+			case <-ctx.Done():
+				timer.Stop()
+				return ctx.Err()
+			}
+		}
+
+		com.nsocon.logger.Info("nso comet reconnecting", "comet_id", com.cometID, "attempt", attempt, "error", err)
+
+		if relogin {
+			// The comet transaction is registered so it is started again with the login
+			err = com.nsocon.relogin(ctx, generation)
+
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+
+			if err != nil {
+				continue
+			}
+
+			// The subscriptions of the old session are gone so all of them are renewed
+			relogin = false
+			renewed = newRenewal(true)
+		}
+
+		err = com.resubscribe(ctx, renewed)
+
+		if err == nil {
+			return nil
+		}
+
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		if errors.Is(err, ErrInvalidSession) {
+			relogin = true
+			generation = com.nsocon.sessionGeneration()
+		}
+	}
+
+	return err
+
+}
+
+// Method to subscribe every Subscription again that is not renewed yet
+//   :values ctx: A context.Context to cancel the requests
+//   :values renewed: The *renewal of the current session, it is updated as subscriptions are renewed
+func (com *NsoJsonRpcComet) resubscribe(ctx context.Context, renewed *renewal) error {
+	com.lock.Lock()
+	var subscriptions []*Subscription
+	for _, handle := range com.handles {
+		subscriptions = append(subscriptions, com.subs[handle])
+	}
+	com.lock.Unlock()
+
+	for _, subscription := range subscriptions {
+		// On a session that still works the old handle is stopped before it is subscribed again
+		if !renewed.fresh && !renewed.unsubscribed[subscription] {
+			err := com.unsubscribeHandle(ctx, subscription.Handle())
+
+			if err != nil {
+				return err
+			}
+
+			renewed.unsubscribed[subscription] = true
+		}
+
+		if !renewed.subscribed[subscription] {
+			newHandle, err := com.sendSubscribe(ctx, subscription.kind, subscription.params)
+
+			if err != nil {
+				return err
+			}
+
+			com.replaceHandle(subscription, newHandle)
+			renewed.subscribed[subscription] = true
+		}
+
+		if !renewed.started[subscription] {
+			_, err := com.startSubscription(ctx, subscription.Handle())
+
+			if err != nil {
+				return err
+			}
+
+			renewed.started[subscription] = true
+		}
+	}
+
+	return nil
+
+}
+
+// Method to give a Subscription the handle it got when it was subscribed again
+//   :values subscription: The *Subscription
+//   :values newHandle: The new comet handle
+func (com *NsoJsonRpcComet) replaceHandle(subscription *Subscription, newHandle string) {
+	com.lock.Lock()
+	defer com.lock.Unlock()
+
+	for i, handle := range com.handles {
+		if handle == subscription.handle {
+			com.handles[i] = newHandle
+		}
+	}
+
+	delete(com.subs, subscription.handle)
+	com.subs[newHandle] = subscription
+	subscription.handle = newHandle
+
+}
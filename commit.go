@@ -0,0 +1,173 @@
+package nsojsonrpcrequestergo
+
+import (
+	"errors"
+	"fmt"
+)
+
+// CommitOptions holds the flags that can be given to a NSO commit
+// The zero value is a plain commit
+type CommitOptions struct {
+	// DryRun is "", cli, native, or xml
+	DryRun string
+	// DryRunReverse shows the reverse diff, only with a native DryRun
+	DryRunReverse bool
+	// Confirmed makes the commit a confirmed commit
+	Confirmed bool
+	// ConfirmTimeout is the seconds to wait for ConfirmCommit, 0 for the NSO default
+	ConfirmTimeout int
+	// Comment is shown in the NSO commit log
+	Comment string
+	// Label is shown in the NSO commit log
+	Label string
+	// NoNetworking does not send any changes to the devices
+	NoNetworking bool
+	// NoOutOfSyncCheck skips the device out of sync check
+	NoOutOfSyncCheck bool
+	// NoOverwrite checks that the device data was not changed outside NSO
+	NoOverwrite bool
+	// NoRevisionDrop does not drop changes based on the device revision
+	NoRevisionDrop bool
+	// NoDeploy does not run the service create code
+	NoDeploy bool
+	// Reconcile is "", keep-non-service-config, or discard-non-service-config
+	Reconcile string
+	// CommitQueue is "", async, sync, or bypass
+	CommitQueue string
+	// CommitQueueTimeout is the seconds to wait for a sync commit queue, 0 to wait forever
+	CommitQueueTimeout int
+	// UseLSA uses Layered Service Architecture
+	UseLSA bool
+	// NoLSA does not use Layered Service Architecture
+	NoLSA bool
+}
+
+// Method to validate the options for combinations NSO does not allow
+func (opts *CommitOptions) Validate() error {
+	if opts.DryRun != "" && opts.DryRun != "cli" && opts.DryRun != "native" && opts.DryRun != "xml" {
+		return errors.New("dry run output must be cli, native, or xml")
+	}
+
+	if opts.DryRunReverse == true && opts.DryRun != "native" {
+		return errors.New("dry run reverse can only be used with a native dry run")
+	}
+
+	if opts.Confirmed == true && opts.DryRun != "" {
+		return errors.New("a confirmed commit can not be a dry run")
+	}
+
+	if opts.ConfirmTimeout < 0 {
+		return errors.New("confirm timeout can not be negative")
+	}
+
+	if opts.ConfirmTimeout > 0 && opts.Confirmed != true {
+		return errors.New("confirm timeout can only be used with a confirmed commit")
+	}
+
+	if opts.CommitQueue != "" && opts.CommitQueue != "async" && opts.CommitQueue != "sync" && opts.CommitQueue != "bypass" {
+		return errors.New("commit queue must be async, sync, or bypass")
+	}
+
+	if opts.CommitQueueTimeout < 0 {
+		return errors.New("commit queue timeout can not be negative")
+	}
+
+	if opts.CommitQueueTimeout > 0 && opts.CommitQueue != "sync" {
+		return errors.New("commit queue timeout can only be used with a sync commit queue")
+	}
+
+	if opts.NoNetworking == true && opts.CommitQueue != "" {
+		return errors.New("no networking can not be used with a commit queue")
+	}
+
+	if opts.Reconcile != "" && opts.Reconcile != "keep-non-service-config" && opts.Reconcile != "discard-non-service-config" {
+		return errors.New("reconcile must be keep-non-service-config, or discard-non-service-config")
+	}
+
+	if opts.Reconcile != "" && opts.NoDeploy == true {
+		return errors.New("reconcile can not be used with no deploy")
+	}
+
+	if opts.UseLSA == true && opts.NoLSA == true {
+		return errors.New("use lsa and no lsa can not be used together")
+	}
+
+	return nil
+
+}
+
+// Method to convert the options to the NSO commit flags
+func (opts *CommitOptions) Flags() ([]string, error) {
+	var flags []string = nil
+
+	err := opts.Validate()
+
+	if err != nil {
+		return flags, err
+	}
+
+	if opts.DryRun != "" {
+		flags = append(flags, fmt.Sprintf("dry-run=%s", opts.DryRun))
+	}
+
+	if opts.DryRunReverse == true {
+		flags = append(flags, "dry-run-reverse")
+	}
+
+	if opts.Confirmed == true {
+		flags = append(flags, "confirmed")
+		if opts.ConfirmTimeout > 0 {
+			flags = append(flags, fmt.Sprintf("confirm-timeout=%d", opts.ConfirmTimeout))
+		}
+	}
+
+	if opts.Comment != "" {
+		flags = append(flags, fmt.Sprintf("comment=%s", opts.Comment))
+	}
+
+	if opts.Label != "" {
+		flags = append(flags, fmt.Sprintf("label=%s", opts.Label))
+	}
+
+	if opts.NoNetworking == true {
+		flags = append(flags, "no-networking")
+	}
+
+	if opts.NoOutOfSyncCheck == true {
+		flags = append(flags, "no-out-of-sync-check")
+	}
+
+	if opts.NoOverwrite == true {
+		flags = append(flags, "no-overwrite")
+	}
+
+	if opts.NoRevisionDrop == true {
+		flags = append(flags, "no-revision-drop")
+	}
+
+	if opts.NoDeploy == true {
+		flags = append(flags, "no-deploy")
+	}
+
+	if opts.Reconcile != "" {
+		flags = append(flags, fmt.Sprintf("reconcile=%s", opts.Reconcile))
+	}
+
+	if opts.CommitQueue != "" {
+		flags = append(flags, fmt.Sprintf("commit-queue=%s", opts.CommitQueue))
+		if opts.CommitQueueTimeout > 0 {
+			flags = append(flags, fmt.Sprintf("commit-queue-timeout=%d", opts.CommitQueueTimeout))
+		}
+	}
+
+	if opts.UseLSA == true {
+		flags = append(flags, "use-lsa")
+	}
+
+	if opts.NoLSA == true {
+		flags = append(flags, "no-lsa")
+	}
+
+	return flags, nil
+
+}
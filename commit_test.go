@@ -0,0 +1,50 @@
+package nsojsonrpcrequestergo
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestCommitOptions_Flags(t *testing.T) {
+	scenarios := []struct {
+		opts     CommitOptions
+		expect   []string
+		rcvError error
+	}{
+		{opts: CommitOptions{}, expect: nil, rcvError: nil},
+		{opts: CommitOptions{DryRun: "native", DryRunReverse: true}, expect: []string{"dry-run=native", "dry-run-reverse"}, rcvError: nil},
+		{opts: CommitOptions{Comment: "ticket 1234", Label: "pipeline", NoNetworking: true}, expect: []string{"comment=ticket 1234", "label=pipeline", "no-networking"}, rcvError: nil},
+		{opts: CommitOptions{CommitQueue: "sync", CommitQueueTimeout: 60, NoOverwrite: true}, expect: []string{"no-overwrite", "commit-queue=sync", "commit-queue-timeout=60"}, rcvError: nil},
+		{opts: CommitOptions{Confirmed: true, ConfirmTimeout: 300}, expect: []string{"confirmed", "confirm-timeout=300"}, rcvError: nil},
+		{opts: CommitOptions{Reconcile: "keep-non-service-config", UseLSA: true}, expect: []string{"reconcile=keep-non-service-config", "use-lsa"}, rcvError: nil},
+		{opts: CommitOptions{DryRun: "json"}, rcvError: errors.New("dry run output must be cli, native, or xml")},
+		{opts: CommitOptions{DryRun: "cli", DryRunReverse: true}, rcvError: errors.New("dry run reverse can only be used with a native dry run")},
+		{opts: CommitOptions{DryRun: "cli", Confirmed: true}, rcvError: errors.New("a confirmed commit can not be a dry run")},
+		{opts: CommitOptions{ConfirmTimeout: 30}, rcvError: errors.New("confirm timeout can only be used with a confirmed commit")},
+		{opts: CommitOptions{CommitQueue: "later"}, rcvError: errors.New("commit queue must be async, sync, or bypass")},
+		{opts: CommitOptions{CommitQueue: "async", CommitQueueTimeout: 30}, rcvError: errors.New("commit queue timeout can only be used with a sync commit queue")},
+		{opts: CommitOptions{CommitQueue: "async", NoNetworking: true}, rcvError: errors.New("no networking can not be used with a commit queue")},
+		{opts: CommitOptions{Reconcile: "keep-non-service-config", NoDeploy: true}, rcvError: errors.New("reconcile can not be used with no deploy")},
+		{opts: CommitOptions{UseLSA: true, NoLSA: true}, rcvError: errors.New("use lsa and no lsa can not be used together")},
+	}
+
+	for _, scenario := range scenarios {
+		flags, err := scenario.opts.Flags()
+		if scenario.rcvError != nil {
+			if err == nil || err.Error() != scenario.rcvError.Error() {
+				t.Errorf("expected error %v got %v", scenario.rcvError, err)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("unexpected error %v", err)
+		}
+		if fmt.Sprint(flags) != fmt.Sprint(scenario.expect) {
+			t.Errorf("expected %v got %v", scenario.expect, flags)
+		}
+
+	}
+
+}
@@ -2,12 +2,17 @@ package nsojsonrpcrequestergo
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"github.com/imroc/req"
-	"math/rand"
 	"net"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 )
 
@@ -23,6 +28,9 @@ type nsoJsonRpcHTTPConnection struct {
 	headers                          nsoRequestHeaders
 }
 
+// validHostname matches a DNS hostname made of labels of up to 63 characters
+var validHostname = regexp.MustCompile(`^([A-Za-z0-9]([A-Za-z0-9-]{0,61}[A-Za-z0-9])?)(\.[A-Za-z0-9]([A-Za-z0-9-]{0,61}[A-Za-z0-9])?)*\.?$`)
+
 // nsoRequestHeaders holds the common request headers
 type nsoRequestHeaders struct {
 	ContentType string `json:"Content-Type"`
@@ -31,7 +39,7 @@ type nsoRequestHeaders struct {
 
 // Constructor to create a new newNsoJsonRpcHTTPConnection struct
 //   :values protocol: http, https
-//   :values ip: a IPv4 address, or a CNAME
+//   :values ip: a IPv4 address, a IPv6 address, or a hostname
 //   :values port: 1 to 65535
 //   :values username: A username
 //   :values password: A password
@@ -44,27 +52,11 @@ func newNsoJsonRpcHTTPConnection(protocol string, ip string, port int, username
 		return &nsoJsonRpcHTTPConnection{}, errors.New("only http, and https is supported")
 	}
 
-	// Check if ip given is a Ucast IP
-	_, err := IpV4UnicastAddress(ip)
+	// Check if ip given is a IP address, or a hostname
+	ip, err := checkHost(ip)
 
-	// If err comes back try a DNS lookup
 	if err != nil {
-		foundlookup, _ := net.LookupIP(ip)
-
-		// If one or more IPs looked up, grab the first found IPv4
-		if len(foundlookup) >= 1 {
-			for _, v := range foundlookup {
-				foundip, _ := IpV4Address(v.String())
-				ip = foundip
-				break
-			}
-
-			// If no lookup found send error
-		} else {
-			return &nsoJsonRpcHTTPConnection{}, err
-
-		}
-
+		return &nsoJsonRpcHTTPConnection{}, err
 	}
 
 	if (port < 1) || (port > 65535) {
@@ -80,9 +72,53 @@ func newNsoJsonRpcHTTPConnection(protocol string, ip string, port int, username
 
 }
 
+// checkHost verifies a host is a IPv4 address, a IPv6 address, or a hostname
+// hostnames are kept as given so TLS can verify the server certificate
+//   :values host: The host to verify, a IPv6 address can be in brackets
+func checkHost(host string) (string, error) {
+	host = strings.TrimSuffix(strings.TrimPrefix(host, "["), "]")
+
+	// A IPv6 link-local address can have a zone like fe80::1%eth0
+	address := host
+	zone := ""
+	if i := strings.LastIndex(host, "%"); i > -1 {
+		address = host[:i]
+		zone = host[i:]
+	}
+
+	if strings.Contains(address, ":") {
+		ipv6address, err := IpV6Address(address)
+
+		if err != nil {
+			return host, err
+		}
+
+		return ipv6address + zone, nil
+	}
+
+	if zone == "" && net.ParseIP(host) != nil {
+		return IpV4Address(host)
+	}
+
+	// Only digits and dots was meant to be a IPv4 address
+	if strings.Trim(host, "0123456789.") == "" {
+		return host, errors.New("not a valid IPv4 address")
+	}
+
+	if !validHostname.MatchString(host) || len(host) > 253 {
+		return host, errors.New("not a valid hostname")
+	}
+
+	return host, nil
+
+}
+
 // Method to get the NSO JsonRPC URL
 func (c *nsoJsonRpcHTTPConnection) NsoUrl() string {
-	return fmt.Sprintf("%s://%s:%d/jsonrpc", c.protocol, c.ip, c.port)
+	// A IPv6 zone has to be escaped in a URL
+	host := strings.Replace(c.ip, "%", "%25", 1)
+
+	return fmt.Sprintf("%s://%s/jsonrpc", c.protocol, net.JoinHostPort(host, strconv.Itoa(c.port)))
 }
 
 // Method to get the NSO Headers
@@ -91,6 +127,16 @@ func (c *nsoJsonRpcHTTPConnection) NsoHeaders() *nsoRequestHeaders {
 
 }
 
+// Method to convert the headers to a http.Header
+func (h *nsoRequestHeaders) httpHeader() http.Header {
+	header := make(http.Header)
+	header.Set("Content-Type", h.ContentType)
+	header.Set("Accept", h.Accept)
+
+	return header
+
+}
+
 /*
 END OF NSO Server connection
 */
@@ -99,56 +145,234 @@ END OF NSO Server connection
 START OF NSO JSON-Rpc Requester
 */
 
+// abortTimeout is how long to wait for the abort request sent after a cancelled request
+const abortTimeout = 5 * time.Second
+
+// rpcParam holds a JSON-RPC request before it is converted to JSON
+type rpcParam map[string]interface{}
+
 type nsoJsonConnection struct {
-	request *req.Req
-	id      int
-	th      float64
-	nsocon  nsoJsonRpcHTTPConnection
+	transport     Transport
+	nsocon        nsoJsonRpcHTTPConnection
+	headers       http.Header
+	logger        Logger
+	wireDump      bool
+	abortOnCancel bool
+	idLock        sync.Mutex
+	lastID        int
+	inFlight      map[int]string
+	sessionLock   sync.Mutex
+	loggedIn      bool
+	generation    int
+	autoRelogin   bool
+	retryPolicy   *RetryPolicy
+	reloginLock   sync.Mutex
+	transactions  map[*Transaction]bool
 }
 
 // Constructor to create a new newNsoJsonConnection struct
 //   :values protocol: http, https
-//   :values ip: a IPv4 address, or a CNAME
+//   :values ip: a IPv4 address, a IPv6 address, or a hostname
 //   :values port: 1 to 65535
 //   :values username: A username
 //   :values password: A password
 //   :values sslVerify: true to verify SSL, false not to
 func newNsoJsonConnection(protocol string, ip string, port int, username string, password string, sslVerify bool) (*nsoJsonConnection, error) {
-	rand.Seed(int64(time.Now().Second()))
-	newId := rand.Intn(65000-1+1) + 1
+	nsoJson, err := newNsoJsonConnectionWithTransport(protocol, ip, port, username, password, sslVerify, NewHTTPTransport(sslVerify))
 
+	if err != nil {
+		return nsoJson, err
+	}
+
+	return nsoJson, nil
+
+}
+
+// Constructor to create a new newNsoJsonConnection struct with a Transport
+//   :values protocol: http, https
+//   :values ip: a IPv4 address, a IPv6 address, or a hostname
+//   :values port: 1 to 65535
+//   :values username: A username
+//   :values password: A password
+//   :values sslVerify: true to verify SSL, false not to
+//   :values transport: The Transport used to reach the NSO Server
+func newNsoJsonConnectionWithTransport(protocol string, ip string, port int, username string, password string, sslVerify bool, transport Transport) (*nsoJsonConnection, error) {
 	c, err := newNsoJsonRpcHTTPConnection(protocol, ip, port, username, password, sslVerify)
 
 	if err != nil {
 		return &nsoJsonConnection{}, err
 	}
 
-	return &nsoJsonConnection{id: newId, nsocon: *c}, nil
+	if transport == nil {
+		return &nsoJsonConnection{}, errors.New("a transport is required")
+	}
+
+	nsoJson := &nsoJsonConnection{
+		transport:    transport,
+		nsocon:       *c,
+		logger:       noopLogger{},
+		inFlight:     make(map[int]string),
+		transactions: make(map[*Transaction]bool),
+	}
+
+	return nsoJson, nil
+
+}
+
+// Method to convert the NsoJsonRequest to a TransportRequest
+//   :values param: A rpcParam, or a []rpcParam for a batch
+func (nsoJson *nsoJsonConnection) getJsonRequest(param interface{}) (*TransportRequest, error) {
+
+	jsonData, err := json.Marshal(param)
+
+	if err != nil {
+		return nil, err
+	}
+
+	header := nsoJson.nsocon.NsoHeaders().httpHeader()
+
+	for key, values := range nsoJson.headers {
+		header[key] = append([]string(nil), values...)
+	}
+
+	return &TransportRequest{URL: nsoJson.nsocon.NsoUrl(), Header: header, Body: jsonData}, nil
+
+}
+
+// Method to allocate the next request id and mark it in flight
+//   :values param: A rpcParam the id is set in
+func (nsoJson *nsoJsonConnection) startRequest(param rpcParam) int {
+	nsoJson.idLock.Lock()
+	defer nsoJson.idLock.Unlock()
+
+	if nsoJson.inFlight == nil {
+		nsoJson.inFlight = make(map[int]string)
+	}
+
+	nsoJson.lastID++
+	method, _ := param["method"].(string)
+	nsoJson.inFlight[nsoJson.lastID] = method
+	param["id"] = nsoJson.lastID
+
+	return nsoJson.lastID
 
 }
 
-// Method to convert the NsoJsonRequest to a bytes.Buffer for transport
-//   :values param: A req.Param
-func (nsoJson *nsoJsonConnection) getJsonRequest(param req.Param) *bytes.Buffer {
+// Method to mark a request id as no longer in flight
+//   :values id: A request id from startRequest
+func (nsoJson *nsoJsonConnection) finishRequest(id int) {
+	nsoJson.idLock.Lock()
+	defer nsoJson.idLock.Unlock()
+
+	delete(nsoJson.inFlight, id)
+
+}
 
-	jsonData, _ := json.Marshal(param)
+// Method to get the id of the last request sent
+func (nsoJson *nsoJsonConnection) LastRequestID() int {
+	nsoJson.idLock.Lock()
+	defer nsoJson.idLock.Unlock()
 
-	return bytes.NewBuffer(jsonData)
+	return nsoJson.lastID
+
+}
+
+// Method to get the ids of all requests waiting on a response
+// the ids are returned in the order they were sent
+func (nsoJson *nsoJsonConnection) InFlightRequestIDs() []int {
+	nsoJson.idLock.Lock()
+	defer nsoJson.idLock.Unlock()
+
+	ids := make([]int, 0, len(nsoJson.inFlight))
+	for id := range nsoJson.inFlight {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	return ids
+
+}
+
+// Method to check if a request id is waiting on a response
+//   :values id: A request id
+func (nsoJson *nsoJsonConnection) isInFlight(id int) bool {
+	nsoJson.idLock.Lock()
+	defer nsoJson.idLock.Unlock()
+
+	_, ok := nsoJson.inFlight[id]
+
+	return ok
+
+}
+
+// checkDatastore verifies a datastore name is one NSO knows
+//   :values db: running, startup, or candidate
+func checkDatastore(db string) error {
+	if db == "running" || db == "startup" || db == "candidate" {
+		return nil
+	}
+
+	return errors.New("only running, startup, and candidate datastores are supported")
+
+}
+
+// Method to set if an abort is sent to NSO when a request context is cancelled
+//   :values enabled: true to send an abort false to not
+func (nsoJson *nsoJsonConnection) SetAbortOnCancel(enabled bool) {
+	nsoJson.abortOnCancel = enabled
 
 }
 
 // Method to send a POST request
-//   :values param: A req.Param
-func (nsoJson *nsoJsonConnection) sendPost(param req.Param) (*req.Resp, error) {
-	if nsoJson.nsocon.sslVerify == true {
-		nsoJson.request.EnableInsecureTLS(false)
+//   :values param: A rpcParam
+func (nsoJson *nsoJsonConnection) sendPost(param rpcParam) (*NsoJsonResponse, error) {
+	response, err := nsoJson.sendPostContext(context.Background(), param)
 
-	} else {
-		nsoJson.request.EnableInsecureTLS(true)
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+
+}
+
+// Method to send a POST request using a context
+//   :values ctx: A context.Context to cancel the request
+//   :values param: A rpcParam
+func (nsoJson *nsoJsonConnection) sendPostContext(ctx context.Context, param rpcParam) (*NsoJsonResponse, error) {
+	response, err := nsoJson.sendTransactionPostContext(ctx, nil, param)
+
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+
+}
+
+// Method to send a POST request on a transaction using a context
+//   :values ctx: A context.Context to cancel the request
+//   :values trans: The *Transaction the request is sent on, nil if it is not on one
+//   :values param: A rpcParam
+func (nsoJson *nsoJsonConnection) sendTransactionPostContext(ctx context.Context, trans *Transaction, param rpcParam) (*NsoJsonResponse, error) {
+	err := nsoJson.checkTransactionLost(trans)
 
+	if err != nil {
+		return nil, err
 	}
 
-	response, err := nsoJson.request.Post(nsoJson.nsocon.NsoUrl(), req.BodyJSON(nsoJson.getJsonRequest(param)), req.HeaderFromStruct(nsoJson.nsocon.NsoHeaders()))
+	generation := nsoJson.sessionGeneration()
+
+	// The handle is read after the generation so a re-login in between is found by the request failing
+	if trans != nil {
+		param = nsoJson.withTransactionHandle(param, trans)
+	}
+
+	response, err := nsoJson.sendPostRetry(ctx, param)
+
+	if err != nil && nsoJson.canRelogin(param, err) {
+		return nsoJson.reloginAndRetry(ctx, trans, param, generation, err)
+	}
 
 	if err != nil {
 		return response, err
@@ -158,18 +382,42 @@ func (nsoJson *nsoJsonConnection) sendPost(param req.Param) (*req.Resp, error) {
 
 }
 
-// Method to send a GET request
-//   :values param: A req.Param
-func (nsoJson *nsoJsonConnection) sendGet(param req.Param) (*req.Resp, error) {
-	if nsoJson.nsocon.sslVerify == true {
-		nsoJson.request.EnableInsecureTLS(false)
+// Method to send a POST request once without a re-login
+//   :values ctx: A context.Context to cancel the request
+//   :values param: A rpcParam
+func (nsoJson *nsoJsonConnection) sendPostOnce(ctx context.Context, param rpcParam) (*NsoJsonResponse, error) {
+	id := nsoJson.startRequest(param)
+	defer nsoJson.finishRequest(id)
 
-	} else {
-		nsoJson.request.EnableInsecureTLS(true)
+	method, _ := param["method"].(string)
 
+	request, err := nsoJson.getJsonRequest(param)
+
+	if err != nil {
+		return nil, err
 	}
 
-	response, err := nsoJson.request.Get(nsoJson.nsocon.NsoUrl(), req.BodyJSON(nsoJson.getJsonRequest(param)), req.HeaderFromStruct(nsoJson.nsocon.NsoHeaders()))
+	secretValues := isSecretParam(param)
+
+	nsoJson.dumpWire("request", method, id, request.Body, secretValues)
+
+	start := time.Now()
+
+	transportResponse, err := nsoJson.transport.Send(ctx, request)
+
+	if err != nil {
+		nsoJson.logger.Warn("nso json-rpc request failed", "method", method, "id", id, "duration", time.Since(start), "error", err)
+		if ctx.Err() != nil {
+			nsoJson.abortCancelled(param)
+		}
+		return nil, &transportError{err: err}
+	}
+
+	nsoJson.dumpWire("response", method, id, transportResponse.Body, secretValues)
+
+	response, err := nsoJson.checkResponse(param, transportResponse)
+
+	nsoJson.logRequest(method, id, time.Since(start), transportResponse.StatusCode, err)
 
 	if err != nil {
 		return response, err
@@ -179,59 +427,262 @@ func (nsoJson *nsoJsonConnection) sendGet(param req.Param) (*req.Resp, error) {
 
 }
 
+// Method to abort a request on the NSO server after its context was cancelled
+//   :values param: The rpcParam that was cancelled
+func (nsoJson *nsoJsonConnection) abortCancelled(param rpcParam) {
+	if nsoJson.abortOnCancel != true || param["method"] == "abort" || param["method"] == "login" {
+		return
+	}
+
+	requestID, _ := param["id"].(int)
+
+	ctx, cancel := context.WithTimeout(context.Background(), abortTimeout)
+	defer cancel()
+
+	_, _ = nsoJson.AbortContext(ctx, requestID)
+
+}
+
+// nsoJsonEnvelope holds the parts of a JSON-RPC response needed to check for errors
+type nsoJsonEnvelope struct {
+	ID    *int            `json:"id"`
+	Error json.RawMessage `json:"error"`
+}
+
+// Method to check a response for HTTP and JSON-RPC errors
+//   :values param: The rpcParam that was sent
+//   :values transportResponse: The *TransportResponse that came back
+func (nsoJson *nsoJsonConnection) checkResponse(param rpcParam, transportResponse *TransportResponse) (*NsoJsonResponse, error) {
+	method, _ := param["method"].(string)
+	id, _ := param["id"].(int)
+
+	statusCode := transportResponse.StatusCode
+	body := transportResponse.Body
+
+	if statusCode < 200 || statusCode > 299 {
+		return &NsoJsonResponse{StatusCode: statusCode, body: body}, &HTTPStatusError{StatusCode: statusCode, Method: method}
+	}
+
+	if len(bytes.TrimSpace(body)) == 0 {
+		return &NsoJsonResponse{StatusCode: statusCode, body: body}, nil
+	}
+
+	var envelope nsoJsonEnvelope
+
+	err := json.Unmarshal(body, &envelope)
+
+	if err != nil {
+		return &NsoJsonResponse{StatusCode: statusCode, body: body}, fmt.Errorf("could not decode the JSON-RPC response for %s: %w", method, err)
+	}
+
+	response, err := newNsoJsonResponseFromBody(statusCode, body)
+
+	if err != nil {
+		return response, fmt.Errorf("could not decode the JSON-RPC response for %s: %w", method, err)
+	}
+
+	if envelope.ID != nil && *envelope.ID != id {
+		return response, fmt.Errorf("response id %d does not match request id %d for %s", *envelope.ID, id, method)
+	}
+
+	if len(envelope.Error) == 0 || string(envelope.Error) == "null" {
+		return response, nil
+	}
+
+	rpcErr, err := newNsoRpcError(method, id, envelope.Error)
+
+	if err != nil {
+		return response, fmt.Errorf("could not decode the JSON-RPC error for %s: %w", method, err)
+	}
+
+	return response, rpcErr
+
+}
+
 // Method to login to the NSO Server
 func (nsoJson *nsoJsonConnection) NsoLogin() error {
-	param := req.Param{
+	err := nsoJson.NsoLoginContext(context.Background())
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Method to login to the NSO Server using a context
+//   :values ctx: A context.Context to cancel the request
+func (nsoJson *nsoJsonConnection) NsoLoginContext(ctx context.Context) error {
+	param := rpcParam{
 		"jsonrpc": "2.0",
-		"id":      nsoJson.id,
 		"method":  "login",
 		"params":  map[string]string{"user": nsoJson.nsocon.username, "passwd": nsoJson.nsocon.password},
 	}
 
-	request := req.New()
-	nsoJson.request = request
-
-	_, err := nsoJson.sendPost(param)
+	_, err := nsoJson.sendPostContext(ctx, param)
 
 	if err != nil {
 		return err
 	}
 
+	nsoJson.setLoggedIn(true)
+
 	return nil
 
 }
 
 // Method to logout to the NSO Server
 func (nsoJson *nsoJsonConnection) NsoLogout() error {
-	param := req.Param{
+	err := nsoJson.NsoLogoutContext(context.Background())
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Method to logout to the NSO Server using a context
+//   :values ctx: A context.Context to cancel the request
+func (nsoJson *nsoJsonConnection) NsoLogoutContext(ctx context.Context) error {
+	param := rpcParam{
 		"jsonrpc": "2.0",
-		"id":      nsoJson.id,
 		"method":  "logout",
 	}
 
-	response, _ := nsoJson.sendPost(param)
-
-	err := response.Response().Body.Close()
+	_, err := nsoJson.sendPostContext(ctx, param)
 
 	if err != nil {
 		return err
 	}
 
+	nsoJson.setLoggedIn(false)
+
 	return nil
 }
 
-// Method to start a new NSO Transaction
+// Method to set if the session is logged in to the NSO Server
+//   :values loggedIn: true after a login false after a logout
+func (nsoJson *nsoJsonConnection) setLoggedIn(loggedIn bool) {
+	nsoJson.sessionLock.Lock()
+	defer nsoJson.sessionLock.Unlock()
+
+	nsoJson.loggedIn = loggedIn
+
+	if loggedIn {
+		nsoJson.generation++
+	}
+
+}
+
+// Method to get how many times the session has logged in
+// it is used to tell if a re-login already happened
+func (nsoJson *nsoJsonConnection) sessionGeneration() int {
+	nsoJson.sessionLock.Lock()
+	defer nsoJson.sessionLock.Unlock()
+
+	return nsoJson.generation
+
+}
+
+// Method to check if the session is logged in to the NSO Server
+func (nsoJson *nsoJsonConnection) isLoggedIn() bool {
+	nsoJson.sessionLock.Lock()
+	defer nsoJson.sessionLock.Unlock()
+
+	return nsoJson.loggedIn
+
+}
+
+// Method to start a new NSO Transaction on the running datastore
+//   :values mode: read, or read_write
+//   :values confMode: private, shared, or exclusive
+//   :values tag: "" or a value
+//   :values onPendingChanges: reuse, reject, or discard
+func (nsoJson *nsoJsonConnection) NewTransaction(mode, confMode, tag, onPendingChanges string) (*Transaction, error) {
+	trans, err := nsoJson.NewTransactionContext(context.Background(), mode, confMode, tag, onPendingChanges)
+
+	if err != nil {
+		return trans, err
+	}
+
+	return trans, nil
+}
+
+// Method to start a new NSO Transaction on the running datastore using a context
+//   :values ctx: A context.Context to cancel the request
+//   :values mode: read, or read_write
+//   :values confMode: private, shared, or exclusive
+//   :values tag: "" or a value
+//   :values onPendingChanges: reuse, reject, or discard
+func (nsoJson *nsoJsonConnection) NewTransactionContext(ctx context.Context, mode, confMode, tag, onPendingChanges string) (*Transaction, error) {
+	trans, err := nsoJson.NewTransactionDBContext(ctx, "running", mode, confMode, tag, onPendingChanges)
+
+	if err != nil {
+		return trans, err
+	}
+
+	return trans, nil
+}
+
+// Method to start a new NSO Transaction on a datastore
+//   :values db: running, startup, or candidate
+//   :values mode: read, or read_write
+//   :values confMode: private, shared, or exclusive
+//   :values tag: "" or a value
+//   :values onPendingChanges: reuse, reject, or discard
+func (nsoJson *nsoJsonConnection) NewTransactionDB(db, mode, confMode, tag, onPendingChanges string) (*Transaction, error) {
+	trans, err := nsoJson.NewTransactionDBContext(context.Background(), db, mode, confMode, tag, onPendingChanges)
+
+	if err != nil {
+		return trans, err
+	}
+
+	return trans, nil
+}
+
+// Method to start a new NSO Transaction on a datastore using a context
+//   :values ctx: A context.Context to cancel the request
+//   :values db: running, startup, or candidate
 //   :values mode: read, or read_write
 //   :values confMode: private, shared, or exclusive
 //   :values tag: "" or a value
 //   :values onPendingChanges: reuse, reject, or discard
-func (nsoJson *nsoJsonConnection) NewTransaction(mode, confMode, tag, onPendingChanges string) error {
-	param := req.Param{
+func (nsoJson *nsoJsonConnection) NewTransactionDBContext(ctx context.Context, db, mode, confMode, tag, onPendingChanges string) (*Transaction, error) {
+	err := checkDatastore(db)
+
+	if err != nil {
+		return nil, err
+	}
+
+	param := newTransactionParam(db, mode, confMode, tag, onPendingChanges)
+
+	response, err := nsoJson.sendPostContext(ctx, param)
+
+	if err != nil {
+		return nil, err
+	}
+
+	th := response.GetTransactionHandle()
+
+	trans := newTransaction(nsoJson, th, db, mode, confMode, tag, onPendingChanges)
+	nsoJson.registerTransaction(trans)
+
+	return trans, nil
+}
+
+// newTransactionParam creates the rpcParam to start a new NSO Transaction
+//   :values db: running, startup, or candidate
+//   :values mode: read, or read_write
+//   :values confMode: private, shared, or exclusive
+//   :values tag: "" or a value
+//   :values onPendingChanges: reuse, reject, or discard
+func newTransactionParam(db, mode, confMode, tag, onPendingChanges string) rpcParam {
+	param := rpcParam{
 		"jsonrpc": "2.0",
-		"id":      nsoJson.id,
 		"method":  "new_trans",
 		"params": map[string]string{
-			"db":                 "running",
+			"db":                 db,
 			"mode":               mode,
 			"conf_mode":          confMode,
 			"tag":                tag,
@@ -239,27 +690,325 @@ func (nsoJson *nsoJsonConnection) NewTransaction(mode, confMode, tag, onPendingC
 		},
 	}
 
-	response, err := nsoJson.sendPost(param)
+	return param
+
+}
+
+// Method to take a lock on a NSO datastore
+//   :values db: running, startup, or candidate
+func (nsoJson *nsoJsonConnection) LockDB(db string) (*NsoJsonResponse, error) {
+	response, err := nsoJson.LockDBContext(context.Background(), db)
 
 	if err != nil {
-		return err
+		return response, err
 	}
 
-	nsoResponse := NewNsoJsonResponse()
-	nsoJson.th = nsoResponse.GetTransactionHandle(response)
+	return response, nil
+}
 
-	return nil
+// Method to take a lock on a NSO datastore using a context
+//   :values ctx: A context.Context to cancel the request
+//   :values db: running, startup, or candidate
+func (nsoJson *nsoJsonConnection) LockDBContext(ctx context.Context, db string) (*NsoJsonResponse, error) {
+	err := checkDatastore(db)
+
+	if err != nil {
+		return nil, err
+	}
+
+	param := rpcParam{
+		"jsonrpc": "2.0",
+		"method":  "lock_db",
+		"params": map[string]interface{}{
+			"db": db,
+		},
+	}
+
+	response, err := nsoJson.sendPostContext(ctx, param)
+
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+// Method to release a lock on a NSO datastore
+//   :values db: running, startup, or candidate
+func (nsoJson *nsoJsonConnection) UnlockDB(db string) (*NsoJsonResponse, error) {
+	response, err := nsoJson.UnlockDBContext(context.Background(), db)
+
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+// Method to release a lock on a NSO datastore using a context
+//   :values ctx: A context.Context to cancel the request
+//   :values db: running, startup, or candidate
+func (nsoJson *nsoJsonConnection) UnlockDBContext(ctx context.Context, db string) (*NsoJsonResponse, error) {
+	err := checkDatastore(db)
+
+	if err != nil {
+		return nil, err
+	}
+
+	param := rpcParam{
+		"jsonrpc": "2.0",
+		"method":  "unlock_db",
+		"params": map[string]interface{}{
+			"db": db,
+		},
+	}
+
+	response, err := nsoJson.sendPostContext(ctx, param)
+
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+// Method to reset the candidate datastore to the running datastore
+func (nsoJson *nsoJsonConnection) ResetCandidateDB() (*NsoJsonResponse, error) {
+	response, err := nsoJson.ResetCandidateDBContext(context.Background())
+
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+// Method to reset the candidate datastore to the running datastore using a context
+//   :values ctx: A context.Context to cancel the request
+func (nsoJson *nsoJsonConnection) ResetCandidateDBContext(ctx context.Context) (*NsoJsonResponse, error) {
+	param := rpcParam{
+		"jsonrpc": "2.0",
+		"method":  "reset_candidate_db",
+	}
+
+	response, err := nsoJson.sendPostContext(ctx, param)
+
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+// Method to copy the running datastore to the startup datastore
+func (nsoJson *nsoJsonConnection) CopyRunningToStartup() (*NsoJsonResponse, error) {
+	response, err := nsoJson.CopyRunningToStartupContext(context.Background())
+
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+// Method to copy the running datastore to the startup datastore using a context
+//   :values ctx: A context.Context to cancel the request
+func (nsoJson *nsoJsonConnection) CopyRunningToStartupContext(ctx context.Context) (*NsoJsonResponse, error) {
+	param := rpcParam{
+		"jsonrpc": "2.0",
+		"method":  "copy_running_to_startup",
+	}
+
+	response, err := nsoJson.sendPostContext(ctx, param)
+
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+// Method to check if the running datastore has been copied to the startup datastore
+func (nsoJson *nsoJsonConnection) ExistsRunningToStartup() (*NsoJsonResponse, error) {
+	response, err := nsoJson.ExistsRunningToStartupContext(context.Background())
+
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+// Method to check if the running datastore has been copied to the startup datastore using a context
+//   :values ctx: A context.Context to cancel the request
+func (nsoJson *nsoJsonConnection) ExistsRunningToStartupContext(ctx context.Context) (*NsoJsonResponse, error) {
+	param := rpcParam{
+		"jsonrpc": "2.0",
+		"method":  "exists_running_to_startup",
+	}
+
+	response, err := nsoJson.sendPostContext(ctx, param)
+
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+// Method to confirm the pending confirmed commit
+func (nsoJson *nsoJsonConnection) ConfirmCommit() (*NsoJsonResponse, error) {
+	response, err := nsoJson.ConfirmCommitContext(context.Background())
+
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+// Method to confirm the pending confirmed commit using a context
+//   :values ctx: A context.Context to cancel the request
+func (nsoJson *nsoJsonConnection) ConfirmCommitContext(ctx context.Context) (*NsoJsonResponse, error) {
+	param := rpcParam{
+		"jsonrpc": "2.0",
+		"method":  "confirm_commit",
+	}
+
+	response, err := nsoJson.sendPostContext(ctx, param)
+
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+// Method to cancel the pending confirmed commit
+// The configuration is rolled back to what it was before the confirmed commit
+func (nsoJson *nsoJsonConnection) CancelConfirmedCommit() (*NsoJsonResponse, error) {
+	response, err := nsoJson.CancelConfirmedCommitContext(context.Background())
+
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+// Method to cancel the pending confirmed commit using a context
+// The configuration is rolled back to what it was before the confirmed commit
+// this is the cancel_commit method, like the NETCONF cancel-commit operation
+//   :values ctx: A context.Context to cancel the request
+func (nsoJson *nsoJsonConnection) CancelConfirmedCommitContext(ctx context.Context) (*NsoJsonResponse, error) {
+	param := rpcParam{
+		"jsonrpc": "2.0",
+		"method":  "cancel_commit",
+		"params":  map[string]interface{}{},
+	}
+
+	response, err := nsoJson.sendPostContext(ctx, param)
+
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+// Method to get the metadata of all rollback files, newest first
+func (nsoJson *nsoJsonConnection) GetRollbacks() ([]Rollback, error) {
+	response, err := nsoJson.GetRollbacksContext(context.Background())
+
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+// Method to get the metadata of all rollback files, newest first using a context
+//   :values ctx: A context.Context to cancel the request
+func (nsoJson *nsoJsonConnection) GetRollbacksContext(ctx context.Context) ([]Rollback, error) {
+	param := rpcParam{
+		"jsonrpc": "2.0",
+		"method":  "get_rollbacks",
+	}
+
+	response, err := nsoJson.sendPostContext(ctx, param)
+
+	if err != nil {
+		return []Rollback{}, err
+	}
+
+	rollbacks, err := newRollbacks(response)
+
+	if err != nil {
+		return rollbacks, err
+	}
+
+	return rollbacks, nil
+}
+
+// Method to get the content of a rollback file
+//   :values number: The rollback number
+func (nsoJson *nsoJsonConnection) GetRollback(number int) (string, error) {
+	content, err := nsoJson.GetRollbackContext(context.Background(), number)
+
+	if err != nil {
+		return content, err
+	}
+
+	return content, nil
+}
+
+// Method to get the content of a rollback file using a context
+//   :values ctx: A context.Context to cancel the request
+//   :values number: The rollback number
+func (nsoJson *nsoJsonConnection) GetRollbackContext(ctx context.Context, number int) (string, error) {
+	param := rpcParam{
+		"jsonrpc": "2.0",
+		"method":  "get_rollback",
+		"params": map[string]interface{}{
+			"nr": number,
+		},
+	}
+
+	response, err := nsoJson.sendPostContext(ctx, param)
+
+	if err != nil {
+		return "", err
+	}
+
+	content, err := newRollbackContent(response)
+
+	if err != nil {
+		return content, err
+	}
+
+	return content, nil
 }
 
 // Method to get all NSO transactions
-func (nsoJson *nsoJsonConnection) GetTransaction() (*req.Resp, error) {
-	param := req.Param{
+func (nsoJson *nsoJsonConnection) GetTransaction() (*NsoJsonResponse, error) {
+	response, err := nsoJson.GetTransactionContext(context.Background())
+
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+// Method to get all NSO transactions using a context
+//   :values ctx: A context.Context to cancel the request
+func (nsoJson *nsoJsonConnection) GetTransactionContext(ctx context.Context) (*NsoJsonResponse, error) {
+	param := rpcParam{
 		"jsonrpc": "2.0",
-		"id":      nsoJson.id,
 		"method":  "get_trans",
 	}
 
-	response, err := nsoJson.sendPost(param)
+	response, err := nsoJson.sendPostContext(ctx, param)
 
 	if err != nil {
 		return response, err
@@ -270,17 +1019,29 @@ func (nsoJson *nsoJsonConnection) GetTransaction() (*req.Resp, error) {
 
 // Method to get NSO system settings
 //   :values operation: capabilities, customizations , models, user, version, or all
-func (nsoJson *nsoJsonConnection) GetSystemSetting(operation string) (*req.Resp, error) {
-	param := req.Param{
+func (nsoJson *nsoJsonConnection) GetSystemSetting(operation string) (*NsoJsonResponse, error) {
+	response, err := nsoJson.GetSystemSettingContext(context.Background(), operation)
+
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+// Method to get NSO system settings using a context
+//   :values ctx: A context.Context to cancel the request
+//   :values operation: capabilities, customizations , models, user, version, or all
+func (nsoJson *nsoJsonConnection) GetSystemSettingContext(ctx context.Context, operation string) (*NsoJsonResponse, error) {
+	param := rpcParam{
 		"jsonrpc": "2.0",
-		"id":      nsoJson.id,
 		"method":  "get_system_setting",
 		"params": map[string]string{
 			"operation": operation,
 		},
 	}
 
-	response, err := nsoJson.sendPost(param)
+	response, err := nsoJson.sendPostContext(ctx, param)
 
 	if err != nil {
 		return response, err
@@ -290,18 +1051,10 @@ func (nsoJson *nsoJsonConnection) GetSystemSetting(operation string) (*req.Resp,
 }
 
 // Method to abort a request-id
+// The request has to be in flight, see InFlightRequestIDs
 //   :values requestID: An id
-func (nsoJson *nsoJsonConnection) Abort(requestID int) (*req.Resp, error) {
-	param := req.Param{
-		"jsonrpc": "2.0",
-		"id":      nsoJson.id,
-		"method":  "abort",
-		"params": map[string]int{
-			"id": requestID,
-		},
-	}
-
-	response, err := nsoJson.sendPost(param)
+func (nsoJson *nsoJsonConnection) Abort(requestID int) (*NsoJsonResponse, error) {
+	response, err := nsoJson.AbortContext(context.Background(), requestID)
 
 	if err != nil {
 		return response, err
@@ -310,20 +1063,24 @@ func (nsoJson *nsoJsonConnection) Abort(requestID int) (*req.Resp, error) {
 	return response, nil
 }
 
-// Method to evaluate a xpath expression
-//   :values xpathExpression: An xpath expression
-func (nsoJson *nsoJsonConnection) EvalXPATH(xpathExpression string) (*req.Resp, error) {
-	param := req.Param{
+// Method to abort a request-id using a context
+// The request has to be in flight, see InFlightRequestIDs
+//   :values ctx: A context.Context to cancel the request
+//   :values requestID: An id
+func (nsoJson *nsoJsonConnection) AbortContext(ctx context.Context, requestID int) (*NsoJsonResponse, error) {
+	if !nsoJson.isInFlight(requestID) {
+		return nil, fmt.Errorf("request id %d is not in flight", requestID)
+	}
+
+	param := rpcParam{
 		"jsonrpc": "2.0",
-		"id":      nsoJson.id,
-		"method":  "eval_xpath",
-		"params": map[string]interface{}{
-			"th":         nsoJson.th,
-			"xpath_expr": xpathExpression,
+		"method":  "abort",
+		"params": map[string]int{
+			"id": requestID,
 		},
 	}
 
-	response, err := nsoJson.sendPost(param)
+	response, err := nsoJson.sendPostContext(ctx, param)
 
 	if err != nil {
 		return response, err
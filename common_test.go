@@ -1,8 +1,17 @@
 package nsojsonrpcrequestergo
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 )
 
 func Test_newNsoJsonRpcHTTPConnectionGoodParams(t *testing.T) {
@@ -73,6 +82,8 @@ func Test_newNsoJsonRpcHTTPConnectionBadParams(t *testing.T) {
 	}{
 		{protocol: "ssh", ip: "192.168.1.1", port: 8080, username: "admin", password: "admin", sslVerify: false, rcvError: errors.New("only http, and https is supported")},
 		{protocol: "https", ip: "192.168.1.1000", port: 443, username: "user", password: "pass", sslVerify: true, rcvError: errors.New("not a valid IPv4 address")},
+		{protocol: "https", ip: "2001:db8::zz", port: 443, username: "user", password: "pass", sslVerify: true, rcvError: errors.New("not a valid IPv6 address")},
+		{protocol: "https", ip: "nso_1.example.com", port: 443, username: "user", password: "pass", sslVerify: true, rcvError: errors.New("not a valid hostname")},
 		{protocol: "https", ip: "192.168.1.1", port: 0, username: "user", password: "pass", sslVerify: true, rcvError: errors.New("valid port range between 1 and 65535")},
 		{protocol: "https", ip: "192.168.1.1", port: 65536, username: "user", password: "pass", sslVerify: true, rcvError: errors.New("valid port range between 1 and 65535")},
 	}
@@ -102,6 +113,10 @@ func Test_newNsoJsonRpcHTTPConnection_NsoUrl(t *testing.T) {
 	}{
 		{protocol: "http", ip: "192.168.1.1", port: 8080, username: "admin", password: "admin", sslVerify: false, expect: "http://192.168.1.1:8080/jsonrpc"},
 		{protocol: "https", ip: "192.168.1.1", port: 443, username: "user", password: "pass", sslVerify: true, expect: "https://192.168.1.1:443/jsonrpc"},
+		{protocol: "https", ip: "2001:db8::1", port: 8888, username: "user", password: "pass", sslVerify: true, expect: "https://[2001:db8::1]:8888/jsonrpc"},
+		{protocol: "https", ip: "[2001:DB8::1]", port: 8888, username: "user", password: "pass", sslVerify: true, expect: "https://[2001:db8::1]:8888/jsonrpc"},
+		{protocol: "http", ip: "fe80::1%eth0", port: 8080, username: "user", password: "pass", sslVerify: true, expect: "http://[fe80::1%25eth0]:8080/jsonrpc"},
+		{protocol: "https", ip: "nso.example.com", port: 8888, username: "user", password: "pass", sslVerify: true, expect: "https://nso.example.com:8888/jsonrpc"},
 	}
 
 	for _, scenario := range scenarios {
@@ -155,3 +170,179 @@ func Test_nsoJsonConnection_sendPost(*testing.T) {
 	}
 
 }
+
+func Test_nsoJsonConnection_sendPostContextAbort(t *testing.T) {
+	methods := make(chan string, 10)
+	release := make(chan struct{})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		method, _ := body["method"].(string)
+		methods <- method
+
+		if method == "commit" {
+			<-release
+		}
+
+		_, _ = w.Write([]byte(fmt.Sprintf(`{"jsonrpc": "2.0", "id": %v, "result": {}}`, body["id"])))
+	}))
+	defer server.Close()
+	defer close(release)
+
+	nsoJson, err := newNsoJsonConnection("http", "127.0.0.1", testServerPort(server), "admin", "admin", false)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	nsoJson.SetAbortOnCancel(true)
+
+	err = nsoJson.NsoLogin()
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err = nsoJson.sendPostContext(ctx, rpcParam{"jsonrpc": "2.0", "method": "commit"})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected error %v got %v", context.DeadlineExceeded, err)
+	}
+
+	expect := []string{"login", "commit", "abort"}
+	for _, method := range expect {
+		if rcvMethod := <-methods; rcvMethod != method {
+			t.Errorf("expected %v got %v", method, rcvMethod)
+		}
+	}
+
+}
+
+// testServerPort gets the port a httptest.Server listens on
+func testServerPort(server *httptest.Server) int {
+	port, _ := strconv.Atoi(server.URL[strings.LastIndex(server.URL, ":")+1:])
+
+	return port
+
+}
+
+func Test_nsoJsonConnection_requestIDs(t *testing.T) {
+	scenarios := []struct {
+		replyID  string
+		rcvError error
+	}{
+		{replyID: "", rcvError: nil},
+		{replyID: "999", rcvError: errors.New("response id 999 does not match request id 3 for get_trans")},
+	}
+
+	for _, scenario := range scenarios {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var body map[string]interface{}
+			_ = json.NewDecoder(r.Body).Decode(&body)
+
+			replyID := fmt.Sprint(body["id"])
+			if scenario.replyID != "" && body["method"] != "login" {
+				replyID = scenario.replyID
+			}
+
+			_, _ = w.Write([]byte(fmt.Sprintf(`{"jsonrpc": "2.0", "id": %s, "result": {}}`, replyID)))
+		}))
+
+		nsoJson, _ := newNsoJsonConnection("http", "127.0.0.1", testServerPort(server), "admin", "admin", false)
+
+		_ = nsoJson.NsoLogin()
+		_, _ = nsoJson.GetSystemSetting("version")
+		_, err := nsoJson.GetTransaction()
+
+		if nsoJson.LastRequestID() != 3 {
+			t.Errorf("expected %v got %v", 3, nsoJson.LastRequestID())
+		}
+		if len(nsoJson.InFlightRequestIDs()) != 0 {
+			t.Errorf("expected no requests in flight got %v", nsoJson.InFlightRequestIDs())
+		}
+		if scenario.rcvError == nil && err != nil {
+			t.Errorf("unexpected error %v", err)
+		}
+		if scenario.rcvError != nil && (err == nil || err.Error() != scenario.rcvError.Error()) {
+			t.Errorf("expected error %v got %v", scenario.rcvError, err)
+		}
+
+		_, err = nsoJson.Abort(1)
+		if err == nil || err.Error() != "request id 1 is not in flight" {
+			t.Errorf("expected error %v got %v", "request id 1 is not in flight", err)
+		}
+
+		server.Close()
+
+	}
+
+}
+
+// testJsonRpcServer starts a httptest.Server that answers each JSON-RPC method
+// with the given result, and records the methods called
+func testJsonRpcServer(results map[string]string) (*httptest.Server, *[]string) {
+	var called []string
+	var lock sync.Mutex
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		method, _ := body["method"].(string)
+
+		lock.Lock()
+		called = append(called, method)
+		lock.Unlock()
+
+		result, ok := results[method]
+		if !ok {
+			result = "{}"
+		}
+
+		_, _ = w.Write([]byte(fmt.Sprintf(`{"jsonrpc": "2.0", "id": %v, "result": %s}`, body["id"], result)))
+	}))
+
+	return server, &called
+
+}
+
+func Test_Transaction_lifecycle(t *testing.T) {
+	server, called := testJsonRpcServer(map[string]string{
+		"new_trans":         `{"th": 7}`,
+		"is_trans_modified": `{"modified": true}`,
+	})
+	defer server.Close()
+
+	nsoJson, _ := newNsoJsonConnection("http", "127.0.0.1", testServerPort(server), "admin", "admin", false)
+
+	_ = nsoJson.NsoLogin()
+	trans, _ := nsoJson.NewTransaction("read_write", "private", "", "reuse")
+
+	if trans.Handle() != 7 {
+		t.Errorf("expected %v got %v", 7, trans.Handle())
+	}
+
+	_, _ = trans.SetComment("ticket 1234")
+	_, _ = trans.SetLabel("pipeline")
+
+	modified, err := trans.IsModified()
+	if err != nil || modified != true {
+		t.Errorf("expected %v got %v %v", true, modified, err)
+	}
+
+	_, _ = trans.GetChanges()
+
+	err = trans.DeleteTransaction()
+	if err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+	if trans.Handle() != 0 {
+		t.Errorf("expected %v got %v", 0, trans.Handle())
+	}
+
+	expect := []string{"login", "new_trans", "set_trans_comment", "set_trans_label", "is_trans_modified", "get_trans_changes", "delete_trans"}
+	if fmt.Sprint(*called) != fmt.Sprint(expect) {
+		t.Errorf("expected %v got %v", expect, *called)
+	}
+
+}
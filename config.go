@@ -1,19 +1,20 @@
 package nsojsonrpcrequestergo
 
 import (
+	"context"
 	"errors"
-	"fmt"
-	"github.com/imroc/req"
 )
 
 // NsoJsonRpcConfig holds a NSO JSON RPC config needs
+// The data methods use the Transaction last started with NewTransaction
 type NsoJsonRpcConfig struct {
-	nsocon nsoJsonConnection
+	nsocon *nsoJsonConnection
+	trans  *Transaction
 }
 
 // Constructor for a NsoJsonRpcConfig
 //   :values protocol: http, https
-//   :values ip: a IPv4 address, or a CNAME
+//   :values ip: a IPv4 address, a IPv6 address, or a hostname
 //   :values port: 1 to 65535
 //   :values username: A username
 //   :values password: A password
@@ -26,51 +27,1125 @@ func NewNsoJsonRpcConfig(protocol string, ip string, port int, username string,
 		return &NsoJsonRpcConfig{}, err
 	}
 
-	return &NsoJsonRpcConfig{nsocon: *nsoJson}, nil
+	return &NsoJsonRpcConfig{nsocon: nsoJson}, nil
+
+}
+
+// Constructor for a NsoJsonRpcConfig using a Transport
+// TLS settings are up to the Transport
+//   :values protocol: http, https
+//   :values ip: a IPv4 address, a IPv6 address, or a hostname
+//   :values port: 1 to 65535
+//   :values username: A username
+//   :values password: A password
+//   :values transport: The Transport used to reach the NSO Server
+func NewNsoJsonRpcConfigWithTransport(protocol string, ip string, port int, username string, password string, transport Transport) (*NsoJsonRpcConfig, error) {
+
+	nsoJson, err := newNsoJsonConnectionWithTransport(protocol, ip, port, username, password, true, transport)
+
+	if err != nil {
+		return &NsoJsonRpcConfig{}, err
+	}
+
+	return &NsoJsonRpcConfig{nsocon: nsoJson}, nil
+
+}
+
+// Method to set if an abort is sent to NSO when a request context is cancelled
+//   :values enabled: true to send an abort false to not
+func (config *NsoJsonRpcConfig) SetAbortOnCancel(enabled bool) {
+	config.nsocon.SetAbortOnCancel(enabled)
+
+}
+
+// Method to set if the session logs in again when NSO says it is invalid
+// read transactions are started again and idempotent requests are sent once more
+// a request on a lost read_write transaction returns ErrTransactionLost
+//   :values enabled: true to login again false to not
+func (config *NsoJsonRpcConfig) SetAutoRelogin(enabled bool) {
+	config.nsocon.SetAutoRelogin(enabled)
+
+}
+
+// Method to set the Logger, nil turns logging off
+//   :values logger: A Logger, a *slog.Logger works
+func (config *NsoJsonRpcConfig) SetLogger(logger Logger) {
+	config.nsocon.SetLogger(logger)
+
+}
+
+// Method to set if the full request and response bodies are logged at debug level
+// passwords and other secrets are redacted
+//   :values enabled: true to log the bodies false to not
+func (config *NsoJsonRpcConfig) SetWireDump(enabled bool) {
+	config.nsocon.SetWireDump(enabled)
+
+}
+
+// Method to set the RetryPolicy, nil turns retries off
+// non-idempotent methods like commit are only retried when they are in the policy Methods
+//   :values policy: A *RetryPolicy
+func (config *NsoJsonRpcConfig) SetRetryPolicy(policy *RetryPolicy) error {
+	err := config.nsocon.SetRetryPolicy(policy)
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+
+}
+
+// Method to get the id of the last request sent
+func (config *NsoJsonRpcConfig) LastRequestID() int {
+	return config.nsocon.LastRequestID()
+
+}
+
+// Method to get the ids of all requests waiting on a response
+// use these with Abort to abort a specific request
+func (config *NsoJsonRpcConfig) InFlightRequestIDs() []int {
+	return config.nsocon.InFlightRequestIDs()
 
 }
 
 // Method to login to the NSO Server
 func (config *NsoJsonRpcConfig) NsoLogin() error {
-	err := config.nsocon.NsoLogin()
+	err := config.NsoLoginContext(context.Background())
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Method to login to the NSO Server using a context
+//   :values ctx: A context.Context to cancel the request
+func (config *NsoJsonRpcConfig) NsoLoginContext(ctx context.Context) error {
+	err := config.nsocon.NsoLoginContext(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+
+}
+
+// Method to logout to the NSO Server
+func (config *NsoJsonRpcConfig) NsoLogout() error {
+	err := config.NsoLogoutContext(context.Background())
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Method to logout to the NSO Server using a context
+//   :values ctx: A context.Context to cancel the request
+func (config *NsoJsonRpcConfig) NsoLogoutContext(ctx context.Context) error {
+	err := config.nsocon.NsoLogoutContext(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Method to start a new NSO Transaction on the running datastore
+//   :values mode: read, or read_write
+//   :values confMode: private, shared, or exclusive
+//   :values tag: "" or a value
+//   :values onPendingChanges: reuse, reject, or discard
+func (config *NsoJsonRpcConfig) NewTransaction(mode, confMode, tag, onPendingChanges string) (*Transaction, error) {
+	trans, err := config.NewTransactionContext(context.Background(), mode, confMode, tag, onPendingChanges)
+
+	if err != nil {
+		return trans, err
+	}
+
+	return trans, nil
+}
+
+// Method to start a new NSO Transaction on the running datastore using a context
+//   :values ctx: A context.Context to cancel the request
+//   :values mode: read, or read_write
+//   :values confMode: private, shared, or exclusive
+//   :values tag: "" or a value
+//   :values onPendingChanges: reuse, reject, or discard
+func (config *NsoJsonRpcConfig) NewTransactionContext(ctx context.Context, mode, confMode, tag, onPendingChanges string) (*Transaction, error) {
+	trans, err := config.nsocon.NewTransactionContext(ctx, mode, confMode, tag, onPendingChanges)
+
+	if err != nil {
+		return trans, err
+	}
+
+	config.trans = trans
+
+	return trans, nil
+}
+
+// Method to start a new NSO Transaction on a datastore
+//   :values db: running, startup, or candidate
+//   :values mode: read, or read_write
+//   :values confMode: private, shared, or exclusive
+//   :values tag: "" or a value
+//   :values onPendingChanges: reuse, reject, or discard
+func (config *NsoJsonRpcConfig) NewTransactionDB(db, mode, confMode, tag, onPendingChanges string) (*Transaction, error) {
+	trans, err := config.NewTransactionDBContext(context.Background(), db, mode, confMode, tag, onPendingChanges)
+
+	if err != nil {
+		return trans, err
+	}
+
+	return trans, nil
+}
+
+// Method to start a new NSO Transaction on a datastore using a context
+//   :values ctx: A context.Context to cancel the request
+//   :values db: running, startup, or candidate
+//   :values mode: read, or read_write
+//   :values confMode: private, shared, or exclusive
+//   :values tag: "" or a value
+//   :values onPendingChanges: reuse, reject, or discard
+func (config *NsoJsonRpcConfig) NewTransactionDBContext(ctx context.Context, db, mode, confMode, tag, onPendingChanges string) (*Transaction, error) {
+	trans, err := config.nsocon.NewTransactionDBContext(ctx, db, mode, confMode, tag, onPendingChanges)
+
+	if err != nil {
+		return trans, err
+	}
+
+	config.trans = trans
+
+	return trans, nil
+}
+
+// Method to take a lock on a NSO datastore
+//   :values db: running, startup, or candidate
+func (config *NsoJsonRpcConfig) LockDB(db string) (*NsoJsonResponse, error) {
+	response, err := config.LockDBContext(context.Background(), db)
+
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+// Method to take a lock on a NSO datastore using a context
+//   :values ctx: A context.Context to cancel the request
+//   :values db: running, startup, or candidate
+func (config *NsoJsonRpcConfig) LockDBContext(ctx context.Context, db string) (*NsoJsonResponse, error) {
+	response, err := config.nsocon.LockDBContext(ctx, db)
+
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+// Method to release a lock on a NSO datastore
+//   :values db: running, startup, or candidate
+func (config *NsoJsonRpcConfig) UnlockDB(db string) (*NsoJsonResponse, error) {
+	response, err := config.UnlockDBContext(context.Background(), db)
+
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+// Method to release a lock on a NSO datastore using a context
+//   :values ctx: A context.Context to cancel the request
+//   :values db: running, startup, or candidate
+func (config *NsoJsonRpcConfig) UnlockDBContext(ctx context.Context, db string) (*NsoJsonResponse, error) {
+	response, err := config.nsocon.UnlockDBContext(ctx, db)
+
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+// Method to reset the candidate datastore to the running datastore
+func (config *NsoJsonRpcConfig) ResetCandidateDB() (*NsoJsonResponse, error) {
+	response, err := config.ResetCandidateDBContext(context.Background())
+
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+// Method to reset the candidate datastore to the running datastore using a context
+//   :values ctx: A context.Context to cancel the request
+func (config *NsoJsonRpcConfig) ResetCandidateDBContext(ctx context.Context) (*NsoJsonResponse, error) {
+	response, err := config.nsocon.ResetCandidateDBContext(ctx)
+
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+// Method to copy the running datastore to the startup datastore
+func (config *NsoJsonRpcConfig) CopyRunningToStartup() (*NsoJsonResponse, error) {
+	response, err := config.CopyRunningToStartupContext(context.Background())
+
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+// Method to copy the running datastore to the startup datastore using a context
+//   :values ctx: A context.Context to cancel the request
+func (config *NsoJsonRpcConfig) CopyRunningToStartupContext(ctx context.Context) (*NsoJsonResponse, error) {
+	response, err := config.nsocon.CopyRunningToStartupContext(ctx)
+
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+// Method to check if the running datastore has been copied to the startup datastore
+func (config *NsoJsonRpcConfig) ExistsRunningToStartup() (*NsoJsonResponse, error) {
+	response, err := config.ExistsRunningToStartupContext(context.Background())
+
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+// Method to check if the running datastore has been copied to the startup datastore using a context
+//   :values ctx: A context.Context to cancel the request
+func (config *NsoJsonRpcConfig) ExistsRunningToStartupContext(ctx context.Context) (*NsoJsonResponse, error) {
+	response, err := config.nsocon.ExistsRunningToStartupContext(ctx)
+
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+// Method to get the current NSO Transaction
+// the current transaction is the one last started with NewTransaction
+func (config *NsoJsonRpcConfig) Transaction() *Transaction {
+	return config.trans
+
+}
+
+// Method to get the handle of the current NSO Transaction
+// 0 is returned if no transaction has been started
+func (config *NsoJsonRpcConfig) TransactionHandle() float64 {
+	if config.trans == nil {
+		return 0
+	}
+
+	return config.trans.Handle()
+
+}
+
+// Method to get the current NSO Transaction or an error if none was started
+func (config *NsoJsonRpcConfig) transaction() (*Transaction, error) {
+	if config.trans == nil {
+		return nil, errors.New("no transaction started, call NewTransaction first")
+	}
+
+	return config.trans, nil
+
+}
+
+// Method to delete the current NSO Transaction
+func (config *NsoJsonRpcConfig) DeleteTransaction() error {
+	err := config.DeleteTransactionContext(context.Background())
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Method to delete the current NSO Transaction using a context
+//   :values ctx: A context.Context to cancel the request
+func (config *NsoJsonRpcConfig) DeleteTransactionContext(ctx context.Context) error {
+	trans, err := config.transaction()
+
+	if err != nil {
+		return err
+	}
+
+	err = trans.DeleteTransactionContext(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Method to set a comment on the current NSO Transaction
+// The comment is shown in the NSO commit log
+//   :values comment: A comment
+func (config *NsoJsonRpcConfig) SetTransactionComment(comment string) (*NsoJsonResponse, error) {
+	response, err := config.SetTransactionCommentContext(context.Background(), comment)
+
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+// Method to set a comment on the current NSO Transaction using a context
+// The comment is shown in the NSO commit log
+//   :values ctx: A context.Context to cancel the request
+//   :values comment: A comment
+func (config *NsoJsonRpcConfig) SetTransactionCommentContext(ctx context.Context, comment string) (*NsoJsonResponse, error) {
+	trans, err := config.transaction()
+
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := trans.SetCommentContext(ctx, comment)
+
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+// Method to set a label on the current NSO Transaction
+// The label is shown in the NSO commit log
+//   :values label: A label
+func (config *NsoJsonRpcConfig) SetTransactionLabel(label string) (*NsoJsonResponse, error) {
+	response, err := config.SetTransactionLabelContext(context.Background(), label)
+
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+// Method to set a label on the current NSO Transaction using a context
+// The label is shown in the NSO commit log
+//   :values ctx: A context.Context to cancel the request
+//   :values label: A label
+func (config *NsoJsonRpcConfig) SetTransactionLabelContext(ctx context.Context, label string) (*NsoJsonResponse, error) {
+	trans, err := config.transaction()
+
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := trans.SetLabelContext(ctx, label)
+
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+// Method to check if the current NSO Transaction has been modified
+func (config *NsoJsonRpcConfig) IsTransactionModified() (bool, error) {
+	result, err := config.IsTransactionModifiedContext(context.Background())
+
+	if err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
+
+// Method to check if the current NSO Transaction has been modified using a context
+//   :values ctx: A context.Context to cancel the request
+func (config *NsoJsonRpcConfig) IsTransactionModifiedContext(ctx context.Context) (bool, error) {
+	trans, err := config.transaction()
+
+	if err != nil {
+		return false, err
+	}
+
+	result, err := trans.IsModifiedContext(ctx)
+
+	if err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
+
+// Method to get the changes made in the current NSO Transaction
+func (config *NsoJsonRpcConfig) GetTransactionChanges() (*NsoJsonResponse, error) {
+	response, err := config.GetTransactionChangesContext(context.Background())
+
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+// Method to get the changes made in the current NSO Transaction using a context
+//   :values ctx: A context.Context to cancel the request
+func (config *NsoJsonRpcConfig) GetTransactionChangesContext(ctx context.Context) (*NsoJsonResponse, error) {
+	trans, err := config.transaction()
+
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := trans.GetChangesContext(ctx)
+
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+// Method to get the conflicts registered in the current NSO Transaction
+func (config *NsoJsonRpcConfig) GetTransactionConflicts() (*NsoJsonResponse, error) {
+	response, err := config.GetTransactionConflictsContext(context.Background())
+
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+// Method to get the conflicts registered in the current NSO Transaction using a context
+//   :values ctx: A context.Context to cancel the request
+func (config *NsoJsonRpcConfig) GetTransactionConflictsContext(ctx context.Context) (*NsoJsonResponse, error) {
+	trans, err := config.transaction()
+
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := trans.GetConflictsContext(ctx)
+
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+// Method to resolve the conflicts registered in the current NSO Transaction
+func (config *NsoJsonRpcConfig) ResolveTransactionConflicts() (*NsoJsonResponse, error) {
+	response, err := config.ResolveTransactionConflictsContext(context.Background())
+
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+// Method to resolve the conflicts registered in the current NSO Transaction using a context
+//   :values ctx: A context.Context to cancel the request
+func (config *NsoJsonRpcConfig) ResolveTransactionConflictsContext(ctx context.Context) (*NsoJsonResponse, error) {
+	trans, err := config.transaction()
+
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := trans.ResolveConflictsContext(ctx)
+
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+// Method to get all NSO transactions
+func (config *NsoJsonRpcConfig) GetTransaction() (*NsoJsonResponse, error) {
+	response, err := config.GetTransactionContext(context.Background())
+
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+// Method to get all NSO transactions using a context
+//   :values ctx: A context.Context to cancel the request
+func (config *NsoJsonRpcConfig) GetTransactionContext(ctx context.Context) (*NsoJsonResponse, error) {
+	response, err := config.nsocon.GetTransactionContext(ctx)
+
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+// Method to get NSO system settings
+//   :values operation: capabilities, customizations , models, user, version, or all
+func (config *NsoJsonRpcConfig) GetSystemSetting(operation string) (*NsoJsonResponse, error) {
+	response, err := config.GetSystemSettingContext(context.Background(), operation)
+
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+// Method to get NSO system settings using a context
+//   :values ctx: A context.Context to cancel the request
+//   :values operation: capabilities, customizations , models, user, version, or all
+func (config *NsoJsonRpcConfig) GetSystemSettingContext(ctx context.Context, operation string) (*NsoJsonResponse, error) {
+	response, err := config.nsocon.GetSystemSettingContext(ctx, operation)
+
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+// Method to abort a request-id
+//   :values requestID: An id
+func (config *NsoJsonRpcConfig) Abort(requestID int) (*NsoJsonResponse, error) {
+	response, err := config.AbortContext(context.Background(), requestID)
+
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+// Method to abort a request-id using a context
+//   :values ctx: A context.Context to cancel the request
+//   :values requestID: An id
+func (config *NsoJsonRpcConfig) AbortContext(ctx context.Context, requestID int) (*NsoJsonResponse, error) {
+	response, err := config.nsocon.AbortContext(ctx, requestID)
+
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+// Method to evaluate a xpath expression
+//   :values xpathExpression: An xpath expression
+func (config *NsoJsonRpcConfig) EvalXPATH(xpathExpression string) (*NsoJsonResponse, error) {
+	response, err := config.EvalXPATHContext(context.Background(), xpathExpression)
+
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+// Method to evaluate a xpath expression using a context
+//   :values ctx: A context.Context to cancel the request
+//   :values xpathExpression: An xpath expression
+func (config *NsoJsonRpcConfig) EvalXPATHContext(ctx context.Context, xpathExpression string) (*NsoJsonResponse, error) {
+	trans, err := config.transaction()
+
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := trans.EvalXPATHContext(ctx, xpathExpression)
+
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+// Method to show NSO config
+//   :values path: A key path
+//   :values resultAs: string, or json
+//   :values withOper: true for operational data false for not
+//   :values maxSize: 0 to disable limit any other number to limit
+func (config *NsoJsonRpcConfig) ShowConfig(path, resultAs string, withOper bool, maxSize int) (*NsoJsonResponse, error) {
+	response, err := config.ShowConfigContext(context.Background(), path, resultAs, withOper, maxSize)
+
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+// Method to show NSO config using a context
+//   :values ctx: A context.Context to cancel the request
+//   :values path: A key path
+//   :values resultAs: string, or json
+//   :values withOper: true for operational data false for not
+//   :values maxSize: 0 to disable limit any other number to limit
+func (config *NsoJsonRpcConfig) ShowConfigContext(ctx context.Context, path, resultAs string, withOper bool, maxSize int) (*NsoJsonResponse, error) {
+	trans, err := config.transaction()
+
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := trans.ShowConfigContext(ctx, path, resultAs, withOper, maxSize)
+
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+// Method to deref NSO config
+//   :values path: A key path
+//   :values resultAs: paths, target, or list-target
+func (config *NsoJsonRpcConfig) Deref(path, resultAs string) (*NsoJsonResponse, error) {
+	response, err := config.DerefContext(context.Background(), path, resultAs)
+
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+// Method to deref NSO config using a context
+//   :values ctx: A context.Context to cancel the request
+//   :values path: A key path
+//   :values resultAs: paths, target, or list-target
+func (config *NsoJsonRpcConfig) DerefContext(ctx context.Context, path, resultAs string) (*NsoJsonResponse, error) {
+	trans, err := config.transaction()
+
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := trans.DerefContext(ctx, path, resultAs)
+
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+// Method to get leaf reference values
+//   :values path: A key path
+//   :values skipGrouping: true to skip grouping false to not
+//   :values keys: array of keys
+func (config *NsoJsonRpcConfig) GetLeafrefValues(path string, skipGrouping bool, keys []string) (*NsoJsonResponse, error) {
+	response, err := config.GetLeafrefValuesContext(context.Background(), path, skipGrouping, keys)
+
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+// Method to get leaf reference values using a context
+//   :values ctx: A context.Context to cancel the request
+//   :values path: A key path
+//   :values skipGrouping: true to skip grouping false to not
+//   :values keys: array of keys
+func (config *NsoJsonRpcConfig) GetLeafrefValuesContext(ctx context.Context, path string, skipGrouping bool, keys []string) (*NsoJsonResponse, error) {
+	trans, err := config.transaction()
+
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := trans.GetLeafrefValuesContext(ctx, path, skipGrouping, keys)
+
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+// Method to run an action
+//   :values path: A key path
+//   :values inputData: A map of data
+func (config *NsoJsonRpcConfig) RunAction(path string, inputData map[string]interface{}) (*NsoJsonResponse, error) {
+	response, err := config.RunActionContext(context.Background(), path, inputData)
+
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+// Method to run an action using a context
+//   :values ctx: A context.Context to cancel the request
+//   :values path: A key path
+//   :values inputData: A map of data
+func (config *NsoJsonRpcConfig) RunActionContext(ctx context.Context, path string, inputData map[string]interface{}) (*NsoJsonResponse, error) {
+	trans, err := config.transaction()
+
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := trans.RunActionContext(ctx, path, inputData)
+
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+// Method to get a schema
+//   :values path: A key path
+func (config *NsoJsonRpcConfig) GetSchema(path string) (*NsoJsonResponse, error) {
+	response, err := config.GetSchemaContext(context.Background(), path)
+
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+// Method to get a schema using a context
+//   :values ctx: A context.Context to cancel the request
+//   :values path: A key path
+func (config *NsoJsonRpcConfig) GetSchemaContext(ctx context.Context, path string) (*NsoJsonResponse, error) {
+	trans, err := config.transaction()
+
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := trans.GetSchemaContext(ctx, path)
+
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+// Method to get a list of keys
+//   :values path: A key path
+func (config *NsoJsonRpcConfig) GetListKeys(path string) (*NsoJsonResponse, error) {
+	response, err := config.GetListKeysContext(context.Background(), path)
+
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+// Method to get a list of keys using a context
+//   :values ctx: A context.Context to cancel the request
+//   :values path: A key path
+func (config *NsoJsonRpcConfig) GetListKeysContext(ctx context.Context, path string) (*NsoJsonResponse, error) {
+	trans, err := config.transaction()
+
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := trans.GetListKeysContext(ctx, path)
+
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+// Method to get a leaf value
+//   :values path: A key path
+//   :values checkDefault: true to check for default value false to not
+func (config *NsoJsonRpcConfig) GetValue(path string, checkDefault bool) (*NsoJsonResponse, error) {
+	response, err := config.GetValueContext(context.Background(), path, checkDefault)
+
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+// Method to get a leaf value using a context
+//   :values ctx: A context.Context to cancel the request
+//   :values path: A key path
+//   :values checkDefault: true to check for default value false to not
+func (config *NsoJsonRpcConfig) GetValueContext(ctx context.Context, path string, checkDefault bool) (*NsoJsonResponse, error) {
+	trans, err := config.transaction()
+
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := trans.GetValueContext(ctx, path, checkDefault)
+
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+// Method to get multiple leaf values
+//   :values path: A key path
+//   :values leafs: A array of leafs
+//   :values checkDefault: true to check for default value false to not
+func (config *NsoJsonRpcConfig) GetValues(path string, leafs []string, checkDefault bool) (*NsoJsonResponse, error) {
+	response, err := config.GetValuesContext(context.Background(), path, leafs, checkDefault)
+
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+// Method to get multiple leaf values using a context
+//   :values ctx: A context.Context to cancel the request
+//   :values path: A key path
+//   :values leafs: A array of leafs
+//   :values checkDefault: true to check for default value false to not
+func (config *NsoJsonRpcConfig) GetValuesContext(ctx context.Context, path string, leafs []string, checkDefault bool) (*NsoJsonResponse, error) {
+	trans, err := config.transaction()
+
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := trans.GetValuesContext(ctx, path, leafs, checkDefault)
+
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+// Method to create a leaf
+//   :values path: A key path
+func (config *NsoJsonRpcConfig) Create(path string) (*NsoJsonResponse, error) {
+	response, err := config.CreateContext(context.Background(), path)
+
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+// Method to create a leaf using a context
+//   :values ctx: A context.Context to cancel the request
+//   :values path: A key path
+func (config *NsoJsonRpcConfig) CreateContext(ctx context.Context, path string) (*NsoJsonResponse, error) {
+	trans, err := config.transaction()
+
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := trans.CreateContext(ctx, path)
+
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+// Method to check if a leaf exists
+//   :values path: A key path
+func (config *NsoJsonRpcConfig) Exists(path string) (*NsoJsonResponse, error) {
+	response, err := config.ExistsContext(context.Background(), path)
+
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+// Method to check if a leaf exists using a context
+//   :values ctx: A context.Context to cancel the request
+//   :values path: A key path
+func (config *NsoJsonRpcConfig) ExistsContext(ctx context.Context, path string) (*NsoJsonResponse, error) {
+	trans, err := config.transaction()
+
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := trans.ExistsContext(ctx, path)
+
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+// Method to get a choice/case
+//   :values path: A key path
+//   :values choice: A choice from a case
+func (config *NsoJsonRpcConfig) GetCase(path, choice string) (*NsoJsonResponse, error) {
+	response, err := config.GetCaseContext(context.Background(), path, choice)
+
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+// Method to get a choice/case using a context
+//   :values ctx: A context.Context to cancel the request
+//   :values path: A key path
+//   :values choice: A choice from a case
+func (config *NsoJsonRpcConfig) GetCaseContext(ctx context.Context, path, choice string) (*NsoJsonResponse, error) {
+	trans, err := config.transaction()
+
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := trans.GetCaseContext(ctx, path, choice)
+
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+// Method to load data to NSO
+//   :values data: The data to be loaded
+//   :values path: A key path use "/" at the very least
+//   :values dataFormat: json, or xml
+//   :values mode: create, merge, or replace
+func (config *NsoJsonRpcConfig) Load(data, path, dataFormat, mode string) (*NsoJsonResponse, error) {
+	response, err := config.LoadContext(context.Background(), data, path, dataFormat, mode)
+
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+// Method to load data to NSO using a context
+//   :values ctx: A context.Context to cancel the request
+//   :values data: The data to be loaded
+//   :values path: A key path use "/" at the very least
+//   :values dataFormat: json, or xml
+//   :values mode: create, merge, or replace
+func (config *NsoJsonRpcConfig) LoadContext(ctx context.Context, data, path, dataFormat, mode string) (*NsoJsonResponse, error) {
+	trans, err := config.transaction()
+
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := trans.LoadContext(ctx, data, path, dataFormat, mode)
+
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+// Method to set a value
+//   :values path: A key path
+//   :values value: What you want to set
+//   :values dryRun: true for dryrun false for not
+func (config *NsoJsonRpcConfig) SetValue(path string, value interface{}, dryRun bool) (*NsoJsonResponse, error) {
+	response, err := config.SetValueContext(context.Background(), path, value, dryRun)
+
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+// Method to set a value using a context
+//   :values ctx: A context.Context to cancel the request
+//   :values path: A key path
+//   :values value: What you want to set
+//   :values dryRun: true for dryrun false for not
+func (config *NsoJsonRpcConfig) SetValueContext(ctx context.Context, path string, value interface{}, dryRun bool) (*NsoJsonResponse, error) {
+	trans, err := config.transaction()
 
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	return nil
+	response, err := trans.SetValueContext(ctx, path, value, dryRun)
+
+	if err != nil {
+		return response, err
+	}
 
+	return response, nil
 }
 
-// Method to logout to the NSO Server
-func (config *NsoJsonRpcConfig) NsoLogout() error {
-	err := config.nsocon.NsoLogout()
+// Method to validate a commit
+//    In the CLI commits are validated automatically, in JsonRPC
+//    they are not, but only validated commits can be committed
+func (config *NsoJsonRpcConfig) ValidateCommit() (*NsoJsonResponse, error) {
+	response, err := config.ValidateCommitContext(context.Background())
 
 	if err != nil {
-		return err
+		return response, err
 	}
 
-	return nil
+	return response, nil
 }
 
-// Method to start a new NSO Transaction
-//   :values mode: read, or read_write
-//   :values confMode: private, shared, or exclusive
-//   :values tag: "" or a value
-//   :values onPendingChanges: reuse, reject, or discard
-func (config *NsoJsonRpcConfig) NewTransaction(mode, confMode, tag, onPendingChanges string) error {
-	err := config.nsocon.NewTransaction(mode, confMode, tag, onPendingChanges)
+// Method to validate a commit using a context
+//    In the CLI commits are validated automatically, in JsonRPC
+//    they are not, but only validated commits can be committed
+//   :values ctx: A context.Context to cancel the request
+func (config *NsoJsonRpcConfig) ValidateCommitContext(ctx context.Context) (*NsoJsonResponse, error) {
+	trans, err := config.transaction()
 
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	return nil
-}
-
-// Method to get all NSO transactions
-func (config *NsoJsonRpcConfig) GetTransaction() (*req.Resp, error) {
-	response, err := config.nsocon.GetTransaction()
+	response, err := trans.ValidateCommitContext(ctx)
 
 	if err != nil {
 		return response, err
@@ -79,10 +1154,13 @@ func (config *NsoJsonRpcConfig) GetTransaction() (*req.Resp, error) {
 	return response, nil
 }
 
-// Method to get NSO system settings
-//   :values operation: capabilities, customizations , models, user, version, or all
-func (config *NsoJsonRpcConfig) GetSystemSetting(operation string) (*req.Resp, error) {
-	response, err := config.nsocon.GetSystemSetting(operation)
+// Method to commit
+//   :values dryRun: true for dryrun false for not
+//   :values output: cli, native, or xml, empty is cli
+//   :values reverse: true for reverse diff false for forward diff
+//                    config only can be used with native
+func (config *NsoJsonRpcConfig) Commit(dryRun bool, output string, reverse bool) (*NsoJsonResponse, error) {
+	response, err := config.CommitContext(context.Background(), dryRun, output, reverse)
 
 	if err != nil {
 		return response, err
@@ -91,10 +1169,20 @@ func (config *NsoJsonRpcConfig) GetSystemSetting(operation string) (*req.Resp, e
 	return response, nil
 }
 
-// Method to abort a request-id
-//   :values requestID: An id
-func (config *NsoJsonRpcConfig) Abort(requestID int) (*req.Resp, error) {
-	response, err := config.nsocon.Abort(requestID)
+// Method to commit using a context
+//   :values ctx: A context.Context to cancel the request
+//   :values dryRun: true for dryrun false for not
+//   :values output: cli, native, or xml, empty is cli
+//   :values reverse: true for reverse diff false for forward diff
+//                    config only can be used with native
+func (config *NsoJsonRpcConfig) CommitContext(ctx context.Context, dryRun bool, output string, reverse bool) (*NsoJsonResponse, error) {
+	trans, err := config.transaction()
+
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := trans.CommitContext(ctx, dryRun, output, reverse)
 
 	if err != nil {
 		return response, err
@@ -103,10 +1191,10 @@ func (config *NsoJsonRpcConfig) Abort(requestID int) (*req.Resp, error) {
 	return response, nil
 }
 
-// Method to evaluate a xpath expression
-//   :values xpathExpression: An xpath expression
-func (config *NsoJsonRpcConfig) EvalXPATH(xpathExpression string) (*req.Resp, error) {
-	response, err := config.nsocon.EvalXPATH(xpathExpression)
+// Method to commit with a set of CommitOptions
+//   :values opts: The CommitOptions, nil for a plain commit
+func (config *NsoJsonRpcConfig) CommitWithOptions(opts *CommitOptions) (*NsoJsonResponse, error) {
+	response, err := config.CommitWithOptionsContext(context.Background(), opts)
 
 	if err != nil {
 		return response, err
@@ -115,26 +1203,17 @@ func (config *NsoJsonRpcConfig) EvalXPATH(xpathExpression string) (*req.Resp, er
 	return response, nil
 }
 
-// Method to show NSO config
-//   :values path: A key path
-//   :values resultAs: string, or json
-//   :values withOper: true for operational data false for not
-//   :values maxSize: 0 to disable limit any other number to limit
-func (config *NsoJsonRpcConfig) ShowConfig(path, resultAs string, withOper bool, maxSize int) (*req.Resp, error) {
-	param := req.Param{
-		"jsonrpc": "2.0",
-		"id":      config.nsocon.id,
-		"method":  "show_config",
-		"params": map[string]interface{}{
-			"th":        config.nsocon.th,
-			"path":      path,
-			"result_as": resultAs,
-			"with_oper": withOper,
-			"max_size":  maxSize,
-		},
+// Method to commit with a set of CommitOptions using a context
+//   :values ctx: A context.Context to cancel the request
+//   :values opts: The CommitOptions, nil for a plain commit
+func (config *NsoJsonRpcConfig) CommitWithOptionsContext(ctx context.Context, opts *CommitOptions) (*NsoJsonResponse, error) {
+	trans, err := config.transaction()
+
+	if err != nil {
+		return nil, err
 	}
 
-	response, err := config.nsocon.sendPost(param)
+	response, err := trans.CommitWithOptionsContext(ctx, opts)
 
 	if err != nil {
 		return response, err
@@ -143,48 +1222,46 @@ func (config *NsoJsonRpcConfig) ShowConfig(path, resultAs string, withOper bool,
 	return response, nil
 }
 
-// Method to deref NSO config
-//   :values path: A key path
-//   :values resultAs: paths, target, or list-target
-func (config *NsoJsonRpcConfig) Deref(path, resultAs string) (*req.Resp, error) {
-	param := req.Param{
-		"jsonrpc": "2.0",
-		"id":      config.nsocon.id,
-		"method":  "deref",
-		"params": map[string]interface{}{
-			"th":        config.nsocon.th,
-			"path":      path,
-			"result_as": resultAs,
-		},
+// Method to do a dry-run commit and parse the result per device
+//   :values output: cli, native, or xml, empty is cli
+//   :values reverse: true for reverse diff false for forward diff
+//                    config only can be used with native
+func (config *NsoJsonRpcConfig) DryRun(output string, reverse bool) (*DryRunResult, error) {
+	result, err := config.DryRunContext(context.Background(), output, reverse)
+
+	if err != nil {
+		return result, err
 	}
 
-	response, err := config.nsocon.sendPost(param)
+	return result, nil
+}
+
+// Method to do a dry-run commit and parse the result per device using a context
+//   :values ctx: A context.Context to cancel the request
+//   :values output: cli, native, or xml, empty is cli
+//   :values reverse: true for reverse diff false for forward diff
+//                    config only can be used with native
+func (config *NsoJsonRpcConfig) DryRunContext(ctx context.Context, output string, reverse bool) (*DryRunResult, error) {
+	trans, err := config.transaction()
 
 	if err != nil {
-		return response, err
+		return nil, err
 	}
 
-	return response, nil
-}
+	result, err := trans.DryRunContext(ctx, output, reverse)
 
-// Method to get leaf reference values
-//   :values path: A key path
-//   :values skipGrouping: true to skip grouping false to not
-//   :values keys: array of keys
-func (config *NsoJsonRpcConfig) GetLeafrefValues(path string, skipGrouping bool, keys []string) (*req.Resp, error) {
-	param := req.Param{
-		"jsonrpc": "2.0",
-		"id":      config.nsocon.id,
-		"method":  "get_leafref_values",
-		"params": map[string]interface{}{
-			"th":            config.nsocon.th,
-			"path":          path,
-			"skip_grouping": skipGrouping,
-			"keys":          keys,
-		},
+	if err != nil {
+		return result, err
 	}
 
-	response, err := config.nsocon.sendPost(param)
+	return result, nil
+}
+
+// Method to do a confirmed commit
+// The commit is rolled back by NSO unless ConfirmCommit is called before the timeout
+//   :values timeout: Seconds to wait for ConfirmCommit, must be greater than 0
+func (config *NsoJsonRpcConfig) CommitConfirmed(timeout int) (*NsoJsonResponse, error) {
+	response, err := config.CommitConfirmedContext(context.Background(), timeout)
 
 	if err != nil {
 		return response, err
@@ -193,22 +1270,18 @@ func (config *NsoJsonRpcConfig) GetLeafrefValues(path string, skipGrouping bool,
 	return response, nil
 }
 
-// Method to run an action
-//   :values path: A key path
-//   :values inputData: A map of data
-func (config *NsoJsonRpcConfig) RunAction(path string, inputData map[string]interface{}) (*req.Resp, error) {
-	param := req.Param{
-		"jsonrpc": "2.0",
-		"id":      config.nsocon.id,
-		"method":  "run_action",
-		"params": map[string]interface{}{
-			"th":     config.nsocon.th,
-			"path":   path,
-			"params": inputData,
-		},
+// Method to do a confirmed commit using a context
+// The commit is rolled back by NSO unless ConfirmCommit is called before the timeout
+//   :values ctx: A context.Context to cancel the request
+//   :values timeout: Seconds to wait for ConfirmCommit, must be greater than 0
+func (config *NsoJsonRpcConfig) CommitConfirmedContext(ctx context.Context, timeout int) (*NsoJsonResponse, error) {
+	trans, err := config.transaction()
+
+	if err != nil {
+		return nil, err
 	}
 
-	response, err := config.nsocon.sendPost(param)
+	response, err := trans.CommitConfirmedContext(ctx, timeout)
 
 	if err != nil {
 		return response, err
@@ -217,20 +1290,9 @@ func (config *NsoJsonRpcConfig) RunAction(path string, inputData map[string]inte
 	return response, nil
 }
 
-// Method to get a schema
-//   :values path: A key path
-func (config *NsoJsonRpcConfig) GetSchema(path string) (*req.Resp, error) {
-	param := req.Param{
-		"jsonrpc": "2.0",
-		"id":      config.nsocon.id,
-		"method":  "get_schema",
-		"params": map[string]interface{}{
-			"th":   config.nsocon.th,
-			"path": path,
-		},
-	}
-
-	response, err := config.nsocon.sendPost(param)
+// Method to confirm the pending confirmed commit
+func (config *NsoJsonRpcConfig) ConfirmCommit() (*NsoJsonResponse, error) {
+	response, err := config.ConfirmCommitContext(context.Background())
 
 	if err != nil {
 		return response, err
@@ -239,20 +1301,10 @@ func (config *NsoJsonRpcConfig) GetSchema(path string) (*req.Resp, error) {
 	return response, nil
 }
 
-// Method to get a list of keys
-//   :values path: A key path
-func (config *NsoJsonRpcConfig) GetListKeys(path string) (*req.Resp, error) {
-	param := req.Param{
-		"jsonrpc": "2.0",
-		"id":      config.nsocon.id,
-		"method":  "get_list_keys",
-		"params": map[string]interface{}{
-			"th":   config.nsocon.th,
-			"path": path,
-		},
-	}
-
-	response, err := config.nsocon.sendPost(param)
+// Method to confirm the pending confirmed commit using a context
+//   :values ctx: A context.Context to cancel the request
+func (config *NsoJsonRpcConfig) ConfirmCommitContext(ctx context.Context) (*NsoJsonResponse, error) {
+	response, err := config.nsocon.ConfirmCommitContext(ctx)
 
 	if err != nil {
 		return response, err
@@ -261,22 +1313,10 @@ func (config *NsoJsonRpcConfig) GetListKeys(path string) (*req.Resp, error) {
 	return response, nil
 }
 
-// Method to get a leaf value
-//   :values path: A key path
-//   :values checkDefault: true to check for default value false to not
-func (config *NsoJsonRpcConfig) GetValue(path string, checkDefault bool) (*req.Resp, error) {
-	param := req.Param{
-		"jsonrpc": "2.0",
-		"id":      config.nsocon.id,
-		"method":  "get_value",
-		"params": map[string]interface{}{
-			"th":            config.nsocon.th,
-			"path":          path,
-			"check_default": checkDefault,
-		},
-	}
-
-	response, err := config.nsocon.sendPost(param)
+// Method to cancel the pending confirmed commit
+// The configuration is rolled back to what it was before the confirmed commit
+func (config *NsoJsonRpcConfig) CancelConfirmedCommit() (*NsoJsonResponse, error) {
+	response, err := config.CancelConfirmedCommitContext(context.Background())
 
 	if err != nil {
 		return response, err
@@ -285,24 +1325,11 @@ func (config *NsoJsonRpcConfig) GetValue(path string, checkDefault bool) (*req.R
 	return response, nil
 }
 
-// Method to get multiple leaf values
-//   :values path: A key path
-//   :values leafs: A array of leafs
-//   :values checkDefault: true to check for default value false to not
-func (config *NsoJsonRpcConfig) GetValues(path string, leafs []string, checkDefault bool) (*req.Resp, error) {
-	param := req.Param{
-		"jsonrpc": "2.0",
-		"id":      config.nsocon.id,
-		"method":  "get_values",
-		"params": map[string]interface{}{
-			"th":            config.nsocon.th,
-			"path":          path,
-			"check_default": checkDefault,
-			"leafs":         leafs,
-		},
-	}
-
-	response, err := config.nsocon.sendPost(param)
+// Method to cancel the pending confirmed commit using a context
+// The configuration is rolled back to what it was before the confirmed commit
+//   :values ctx: A context.Context to cancel the request
+func (config *NsoJsonRpcConfig) CancelConfirmedCommitContext(ctx context.Context) (*NsoJsonResponse, error) {
+	response, err := config.nsocon.CancelConfirmedCommitContext(ctx)
 
 	if err != nil {
 		return response, err
@@ -311,66 +1338,60 @@ func (config *NsoJsonRpcConfig) GetValues(path string, leafs []string, checkDefa
 	return response, nil
 }
 
-// Method to create a leaf
-//   :values path: A key path
-func (config *NsoJsonRpcConfig) Create(path string) (*req.Resp, error) {
-	param := req.Param{
-		"jsonrpc": "2.0",
-		"id":      config.nsocon.id,
-		"method":  "create",
-		"params": map[string]interface{}{
-			"th":   config.nsocon.th,
-			"path": path,
-		},
-	}
-
-	response, err := config.nsocon.sendPost(param)
+// Method to get the metadata of all rollback files, newest first
+func (config *NsoJsonRpcConfig) GetRollbacks() ([]Rollback, error) {
+	rollbacks, err := config.GetRollbacksContext(context.Background())
 
 	if err != nil {
-		return response, err
+		return rollbacks, err
 	}
 
-	return response, nil
+	return rollbacks, nil
 }
 
-// Method to check if a leaf exists
-//   :values path: A key path
-func (config *NsoJsonRpcConfig) Exists(path string) (*req.Resp, error) {
-	param := req.Param{
-		"jsonrpc": "2.0",
-		"id":      config.nsocon.id,
-		"method":  "exists",
-		"params": map[string]interface{}{
-			"th":   config.nsocon.th,
-			"path": path,
-		},
+// Method to get the metadata of all rollback files, newest first using a context
+//   :values ctx: A context.Context to cancel the request
+func (config *NsoJsonRpcConfig) GetRollbacksContext(ctx context.Context) ([]Rollback, error) {
+	rollbacks, err := config.nsocon.GetRollbacksContext(ctx)
+
+	if err != nil {
+		return rollbacks, err
 	}
 
-	response, err := config.nsocon.sendPost(param)
+	return rollbacks, nil
+}
+
+// Method to get the content of a rollback file
+//   :values number: The rollback number
+func (config *NsoJsonRpcConfig) GetRollback(number int) (string, error) {
+	content, err := config.GetRollbackContext(context.Background(), number)
 
 	if err != nil {
-		return response, err
+		return content, err
 	}
 
-	return response, nil
+	return content, nil
 }
 
-// Method to get a choice/case
-//   :values path: A key path
-//   :values choice: A choice from a case
-func (config *NsoJsonRpcConfig) GetCase(path, choice string) (*req.Resp, error) {
-	param := req.Param{
-		"jsonrpc": "2.0",
-		"id":      config.nsocon.id,
-		"method":  "get_case",
-		"params": map[string]interface{}{
-			"th":     config.nsocon.th,
-			"path":   path,
-			"choice": choice,
-		},
+// Method to get the content of a rollback file using a context
+//   :values ctx: A context.Context to cancel the request
+//   :values number: The rollback number
+func (config *NsoJsonRpcConfig) GetRollbackContext(ctx context.Context, number int) (string, error) {
+	content, err := config.nsocon.GetRollbackContext(ctx, number)
+
+	if err != nil {
+		return content, err
 	}
 
-	response, err := config.nsocon.sendPost(param)
+	return content, nil
+}
+
+// Method to load a rollback file into the NSO Transaction
+// The changes are applied when the transaction is committed
+//   :values number: The rollback number
+//   :values selective: true to only undo that one commit, false to undo it and all newer commits
+func (config *NsoJsonRpcConfig) LoadRollback(number int, selective bool) (*NsoJsonResponse, error) {
+	response, err := config.LoadRollbackContext(context.Background(), number, selective)
 
 	if err != nil {
 		return response, err
@@ -379,26 +1400,19 @@ func (config *NsoJsonRpcConfig) GetCase(path, choice string) (*req.Resp, error)
 	return response, nil
 }
 
-// Method to load data to NSO
-//   :values data: The data to be loaded
-//   :values path: A key path use "/" at the very least
-//   :values dataFormat: json, or xml
-//   :values mode: create, merge, or replace
-func (config *NsoJsonRpcConfig) Load(data, path, dataFormat, mode string) (*req.Resp, error) {
-	param := req.Param{
-		"jsonrpc": "2.0",
-		"id":      config.nsocon.id,
-		"method":  "load",
-		"params": map[string]interface{}{
-			"th":     config.nsocon.th,
-			"data":   data,
-			"path":   path,
-			"format": dataFormat,
-			"mode":   mode,
-		},
+// Method to load a rollback file into the NSO Transaction using a context
+// The changes are applied when the transaction is committed
+//   :values ctx: A context.Context to cancel the request
+//   :values number: The rollback number
+//   :values selective: true to only undo that one commit, false to undo it and all newer commits
+func (config *NsoJsonRpcConfig) LoadRollbackContext(ctx context.Context, number int, selective bool) (*NsoJsonResponse, error) {
+	trans, err := config.transaction()
+
+	if err != nil {
+		return nil, err
 	}
 
-	response, err := config.nsocon.sendPost(param)
+	response, err := trans.LoadRollbackContext(ctx, number, selective)
 
 	if err != nil {
 		return response, err
@@ -407,24 +1421,11 @@ func (config *NsoJsonRpcConfig) Load(data, path, dataFormat, mode string) (*req.
 	return response, nil
 }
 
-// Method to set a value
-//   :values path: A key path
-//   :values value: What you want to set
-//   :values dryRun: true for dryrun false for not
-func (config *NsoJsonRpcConfig) SetValue(path string, value interface{}, dryRun bool) (*req.Resp, error) {
-	param := req.Param{
-		"jsonrpc": "2.0",
-		"id":      config.nsocon.id,
-		"method":  "set_value",
-		"params": map[string]interface{}{
-			"th":     config.nsocon.th,
-			"path":   path,
-			"value":  value,
-			"dryrun": dryRun,
-		},
-	}
-
-	response, err := config.nsocon.sendPost(param)
+// Method to install a rollback file into the NSO Transaction
+// The changes are applied when the transaction is committed
+//   :values number: The rollback number
+func (config *NsoJsonRpcConfig) InstallRollback(number int) (*NsoJsonResponse, error) {
+	response, err := config.InstallRollbackContext(context.Background(), number)
 
 	if err != nil {
 		return response, err
@@ -433,20 +1434,18 @@ func (config *NsoJsonRpcConfig) SetValue(path string, value interface{}, dryRun
 	return response, nil
 }
 
-// Method to validate a commit
-//    In the CLI commits are validated automatically, in JsonRPC
-//    they are not, but only validated commits can be committed
-func (config *NsoJsonRpcConfig) ValidateCommit() (*req.Resp, error) {
-	param := req.Param{
-		"jsonrpc": "2.0",
-		"id":      config.nsocon.id,
-		"method":  "validate_commit",
-		"params": map[string]interface{}{
-			"th": config.nsocon.th,
-		},
+// Method to install a rollback file into the NSO Transaction using a context
+// The changes are applied when the transaction is committed
+//   :values ctx: A context.Context to cancel the request
+//   :values number: The rollback number
+func (config *NsoJsonRpcConfig) InstallRollbackContext(ctx context.Context, number int) (*NsoJsonResponse, error) {
+	trans, err := config.transaction()
+
+	if err != nil {
+		return nil, err
 	}
 
-	response, err := config.nsocon.sendPost(param)
+	response, err := trans.InstallRollbackContext(ctx, number)
 
 	if err != nil {
 		return response, err
@@ -455,33 +1454,29 @@ func (config *NsoJsonRpcConfig) ValidateCommit() (*req.Resp, error) {
 	return response, nil
 }
 
-// Method to commit
-//   :values dryRun: true for dryrun false for not
-//   :values output: cli, native, or xml
-//   :values reverse: true for reverse diff false for forward diff
-//                    config only can be used with native
-func (config *NsoJsonRpcConfig) Commit(dryRun bool, output string, reverse bool) (*req.Resp, error) {
-	var flags []string = nil
-
-	if dryRun == true {
-		flags = append(flags, fmt.Sprintf("dry-run=%s", output))
-		if output == "native" && reverse == true {
-			flags = append(flags, "dry-run-reverse")
-		}
+// Method to delete a path
+//   :values path: A key path
+func (config *NsoJsonRpcConfig) Delete(path string) (*NsoJsonResponse, error) {
+	response, err := config.DeleteContext(context.Background(), path)
 
+	if err != nil {
+		return response, err
 	}
 
-	param := req.Param{
-		"jsonrpc": "2.0",
-		"id":      config.nsocon.id,
-		"method":  "commit",
-		"params": map[string]interface{}{
-			"th":    config.nsocon.th,
-			"flags": flags,
-		},
+	return response, nil
+}
+
+// Method to delete a path using a context
+//   :values ctx: A context.Context to cancel the request
+//   :values path: A key path
+func (config *NsoJsonRpcConfig) DeleteContext(ctx context.Context, path string) (*NsoJsonResponse, error) {
+	trans, err := config.transaction()
+
+	if err != nil {
+		return nil, err
 	}
 
-	response, err := config.nsocon.sendPost(param)
+	response, err := trans.DeleteContext(ctx, path)
 
 	if err != nil {
 		return response, err
@@ -490,20 +1485,9 @@ func (config *NsoJsonRpcConfig) Commit(dryRun bool, output string, reverse bool)
 	return response, nil
 }
 
-// Method to delete a path
-//   :values path: A key path
-func (config *NsoJsonRpcConfig) Delete(path string) (*req.Resp, error) {
-	param := req.Param{
-		"jsonrpc": "2.0",
-		"id":      config.nsocon.id,
-		"method":  "delete",
-		"params": map[string]interface{}{
-			"th":   config.nsocon.th,
-			"path": path,
-		},
-	}
-
-	response, err := config.nsocon.sendPost(param)
+// Method to get all service points
+func (config *NsoJsonRpcConfig) GetServicePoints() (*NsoJsonResponse, error) {
+	response, err := config.GetServicePointsContext(context.Background())
 
 	if err != nil {
 		return response, err
@@ -512,15 +1496,15 @@ func (config *NsoJsonRpcConfig) Delete(path string) (*req.Resp, error) {
 	return response, nil
 }
 
-// Method to get all service points
-func (config *NsoJsonRpcConfig) GetServicePoints() (*req.Resp, error) {
-	param := req.Param{
+// Method to get all service points using a context
+//   :values ctx: A context.Context to cancel the request
+func (config *NsoJsonRpcConfig) GetServicePointsContext(ctx context.Context) (*NsoJsonResponse, error) {
+	param := rpcParam{
 		"jsonrpc": "2.0",
-		"id":      config.nsocon.id,
 		"method":  "get_service_points",
 	}
 
-	response, err := config.nsocon.sendPost(param)
+	response, err := config.nsocon.sendPostContext(ctx, param)
 
 	if err != nil {
 		return response, err
@@ -532,18 +1516,28 @@ func (config *NsoJsonRpcConfig) GetServicePoints() (*req.Resp, error) {
 // Method to get template variables
 // This is not xml template variables it is templates in NSO
 //   :values name: The name of the template
-func (config *NsoJsonRpcConfig) GetTemplateVariables(name string) (*req.Resp, error) {
-	param := req.Param{
-		"jsonrpc": "2.0",
-		"id":      config.nsocon.id,
-		"method":  "get_template_variables",
-		"params": map[string]interface{}{
-			"th":   config.nsocon.th,
-			"name": name,
-		},
+func (config *NsoJsonRpcConfig) GetTemplateVariables(name string) (*NsoJsonResponse, error) {
+	response, err := config.GetTemplateVariablesContext(context.Background(), name)
+
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+// Method to get template variables using a context
+// This is not xml template variables it is templates in NSO
+//   :values ctx: A context.Context to cancel the request
+//   :values name: The name of the template
+func (config *NsoJsonRpcConfig) GetTemplateVariablesContext(ctx context.Context, name string) (*NsoJsonResponse, error) {
+	trans, err := config.transaction()
+
+	if err != nil {
+		return nil, err
 	}
 
-	response, err := config.nsocon.sendPost(param)
+	response, err := trans.GetTemplateVariablesContext(ctx, name)
 
 	if err != nil {
 		return response, err
@@ -559,19 +1553,32 @@ func (config *NsoJsonRpcConfig) GetTemplateVariables(name string) (*req.Resp, er
 // run_query and stop_query instead.
 //   :values xpathExpression: A XPATH expression
 //   :values resultAs: string, keypath-value, or leaf_value_as_string
-func (config *NsoJsonRpcConfig) Query(xpathExpression, resultAs string) (*req.Resp, error) {
-	param := req.Param{
-		"jsonrpc": "2.0",
-		"id":      config.nsocon.id,
-		"method":  "query",
-		"params": map[string]interface{}{
-			"th":         config.nsocon.th,
-			"xpath_expr": xpathExpression,
-			"result_as":  resultAs,
-		},
+func (config *NsoJsonRpcConfig) Query(xpathExpression, resultAs string) (*NsoJsonResponse, error) {
+	response, err := config.QueryContext(context.Background(), xpathExpression, resultAs)
+
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+// Method for a basic Query in NSO using a context
+// This is a convenience method for calling
+// start_query, run_query and stop_query This method should not be used for paginated
+// results, as it results in performance degradation - use start_query, multiple
+// run_query and stop_query instead.
+//   :values ctx: A context.Context to cancel the request
+//   :values xpathExpression: A XPATH expression
+//   :values resultAs: string, keypath-value, or leaf_value_as_string
+func (config *NsoJsonRpcConfig) QueryContext(ctx context.Context, xpathExpression, resultAs string) (*NsoJsonResponse, error) {
+	trans, err := config.transaction()
+
+	if err != nil {
+		return nil, err
 	}
 
-	response, err := config.nsocon.sendPost(param)
+	response, err := trans.QueryContext(ctx, xpathExpression, resultAs)
 
 	if err != nil {
 		return response, err
@@ -621,67 +1628,57 @@ func NewQueryObject(xpathExpression, path string, selection []string, chunkSize,
 // Method to start a complex query
 //   :vaules QueryObject: A QueryObject
 func (config *NsoJsonRpcConfig) StartQuery(queryObject *QueryObject) error {
-	params := map[string]interface{}{
-		"th": config.nsocon.th,
+	err := config.StartQueryContext(context.Background(), queryObject)
+
+	if err != nil {
+		return err
 	}
-	if queryObject.xpathExpression != "" {
-		params["xpath_expr"] = queryObject.xpathExpression
-		if len(queryObject.selection) > 0 {
-			params["selection"] = queryObject.selection
-		}
 
-		if len(queryObject.sort) > 0 {
-			params["sort"] = queryObject.sort
-		}
+	return nil
+}
 
-	} else {
-		params["path"] = queryObject.path
-		if queryObject.contextNode != "" {
-			params["context_node"] = queryObject.contextNode
-		}
+// Method to start a complex query using a context
+//   :vaules QueryObject: A QueryObject
+//   :values ctx: A context.Context to cancel the request
+func (config *NsoJsonRpcConfig) StartQueryContext(ctx context.Context, queryObject *QueryObject) error {
+	trans, err := config.transaction()
 
-	}
-	params["chunk_size"] = queryObject.chunkSize
-	params["initial_offset"] = queryObject.initialOffset
-	if queryObject.sortOrder != "" {
-		params["sort_order"] = queryObject.sortOrder
+	if err != nil {
+		return err
 	}
 
-	params["include_total"] = queryObject.includeTotal
-	params["result_as"] = queryObject.resultAs
+	err = trans.StartQueryContext(ctx, queryObject)
 
-	param := req.Param{
-		"jsonrpc": "2.0",
-		"id":      config.nsocon.id,
-		"method":  "start_query",
-		"params":  params,
+	if err != nil {
+		return err
 	}
 
-	response, err := config.nsocon.sendPost(param)
+	return nil
+}
 
-	nsoResponse := NewNsoJsonResponse()
-	queryObject.qh = nsoResponse.GetQueryHandle(response)
+// Method to run a complex query
+//   :values queryHandle: A Query Handle this comes from using the StartQuery method
+func (config *NsoJsonRpcConfig) RunQuery(queryObject *QueryObject) (*NsoJsonResponse, error) {
+	response, err := config.RunQueryContext(context.Background(), queryObject)
 
 	if err != nil {
-		return err
+		return response, err
 	}
 
-	return nil
+	return response, nil
 }
 
-// Method to run a complex query
+// Method to run a complex query using a context
+//   :values ctx: A context.Context to cancel the request
 //   :values queryHandle: A Query Handle this comes from using the StartQuery method
-func (config *NsoJsonRpcConfig) RunQuery(queryObject *QueryObject) (*req.Resp, error) {
-	param := req.Param{
-		"jsonrpc": "2.0",
-		"id":      config.nsocon.id,
-		"method":  "run_query",
-		"params": map[string]interface{}{
-			"qh": queryObject.qh,
-		},
+func (config *NsoJsonRpcConfig) RunQueryContext(ctx context.Context, queryObject *QueryObject) (*NsoJsonResponse, error) {
+	trans, err := config.transaction()
+
+	if err != nil {
+		return nil, err
 	}
 
-	response, err := config.nsocon.sendPost(param)
+	response, err := trans.RunQueryContext(ctx, queryObject)
 
 	if err != nil {
 		return response, err
@@ -692,17 +1689,27 @@ func (config *NsoJsonRpcConfig) RunQuery(queryObject *QueryObject) (*req.Resp, e
 
 // Method to reset a complex query
 //   :values queryHandle: A Query Handle this comes from using the StartQuery method
-func (config *NsoJsonRpcConfig) ResetQuery(queryObject *QueryObject) (*req.Resp, error) {
-	param := req.Param{
-		"jsonrpc": "2.0",
-		"id":      config.nsocon.id,
-		"method":  "reset_query",
-		"params": map[string]interface{}{
-			"qh": queryObject.qh,
-		},
+func (config *NsoJsonRpcConfig) ResetQuery(queryObject *QueryObject) (*NsoJsonResponse, error) {
+	response, err := config.ResetQueryContext(context.Background(), queryObject)
+
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+// Method to reset a complex query using a context
+//   :values ctx: A context.Context to cancel the request
+//   :values queryHandle: A Query Handle this comes from using the StartQuery method
+func (config *NsoJsonRpcConfig) ResetQueryContext(ctx context.Context, queryObject *QueryObject) (*NsoJsonResponse, error) {
+	trans, err := config.transaction()
+
+	if err != nil {
+		return nil, err
 	}
 
-	response, err := config.nsocon.sendPost(param)
+	response, err := trans.ResetQueryContext(ctx, queryObject)
 
 	if err != nil {
 		return response, err
@@ -714,16 +1721,26 @@ func (config *NsoJsonRpcConfig) ResetQuery(queryObject *QueryObject) (*req.Resp,
 // Method to stop a complex query
 //   :values queryHandle: A Query Handle this comes from using the StartQuery method
 func (config *NsoJsonRpcConfig) StopQuery(queryObject *QueryObject) error {
-	param := req.Param{
-		"jsonrpc": "2.0",
-		"id":      config.nsocon.id,
-		"method":  "stop_query",
-		"params": map[string]interface{}{
-			"qh": queryObject.qh,
-		},
+	err := config.StopQueryContext(context.Background(), queryObject)
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Method to stop a complex query using a context
+//   :values ctx: A context.Context to cancel the request
+//   :values queryHandle: A Query Handle this comes from using the StartQuery method
+func (config *NsoJsonRpcConfig) StopQueryContext(ctx context.Context, queryObject *QueryObject) error {
+	trans, err := config.transaction()
+
+	if err != nil {
+		return err
 	}
 
-	_, err := config.nsocon.sendPost(param)
+	err = trans.StopQueryContext(ctx, queryObject)
 
 	if err != nil {
 		return err
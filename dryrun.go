@@ -0,0 +1,225 @@
+package nsojsonrpcrequestergo
+
+import (
+	"encoding/json"
+	"errors"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var (
+	// dryRunDeviceStart matches the start of a device in cli dry-run output
+	dryRunDeviceStart = regexp.MustCompile(`^([+\- ]*)(?:devices\s+)?device\s+(\S+?)\s*\{?\s*$`)
+	// dryRunDeviceClose matches the end of a device in cli dry-run output
+	dryRunDeviceClose = regexp.MustCompile(`^([+\- ]*)[}!]\s*$`)
+	// dryRunXMLDevice matches a device in xml dry-run output
+	dryRunXMLDevice = regexp.MustCompile(`(?s)<device>\s*<name>([^<]+)</name>.*?</device>`)
+)
+
+// DryRunResult holds the parsed result of a dry-run commit
+type DryRunResult struct {
+	// Format is cli, native, or xml
+	Format string
+	// LocalNode is the full local-node output, for native it is empty
+	LocalNode string
+	// Devices maps a device name to its diff, or to its native commands
+	Devices map[string]string
+}
+
+// dryRunNode holds a single node of a dry-run result
+type dryRunNode struct {
+	Name string `json:"name"`
+	Data string `json:"data"`
+}
+
+// Constructor to create a new DryRunResult from a dry-run commit response
+//   :values format: cli, native, or xml
+//   :values response: *NsoJsonResponse from a dry-run commit
+func NewDryRunResult(format string, response *NsoJsonResponse) (*DryRunResult, error) {
+	var body struct {
+		Result map[string]json.RawMessage `json:"result"`
+	}
+
+	err := response.ToJSON(&body)
+
+	if err != nil {
+		return &DryRunResult{}, err
+	}
+
+	raw, ok := body.Result["dry_run_result"]
+
+	if !ok {
+		return &DryRunResult{}, errors.New("could not find dry_run_result")
+	}
+
+	return parseDryRunResult(format, raw)
+
+}
+
+// parseDryRunResult parses the dry_run_result member of a commit result
+//   :values format: cli, native, or xml
+//   :values raw: The raw dry_run_result member
+func parseDryRunResult(format string, raw json.RawMessage) (*DryRunResult, error) {
+	result := &DryRunResult{Format: format, Devices: make(map[string]string)}
+
+	var members map[string]json.RawMessage
+
+	err := json.Unmarshal(raw, &members)
+
+	if err != nil {
+		return result, err
+	}
+
+	// Some NSO versions nest the output under the format name
+	if nested, ok := members[format]; ok {
+		members = nil
+		err = json.Unmarshal(nested, &members)
+
+		if err != nil {
+			return result, err
+		}
+	}
+
+	if format == "native" {
+		var devices []dryRunNode
+
+		err = json.Unmarshal(members["device"], &devices)
+
+		if err != nil {
+			return result, errors.New("could not find native device output")
+		}
+
+		for _, device := range devices {
+			result.Devices[device.Name] = device.Data
+		}
+
+		return result, nil
+	}
+
+	for name, member := range members {
+		var node dryRunNode
+
+		if json.Unmarshal(member, &node) != nil {
+			continue
+		}
+
+		if name == "local-node" {
+			result.LocalNode = node.Data
+			continue
+		}
+
+		// Remote LSA nodes are reported next to local-node
+		result.Devices[name] = node.Data
+	}
+
+	if format == "xml" {
+		for _, match := range dryRunXMLDevice.FindAllStringSubmatch(result.LocalNode, -1) {
+			result.Devices[match[1]] += match[0]
+		}
+
+	} else {
+		for name, diff := range splitCliDryRun(result.LocalNode) {
+			result.Devices[name] += diff
+		}
+
+	}
+
+	return result, nil
+
+}
+
+// splitCliDryRun splits cli dry-run output into a diff per device
+// both the curly brace and the ! terminated cli styles are understood
+//   :values data: The cli output of the local-node
+func splitCliDryRun(data string) map[string]string {
+	devices := make(map[string]string)
+
+	var current string
+	var indent int
+	var lines []string
+
+	flush := func() {
+		if current != "" {
+			devices[current] += strings.Join(lines, "\n") + "\n"
+		}
+		current = ""
+		lines = nil
+	}
+
+	for _, line := range strings.Split(data, "\n") {
+		if current != "" {
+			if match := dryRunDeviceClose.FindStringSubmatch(line); match != nil && len(match[1]) == indent {
+				lines = append(lines, line)
+				flush()
+				continue
+			}
+
+			// A line at or left of the device line ends a device without a closer
+			if strings.TrimSpace(line) == "" || lineIndent(line) > indent {
+				lines = append(lines, line)
+				continue
+			}
+
+			flush()
+		}
+
+		if match := dryRunDeviceStart.FindStringSubmatch(line); match != nil {
+			current = match[2]
+			indent = len(match[1])
+			lines = []string{line}
+		}
+
+	}
+
+	flush()
+
+	return devices
+
+}
+
+// lineIndent counts the leading diff markers and spaces of a line
+//   :values line: A line of cli output
+func lineIndent(line string) int {
+	return len(line) - len(strings.TrimLeft(line, "+- "))
+
+}
+
+// Method to get the names of all devices in the dry-run sorted by name
+func (r *DryRunResult) DeviceNames() []string {
+	names := make([]string, 0, len(r.Devices))
+
+	for name := range r.Devices {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+
+}
+
+// Method to get the devices in the dry-run that are not expected
+//   :values expected: The device names that are allowed to change
+func (r *DryRunResult) UnexpectedDevices(expected []string) []string {
+	var unexpected []string
+
+	allowed := make(map[string]bool)
+	for _, name := range expected {
+		allowed[name] = true
+	}
+
+	for _, name := range r.DeviceNames() {
+		if !allowed[name] {
+			unexpected = append(unexpected, name)
+		}
+	}
+
+	return unexpected
+
+}
+
+// Method to check if the dry-run has any changes
+func (r *DryRunResult) HasChanges() bool {
+	return strings.TrimSpace(r.LocalNode) != "" || len(r.Devices) > 0
+
+}
@@ -0,0 +1,80 @@
+package nsojsonrpcrequestergo
+
+import (
+	"fmt"
+	"testing"
+)
+
+func Test_parseDryRunResult(t *testing.T) {
+	scenarios := []struct {
+		format     string
+		raw        string
+		devices    []string
+		deviceDiff map[string]string
+	}{
+		{
+			format:  "cli",
+			raw:     `{"cli": {"local-node": {"data": " devices {\n     device ce0 {\n         config {\n+            ios:hostname ce0-new;\n         }\n     }\n     device ce1 {\n         config {\n-            ios:hostname ce1;\n         }\n     }\n }\n"}}}`,
+			devices: []string{"ce0", "ce1"},
+			deviceDiff: map[string]string{
+				"ce0": "     device ce0 {\n         config {\n+            ios:hostname ce0-new;\n         }\n     }\n",
+			},
+		},
+		{
+			format:  "cli",
+			raw:     `{"local-node": {"data": " devices device pe0\n  config\n+  ios:hostname pe0-new\n  !\n !\n"}}`,
+			devices: []string{"pe0"},
+		},
+		{
+			format:  "native",
+			raw:     `{"native": {"device": [{"name": "ce0", "data": "hostname ce0-new\n"}, {"name": "ce1", "data": "no hostname\n"}]}}`,
+			devices: []string{"ce0", "ce1"},
+			deviceDiff: map[string]string{
+				"ce1": "no hostname\n",
+			},
+		},
+		{
+			format:  "xml",
+			raw:     `{"xml": {"local-node": {"data": "<devices xmlns=\"http://tail-f.com/ns/ncs\">\n<device>\n<name>ce0</name>\n<config/>\n</device>\n</devices>\n"}}}`,
+			devices: []string{"ce0"},
+		},
+		{
+			format:  "cli",
+			raw:     `{"cli": {"local-node": {"data": ""}}}`,
+			devices: []string{},
+		},
+	}
+
+	for _, scenario := range scenarios {
+		result, err := parseDryRunResult(scenario.format, []byte(scenario.raw))
+		if err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+
+		if fmt.Sprint(result.DeviceNames()) != fmt.Sprint(scenario.devices) {
+			t.Errorf("expected %v got %v", scenario.devices, result.DeviceNames())
+		}
+
+		for name, diff := range scenario.deviceDiff {
+			if result.Devices[name] != diff {
+				t.Errorf("expected %q got %q", diff, result.Devices[name])
+			}
+		}
+
+		if result.HasChanges() != (len(scenario.devices) > 0) {
+			t.Errorf("expected %v got %v", len(scenario.devices) > 0, result.HasChanges())
+		}
+
+	}
+
+}
+
+func TestDryRunResult_UnexpectedDevices(t *testing.T) {
+	result := &DryRunResult{Devices: map[string]string{"ce0": "", "ce1": "", "pe0": ""}}
+
+	unexpected := result.UnexpectedDevices([]string{"ce0", "ce1"})
+	if fmt.Sprint(unexpected) != "[pe0]" {
+		t.Errorf("expected %v got %v", "[pe0]", unexpected)
+	}
+
+}
@@ -0,0 +1,177 @@
+package nsojsonrpcrequestergo
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Sentinel errors for the common NSO JSON-RPC error types
+// use errors.Is to check a returned error against these
+var (
+	ErrInvalidSession   = errors.New("nso session is invalid")
+	ErrLockConflict     = errors.New("nso lock conflict")
+	ErrValidationFailed = errors.New("nso validation failed")
+	ErrAccessDenied     = errors.New("nso access denied")
+)
+
+// nsoRpcErrorTypes maps the sentinel errors to the NSO error types they represent
+var nsoRpcErrorTypes = map[error][]string{
+	ErrInvalidSession:   {"session.invalid_sessionid", "session.missing_sessionid"},
+	ErrLockConflict:     {"db.locked", "trans.locked", "rpc.method.lock_error"},
+	ErrValidationFailed: {"trans.validation_failed", "data.validation_failed", "rpc.method.validation_failed"},
+	ErrAccessDenied:     {"rpc.method.denied", "data.access_denied", "session.access_denied"},
+}
+
+// HTTPStatusError is returned when the NSO Server answers with a non 2xx HTTP status
+type HTTPStatusError struct {
+	StatusCode int
+	Method     string
+}
+
+// Method to get the error as a string
+func (e *HTTPStatusError) Error() string {
+	return fmt.Sprintf("nso server returned HTTP status %d for %s", e.StatusCode, e.Method)
+
+}
+
+// transportError wraps an error from a Transport so it can be told apart from a NSO error
+type transportError struct {
+	err error
+}
+
+// Method to get the error as a string
+func (e *transportError) Error() string {
+	return e.err.Error()
+
+}
+
+// Method to get the wrapped error
+func (e *transportError) Unwrap() error {
+	return e.err
+
+}
+
+// NsoRpcErrorEntry holds a single entry of the data.errors member of a NSO JSON-RPC error
+type NsoRpcErrorEntry struct {
+	Reason string   `json:"reason"`
+	Path   string   `json:"path"`
+	Paths  []string `json:"paths"`
+}
+
+// NsoRpcError holds the error member of a NSO JSON-RPC response
+type NsoRpcError struct {
+	Code     int                    `json:"code"`
+	Type     string                 `json:"type"`
+	Message  string                 `json:"message"`
+	Internal string                 `json:"internal"`
+	Data     map[string]interface{} `json:"-"`
+	Errors   []NsoRpcErrorEntry     `json:"-"`
+	Method   string                 `json:"-"`
+	ID       int                    `json:"-"`
+}
+
+// newNsoRpcError decodes the error member of a NSO JSON-RPC response
+//   :values method: The JSON-RPC method that was called
+//   :values id: The JSON-RPC id of the request
+//   :values raw: The raw error member
+func newNsoRpcError(method string, id int, raw json.RawMessage) (*NsoRpcError, error) {
+	var data struct {
+		Data json.RawMessage `json:"data"`
+	}
+
+	rpcErr := &NsoRpcError{Method: method, ID: id}
+
+	err := json.Unmarshal(raw, rpcErr)
+
+	if err != nil {
+		return rpcErr, err
+	}
+
+	err = json.Unmarshal(raw, &data)
+
+	if err != nil {
+		return rpcErr, err
+	}
+
+	if len(data.Data) == 0 || string(data.Data) == "null" {
+		return rpcErr, nil
+	}
+
+	// data is normally an object, but not all NSO versions agree so ignore anything else
+	var dataMap map[string]interface{}
+	if json.Unmarshal(data.Data, &dataMap) != nil {
+		return rpcErr, nil
+	}
+
+	rpcErr.Data = dataMap
+
+	var dataErrors struct {
+		Errors []NsoRpcErrorEntry `json:"errors"`
+	}
+	if json.Unmarshal(data.Data, &dataErrors) == nil {
+		rpcErr.Errors = dataErrors.Errors
+	}
+
+	return rpcErr, nil
+
+}
+
+// Method to get the error as a string
+func (e *NsoRpcError) Error() string {
+	var sb strings.Builder
+
+	sb.WriteString("nso json-rpc error")
+
+	if e.Method != "" {
+		sb.WriteString(fmt.Sprintf(" in %s", e.Method))
+	}
+
+	sb.WriteString(fmt.Sprintf(": %s: %s (code %d)", e.Type, e.Message, e.Code))
+
+	for _, entry := range e.Errors {
+		if entry.Path != "" {
+			sb.WriteString(fmt.Sprintf("; %s at %s", entry.Reason, entry.Path))
+		} else {
+			sb.WriteString(fmt.Sprintf("; %s", entry.Reason))
+		}
+	}
+
+	return sb.String()
+
+}
+
+// Method to check if the error matches one of the sentinel errors
+//   :values target: A sentinel error like ErrInvalidSession
+func (e *NsoRpcError) Is(target error) bool {
+	types, ok := nsoRpcErrorTypes[target]
+
+	if !ok {
+		return false
+	}
+
+	for _, errType := range types {
+		if e.Type == errType {
+			return true
+		}
+	}
+
+	return false
+
+}
+
+// Method to get all keypaths referenced by the error
+func (e *NsoRpcError) Keypaths() []string {
+	var keypaths []string
+
+	for _, entry := range e.Errors {
+		if entry.Path != "" {
+			keypaths = append(keypaths, entry.Path)
+		}
+		keypaths = append(keypaths, entry.Paths...)
+	}
+
+	return keypaths
+
+}
@@ -0,0 +1,76 @@
+package nsojsonrpcrequestergo
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func Test_newNsoRpcError(t *testing.T) {
+	scenarios := []struct {
+		raw      string
+		errType  string
+		code     int
+		keypaths []string
+		sentinel error
+	}{
+		{raw: `{"code": -32000, "type": "session.invalid_sessionid", "message": "Invalid sessionid"}`, errType: "session.invalid_sessionid", code: -32000, sentinel: ErrInvalidSession},
+		{raw: `{"code": -32000, "type": "db.locked", "message": "Database is locked"}`, errType: "db.locked", code: -32000, sentinel: ErrLockConflict},
+		{raw: `{"code": -32000, "type": "rpc.method.denied", "message": "Method denied"}`, errType: "rpc.method.denied", code: -32000, sentinel: ErrAccessDenied},
+		{raw: `{"code": -32000, "type": "rpc.method.failed", "message": "Method failed", "data": {"errors": [{"reason": "bad value", "path": "/ncs:devices/device{ce0}/port"}]}}`, errType: "rpc.method.failed", code: -32000, keypaths: []string{"/ncs:devices/device{ce0}/port"}},
+		{raw: `{"code": -32602, "type": "rpc.method.invalid_params", "message": "Invalid parameters", "data": {"param": "th"}}`, errType: "rpc.method.invalid_params", code: -32602},
+	}
+
+	for _, scenario := range scenarios {
+		rpcErr, err := newNsoRpcError("test", 1, []byte(scenario.raw))
+		if err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+
+		if rpcErr.Type != scenario.errType {
+			t.Errorf("expected %v got %v", scenario.errType, rpcErr.Type)
+		}
+		if rpcErr.Code != scenario.code {
+			t.Errorf("expected %v got %v", scenario.code, rpcErr.Code)
+		}
+		if fmt.Sprint(rpcErr.Keypaths()) != fmt.Sprint(scenario.keypaths) {
+			t.Errorf("expected %v got %v", scenario.keypaths, rpcErr.Keypaths())
+		}
+		if scenario.sentinel != nil && !errors.Is(rpcErr, scenario.sentinel) {
+			t.Errorf("expected %v to match %v", rpcErr, scenario.sentinel)
+		}
+		if scenario.sentinel == nil && errors.Is(rpcErr, ErrInvalidSession) {
+			t.Errorf("did not expect %v to match %v", rpcErr, ErrInvalidSession)
+		}
+
+	}
+
+}
+
+func Test_nsoJsonConnection_sendPostRpcError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"jsonrpc": "2.0", "id": 1, "error": {"code": -32000, "type": "session.invalid_sessionid", "message": "Invalid sessionid"}}`))
+	}))
+	defer server.Close()
+
+	nsoJson, err := newNsoJsonConnection("http", "127.0.0.1", testServerPort(server), "admin", "admin", false)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	err = nsoJson.NsoLogin()
+
+	var rpcErr *NsoRpcError
+	if !errors.As(err, &rpcErr) {
+		t.Fatalf("expected a *NsoRpcError got %v", err)
+	}
+	if rpcErr.Method != "login" {
+		t.Errorf("expected %v got %v", "login", rpcErr.Method)
+	}
+	if !errors.Is(err, ErrInvalidSession) {
+		t.Errorf("expected %v to match %v", err, ErrInvalidSession)
+	}
+
+}
@@ -1,5 +1,3 @@
 module github.com/btr1975/nsojsonrpcrequestergo
 
 go 1.15
-
-require github.com/imroc/req v0.3.0
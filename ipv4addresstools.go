@@ -11,7 +11,7 @@ func IpV4Address(address string) (string, error) {
 	// Convert ip to a IPv4 Address if possible
 	ipv4address := net.ParseIP(address)
 
-	if ipv4address == nil {
+	if ipv4address == nil || ipv4address.To4() == nil {
 		return address, errors.New("not a valid IPv4 address")
 
 	} else {
@@ -62,3 +62,18 @@ func IpV4MulticastAddress(address string) (string, error) {
 	return address, nil
 
 }
+
+// IpV4Prefix verifies if a given string is a IPv4 prefix in CIDR notation
+// the network address of the prefix is returned
+//   :values prefix: The prefix to verify like 192.168.1.0/24
+func IpV4Prefix(prefix string) (string, error) {
+	ip, network, err := net.ParseCIDR(prefix)
+
+	if err != nil || ip.To4() == nil {
+		return prefix, errors.New("not a valid IPv4 prefix")
+
+	}
+
+	return network.String(), nil
+
+}
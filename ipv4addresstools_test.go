@@ -13,6 +13,7 @@ func TestIpV4Address(t *testing.T) {
 	}{
 		{input: "192.168.1.1", expect: "192.168.1.1", rcvError: nil},
 		{input: "192.168.1.0501", expect: "192.168.1.0501", rcvError: errors.New("not a valid IPv4 address")},
+		{input: "2001:db8::1", expect: "2001:db8::1", rcvError: errors.New("not a valid IPv4 address")},
 	}
 
 	for _, scenario := range scenarios {
@@ -85,3 +86,31 @@ func TestIpV4MulticastAddress(t *testing.T) {
 
 	}
 }
+
+func TestIpV4Prefix(t *testing.T) {
+	scenarios := []struct {
+		input    string
+		expect   string
+		rcvError error
+	}{
+		{input: "192.168.1.0/24", expect: "192.168.1.0/24", rcvError: nil},
+		{input: "192.168.1.10/24", expect: "192.168.1.0/24", rcvError: nil},
+		{input: "192.168.1.0/33", expect: "192.168.1.0/33", rcvError: errors.New("not a valid IPv4 prefix")},
+		{input: "2001:db8::/32", expect: "2001:db8::/32", rcvError: errors.New("not a valid IPv4 prefix")},
+	}
+
+	for _, scenario := range scenarios {
+		value, err := IpV4Prefix(scenario.input)
+		if value != scenario.expect {
+			t.Errorf("expected %v got %v", scenario.expect, value)
+		}
+
+		if err != scenario.rcvError {
+			if err.Error() != scenario.rcvError.Error() {
+				t.Errorf("expected error %v got %v", scenario.rcvError, err)
+			}
+
+		}
+
+	}
+}
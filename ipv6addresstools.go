@@ -0,0 +1,80 @@
+package nsojsonrpcrequestergo
+
+import (
+	"errors"
+	"net"
+	"strings"
+)
+
+// IpV6Address verifies if a given string a IPv6 Address
+//   :values address: The address to verify
+func IpV6Address(address string) (string, error) {
+	// Convert ip to a IPv6 Address if possible
+	ipv6address := net.ParseIP(address)
+
+	if ipv6address == nil || !strings.Contains(address, ":") {
+		return address, errors.New("not a valid IPv6 address")
+
+	} else {
+
+		return ipv6address.String(), nil
+	}
+
+}
+
+// IpV6UnicastAddress verifies if a net.IP is a IPv6 Unicast address
+//   :values address: The address to verify
+func IpV6UnicastAddress(address string) (string, error) {
+	_, err := IpV6Address(address)
+
+	if err != nil {
+		return address, err
+
+	}
+
+	isUcast := net.IP.IsGlobalUnicast(net.ParseIP(address))
+
+	if isUcast != true {
+		return address, errors.New("not a valid IPv6 unicast address")
+
+	}
+
+	return address, nil
+
+}
+
+// IpV6MulticastAddress verifies if a net.IP is a IPv6 Multicast address
+//   :values address: The address to verify
+func IpV6MulticastAddress(address string) (string, error) {
+	_, err := IpV6Address(address)
+
+	if err != nil {
+		return address, err
+
+	}
+
+	isMcast := net.IP.IsMulticast(net.ParseIP(address))
+
+	if isMcast != true {
+		return address, errors.New("not a valid IPv6 multicast address")
+
+	}
+
+	return address, nil
+
+}
+
+// IpV6Prefix verifies if a given string is a IPv6 prefix in CIDR notation
+// the network address of the prefix is returned
+//   :values prefix: The prefix to verify like 2001:db8::/32
+func IpV6Prefix(prefix string) (string, error) {
+	_, network, err := net.ParseCIDR(prefix)
+
+	if err != nil || !strings.Contains(prefix, ":") {
+		return prefix, errors.New("not a valid IPv6 prefix")
+
+	}
+
+	return network.String(), nil
+
+}
@@ -0,0 +1,118 @@
+package nsojsonrpcrequestergo
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestIpV6Address(t *testing.T) {
+	scenarios := []struct {
+		input    string
+		expect   string
+		rcvError error
+	}{
+		{input: "2001:DB8::1", expect: "2001:db8::1", rcvError: nil},
+		{input: "2001:db8::zz", expect: "2001:db8::zz", rcvError: errors.New("not a valid IPv6 address")},
+		{input: "192.168.1.1", expect: "192.168.1.1", rcvError: errors.New("not a valid IPv6 address")},
+	}
+
+	for _, scenario := range scenarios {
+		value, err := IpV6Address(scenario.input)
+		if value != scenario.expect {
+			t.Errorf("expected %v got %v", scenario.expect, value)
+		}
+
+		if err != scenario.rcvError {
+			if err.Error() != scenario.rcvError.Error() {
+				t.Errorf("expected error %v got %v", scenario.rcvError, err)
+			}
+
+		}
+
+	}
+
+}
+
+func TestIpV6UnicastAddress(t *testing.T) {
+	scenarios := []struct {
+		input    string
+		expect   string
+		rcvError error
+	}{
+		{input: "2001:db8::1", expect: "2001:db8::1", rcvError: nil},
+		{input: "2001:db8::zz", expect: "2001:db8::zz", rcvError: errors.New("not a valid IPv6 address")},
+		{input: "ff02::1", expect: "ff02::1", rcvError: errors.New("not a valid IPv6 unicast address")},
+	}
+
+	for _, scenario := range scenarios {
+		value, err := IpV6UnicastAddress(scenario.input)
+		if value != scenario.expect {
+			t.Errorf("expected %v got %v", scenario.expect, value)
+		}
+
+		if err != scenario.rcvError {
+			if err.Error() != scenario.rcvError.Error() {
+				t.Errorf("expected error %v got %v", scenario.rcvError, err)
+			}
+
+		}
+
+	}
+
+}
+
+func TestIpV6MulticastAddress(t *testing.T) {
+	scenarios := []struct {
+		input    string
+		expect   string
+		rcvError error
+	}{
+		{input: "ff02::1", expect: "ff02::1", rcvError: nil},
+		{input: "2001:db8::1", expect: "2001:db8::1", rcvError: errors.New("not a valid IPv6 multicast address")},
+	}
+
+	for _, scenario := range scenarios {
+		value, err := IpV6MulticastAddress(scenario.input)
+		if value != scenario.expect {
+			t.Errorf("expected %v got %v", scenario.expect, value)
+		}
+
+		if err != scenario.rcvError {
+			if err.Error() != scenario.rcvError.Error() {
+				t.Errorf("expected error %v got %v", scenario.rcvError, err)
+			}
+
+		}
+
+	}
+
+}
+
+func TestIpV6Prefix(t *testing.T) {
+	scenarios := []struct {
+		input    string
+		expect   string
+		rcvError error
+	}{
+		{input: "2001:db8::/32", expect: "2001:db8::/32", rcvError: nil},
+		{input: "2001:db8::1/64", expect: "2001:db8::/64", rcvError: nil},
+		{input: "2001:db8::/129", expect: "2001:db8::/129", rcvError: errors.New("not a valid IPv6 prefix")},
+		{input: "192.168.1.0/24", expect: "192.168.1.0/24", rcvError: errors.New("not a valid IPv6 prefix")},
+	}
+
+	for _, scenario := range scenarios {
+		value, err := IpV6Prefix(scenario.input)
+		if value != scenario.expect {
+			t.Errorf("expected %v got %v", scenario.expect, value)
+		}
+
+		if err != scenario.rcvError {
+			if err.Error() != scenario.rcvError.Error() {
+				t.Errorf("expected error %v got %v", scenario.rcvError, err)
+			}
+
+		}
+
+	}
+
+}
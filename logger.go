@@ -0,0 +1,207 @@
+package nsojsonrpcrequestergo
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+)
+
+// Logger is the logging interface used by the library
+// the methods match a *slog.Logger so one can be passed in directly
+// args are key value pairs
+type Logger interface {
+	Debug(msg string, args ...interface{})
+	Info(msg string, args ...interface{})
+	Warn(msg string, args ...interface{})
+	Error(msg string, args ...interface{})
+}
+
+// noopLogger is the Logger used when none is given
+type noopLogger struct{}
+
+func (noopLogger) Debug(msg string, args ...interface{}) {}
+
+func (noopLogger) Info(msg string, args ...interface{}) {}
+
+func (noopLogger) Warn(msg string, args ...interface{}) {}
+
+func (noopLogger) Error(msg string, args ...interface{}) {}
+
+// redacted replaces secrets in a wire dump
+const redacted = "*****"
+
+// secretNames are parts of a JSON member name, or of a keypath leaf, that hold a secret
+var secretNames = []string{"passwd", "password", "secret", "token", "private-key", "private_key"}
+
+// Method to set the Logger, nil turns logging off
+//   :values logger: A Logger, a *slog.Logger works
+func (nsoJson *nsoJsonConnection) SetLogger(logger Logger) {
+	if logger == nil {
+		logger = noopLogger{}
+	}
+
+	nsoJson.logger = logger
+
+}
+
+// Method to set if the full request and response bodies are logged at debug level
+// passwords and other secrets are redacted
+//   :values enabled: true to log the bodies false to not
+func (nsoJson *nsoJsonConnection) SetWireDump(enabled bool) {
+	nsoJson.wireDump = enabled
+
+}
+
+// Method to log a finished request
+//   :values method: The JSON-RPC method
+//   :values id: The JSON-RPC id
+//   :values duration: How long the request took
+//   :values statusCode: The HTTP status code
+//   :values err: The error the request failed with or nil
+func (nsoJson *nsoJsonConnection) logRequest(method string, id int, duration time.Duration, statusCode int, err error) {
+	nsoJson.logRequestArgs([]interface{}{"method", method, "id", id}, duration, statusCode, err)
+
+}
+
+// Method to log a finished request named by log members
+//   :values request: The members that name the request, like method and id
+//   :values duration: How long the request took
+//   :values statusCode: The HTTP status code
+//   :values err: The error the request failed with or nil
+func (nsoJson *nsoJsonConnection) logRequestArgs(request []interface{}, duration time.Duration, statusCode int, err error) {
+	args := append(append([]interface{}{}, request...), "duration", duration, "status", statusCode)
+
+	if err == nil {
+		nsoJson.logger.Debug("nso json-rpc request", args...)
+		return
+	}
+
+	var rpcErr *NsoRpcError
+	if errors.As(err, &rpcErr) {
+		args = append(args, "rpc_error_type", rpcErr.Type, "rpc_error_code", rpcErr.Code)
+	}
+
+	args = append(args, "error", err)
+
+	nsoJson.logger.Warn("nso json-rpc request failed", args...)
+
+}
+
+// Method to log a request or response body when the wire dump is on
+//   :values direction: request, or response
+//   :values method: The JSON-RPC method
+//   :values id: The JSON-RPC id
+//   :values body: The raw body
+//   :values secretValues: true to redact value members, for requests on a secret keypath
+func (nsoJson *nsoJsonConnection) dumpWire(direction string, method string, id int, body []byte, secretValues bool) {
+	nsoJson.dumpWireArgs(direction, []interface{}{"method", method, "id", id}, body, secretValues)
+
+}
+
+// Method to log a request or response body named by log members when the wire dump is on
+//   :values direction: request, or response
+//   :values request: The members that name the request, like method and id
+//   :values body: The raw body
+//   :values secretValues: true to redact value members, for requests on a secret keypath
+func (nsoJson *nsoJsonConnection) dumpWireArgs(direction string, request []interface{}, body []byte, secretValues bool) {
+	if nsoJson.wireDump != true {
+		return
+	}
+
+	args := append(append([]interface{}{}, request...), "body", redactBody(body, secretValues))
+
+	nsoJson.logger.Debug("nso json-rpc wire "+direction, args...)
+
+}
+
+// isSecretName checks if a JSON member name, or a keypath, names a secret
+//   :values name: A member name or a keypath
+func isSecretName(name string) bool {
+	// Only the leaf of a keypath matters
+	if i := strings.LastIndex(name, "/"); i > -1 {
+		name = name[i+1:]
+	}
+
+	name = strings.ToLower(name)
+
+	for _, secret := range secretNames {
+		if strings.Contains(name, secret) {
+			return true
+		}
+	}
+
+	return false
+
+}
+
+// isSecretParam checks if a request reads or writes a secret keypath
+//   :values param: A rpcParam
+func isSecretParam(param rpcParam) bool {
+	params, ok := param["params"].(map[string]interface{})
+
+	if !ok {
+		return false
+	}
+
+	path, _ := params["path"].(string)
+
+	return path != "" && isSecretName(path)
+
+}
+
+// redactBody redacts secrets in a JSON body, a body that is not JSON is returned as it is
+//   :values body: The raw body
+//   :values secretValues: true to redact value members
+func redactBody(body []byte, secretValues bool) string {
+	var data interface{}
+
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+
+	if decoder.Decode(&data) != nil {
+		return string(body)
+	}
+
+	redactedBody, err := json.Marshal(redactValue(data, secretValues))
+
+	if err != nil {
+		return string(body)
+	}
+
+	return string(redactedBody)
+
+}
+
+// redactValue walks decoded JSON and replaces secret members
+//   :values data: The decoded JSON
+//   :values secretValues: true to redact value members
+func redactValue(data interface{}, secretValues bool) interface{} {
+	switch value := data.(type) {
+	case map[string]interface{}:
+		// A path to a secret leaf makes its value a secret
+		path, _ := value["path"].(string)
+		secretPath := path != "" && isSecretName(path)
+
+		for key, member := range value {
+			if isSecretName(key) || ((secretValues || secretPath) && key == "value") {
+				value[key] = redacted
+				continue
+			}
+			value[key] = redactValue(member, secretValues)
+		}
+
+		return value
+
+	case []interface{}:
+		for i, member := range value {
+			value[i] = redactValue(member, secretValues)
+		}
+
+		return value
+	}
+
+	return data
+
+}
@@ -0,0 +1,127 @@
+package nsojsonrpcrequestergo
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// testLogEntry holds one recorded log call
+type testLogEntry struct {
+	level, msg string
+	args       map[string]interface{}
+}
+
+// testRecordLogger records every log call with its args
+type testRecordLogger struct {
+	lock    sync.Mutex
+	entries []testLogEntry
+}
+
+func (l *testRecordLogger) record(level string, msg string, args []interface{}) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	entry := testLogEntry{level: level, msg: msg, args: make(map[string]interface{})}
+	for i := 0; i+1 < len(args); i += 2 {
+		entry.args[args[i].(string)] = args[i+1]
+	}
+	l.entries = append(l.entries, entry)
+}
+
+func (l *testRecordLogger) Debug(msg string, args ...interface{}) { l.record("debug", msg, args) }
+
+func (l *testRecordLogger) Info(msg string, args ...interface{}) { l.record("info", msg, args) }
+
+func (l *testRecordLogger) Warn(msg string, args ...interface{}) { l.record("warn", msg, args) }
+
+func (l *testRecordLogger) Error(msg string, args ...interface{}) { l.record("error", msg, args) }
+
+func Test_SetWireDump(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+
+		switch body["method"] {
+		case "get_value":
+			_, _ = w.Write([]byte(fmt.Sprintf(`{"jsonrpc": "2.0", "id": %v, "result": {"value": "$9$hidden"}}`, body["id"])))
+		case "commit":
+			_, _ = w.Write([]byte(fmt.Sprintf(`{"jsonrpc": "2.0", "id": %v, "error": {"code": -32000, "type": "trans.validation_failed", "message": "failed"}}`, body["id"])))
+		default:
+			_, _ = w.Write([]byte(fmt.Sprintf(`{"jsonrpc": "2.0", "id": %v, "result": {"th": 1}}`, body["id"])))
+		}
+	}))
+	defer server.Close()
+
+	logger := &testRecordLogger{}
+
+	config, _ := NewNsoJsonRpcConfig("http", "127.0.0.1", testServerPort(server), "admin", "Sup3rS3cret", false)
+	config.SetLogger(logger)
+	config.SetWireDump(true)
+
+	_ = config.NsoLogin()
+	trans, _ := config.NewTransaction("read_write", "private", "", "reuse")
+	_, _ = trans.SetValue("/ncs:devices/authgroups/group{default}/default-map/remote-password", "DevicePass", false)
+	_, _ = trans.GetValue("/ncs:devices/authgroups/group{default}/default-map/remote-password", false)
+	_, _ = trans.Commit(false, "", false)
+
+	var dumps, requests, failed int
+
+	for _, entry := range logger.entries {
+		switch entry.msg {
+		case "nso json-rpc wire request", "nso json-rpc wire response":
+			dumps++
+			body := entry.args["body"].(string)
+			for _, secret := range []string{"Sup3rS3cret", "DevicePass", "$9$hidden"} {
+				if strings.Contains(body, secret) {
+					t.Errorf("expected %v to be redacted in %v", secret, body)
+				}
+			}
+		case "nso json-rpc request failed":
+			failed++
+			if entry.args["rpc_error_type"] != "trans.validation_failed" {
+				t.Errorf("expected %v got %v", "trans.validation_failed", entry.args["rpc_error_type"])
+			}
+			if entry.args["status"] != 200 {
+				t.Errorf("expected %v got %v", 200, entry.args["status"])
+			}
+		case "nso json-rpc request":
+			requests++
+			if _, ok := entry.args["duration"]; !ok {
+				t.Errorf("expected a duration for %v", entry.args["method"])
+			}
+		}
+	}
+
+	if dumps != 10 || requests != 4 || failed != 1 {
+		t.Errorf("expected %v %v %v got %v %v %v", 10, 4, 1, dumps, requests, failed)
+	}
+
+}
+
+func Test_redactBody(t *testing.T) {
+	scenarios := []struct {
+		body         string
+		secretValues bool
+		expect       string
+	}{
+		{body: `{"params":{"passwd":"pw","user":"admin"}}`, expect: `{"params":{"passwd":"*****","user":"admin"}}`},
+		{body: `{"params":{"path":"/a/b/secret-key","value":"x"}}`, expect: `{"params":{"path":"/a/b/secret-key","value":"*****"}}`},
+		{body: `{"params":{"path":"/a/b/name","value":"x"}}`, expect: `{"params":{"path":"/a/b/name","value":"x"}}`},
+		{body: `{"result":{"value":"x","size":1.50}}`, secretValues: true, expect: `{"result":{"size":1.50,"value":"*****"}}`},
+		{body: `{"list":[{"Token":"t"}]}`, expect: `{"list":[{"Token":"*****"}]}`},
+		{body: `not json`, expect: `not json`},
+	}
+
+	for _, scenario := range scenarios {
+		rcv := redactBody([]byte(scenario.body), scenario.secretValues)
+		if rcv != scenario.expect {
+			t.Errorf("expected %v got %v", scenario.expect, rcv)
+		}
+	}
+
+}
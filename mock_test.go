@@ -0,0 +1,182 @@
+package nsojsonrpcrequestergo
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/btr1975/nsojsonrpcrequestergo/nsomock"
+)
+
+func Test_nsomock_Commit(t *testing.T) {
+	server := nsomock.NewServer()
+	defer server.Close()
+
+	server.SetValue("/ncs:devices/device{r1}/config/ios:hostname", "r1")
+	server.SetValue("/ncs:devices/device{r2}/config/ios:hostname", "r2")
+
+	config, _ := NewNsoJsonRpcConfig("http", server.Host(), server.Port(), "admin", "admin", false)
+
+	err := config.NsoLogin()
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	trans, _ := config.NewTransaction("read_write", "private", "", "reuse")
+	_, _ = trans.SetValue("/ncs:devices/device{r1}/config/ios:hostname", "r1-new", false)
+
+	modified, _ := trans.IsModified()
+	if modified != true {
+		t.Errorf("expected %v got %v", true, modified)
+	}
+
+	for _, format := range []string{"cli", "native", ""} {
+		result, err := trans.DryRun(format, false)
+		if err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+
+		if !reflect.DeepEqual(result.DeviceNames(), []string{"r1"}) {
+			t.Errorf("expected %v got %v", []string{"r1"}, result.DeviceNames())
+		}
+	}
+
+	// A dry run does not change running
+	value, _ := server.Value("/ncs:devices/device{r1}/config/ios:hostname")
+	if value == "r1-new" {
+		t.Errorf("expected %v got %v", "no change", value)
+	}
+
+	_, err = trans.Commit(false, "", false)
+	if err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	value, _ = server.Value("/ncs:devices/device{r1}/config/ios:hostname")
+	if value != "r1-new" {
+		t.Errorf("expected %v got %v", "r1-new", value)
+	}
+
+	server.FailNext("commit", "trans.validation_failed")
+	_, _ = trans.SetValue("/ncs:devices/device{r2}/config/ios:hostname", "r2-new", false)
+
+	_, err = trans.Commit(false, "", false)
+	if !errors.Is(err, ErrValidationFailed) {
+		t.Errorf("expected error %v got %v", ErrValidationFailed, err)
+	}
+
+	_ = config.NsoLogout()
+
+}
+
+func Test_nsomock_Query(t *testing.T) {
+	server := nsomock.NewServer()
+	defer server.Close()
+
+	server.SetValue("/ncs:devices/device{r1}/address", "10.0.0.1")
+	server.SetValue("/ncs:devices/device{r2}/address", "10.0.0.2")
+	server.SetValue("/ncs:devices/device{r3}/address", "10.0.0.3")
+
+	config, _ := NewNsoJsonRpcConfig("http", server.Host(), server.Port(), "admin", "admin", false)
+	_ = config.NsoLogin()
+	trans, _ := config.NewTransaction("read", "private", "", "reuse")
+
+	response, err := trans.Query("/ncs:devices/device[name='r2']", "string")
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	results, _ := response.GetQueryResults()
+	if !reflect.DeepEqual(results, []string{"/devices/device{r2}"}) {
+		t.Errorf("expected %v got %v", []string{"/devices/device{r2}"}, results)
+	}
+
+	queryObject, _ := NewQueryObject("/ncs:devices/device", "", []string{"name", "address"}, 2, 0, nil, "", true, "", "string")
+
+	err = trans.StartQuery(queryObject)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	scenarios := []struct {
+		expect []string
+	}{
+		{expect: []string{"r1 10.0.0.1", "r2 10.0.0.2"}},
+		{expect: []string{"r3 10.0.0.3"}},
+	}
+
+	for _, scenario := range scenarios {
+		response, err = trans.RunQuery(queryObject)
+		if err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+
+		results, _ = response.GetQueryResults()
+		if !reflect.DeepEqual(results, scenario.expect) {
+			t.Errorf("expected %v got %v", scenario.expect, results)
+		}
+	}
+
+	err = trans.StopQuery(queryObject)
+	if err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+
+}
+
+func Test_nsomock_Comet(t *testing.T) {
+	server := nsomock.NewServer(nsomock.WithPollTimeout(50 * time.Millisecond))
+	defer server.Close()
+
+	comet, _ := NewNsoJsonRpcComet("http", server.Host(), server.Port(), "admin", "admin", false)
+
+	err := comet.StartComet()
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	_, err = comet.SubscribeChanges("/ncs:devices")
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	config, _ := NewNsoJsonRpcConfig("http", server.Host(), server.Port(), "admin", "admin", false)
+	_ = config.NsoLogin()
+	trans, _ := config.NewTransaction("read_write", "private", "", "reuse")
+	_, _ = trans.SetValue("/ncs:devices/device{r1}/address", "10.0.0.1", false)
+	_, _ = trans.Commit(false, "", false)
+
+	response, err := comet.CometPoll()
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	var body struct {
+		Result []struct {
+			Message struct {
+				Changes []struct {
+					Keypath string `json:"keypath"`
+					Op      string `json:"op"`
+					Value   string `json:"value"`
+				} `json:"changes"`
+			} `json:"message"`
+		} `json:"result"`
+	}
+
+	err = response.ToJSON(&body)
+	if err != nil || len(body.Result) != 1 {
+		t.Fatalf("expected one notification got %v %v", body.Result, err)
+	}
+
+	last := body.Result[0].Message.Changes[len(body.Result[0].Message.Changes)-1]
+	if last.Keypath != "/devices/device{r1}/address" || last.Value != "10.0.0.1" {
+		t.Errorf("expected %v got %v", "/devices/device{r1}/address", last)
+	}
+
+	err = comet.StopComet()
+	if err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+
+}
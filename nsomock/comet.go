@@ -0,0 +1,312 @@
+package nsomock
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// cometQueue holds the notifications waiting for a comet poll
+type cometQueue struct {
+	messages []interface{}
+	wake     chan struct{}
+}
+
+// Constructor for an empty cometQueue
+func newCometQueue() *cometQueue {
+
+	return &cometQueue{wake: make(chan struct{}, 1)}
+
+}
+
+// Method to wake up a waiting comet poll
+func (q *cometQueue) notify() {
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+
+}
+
+// Method to add a notification and wake up a waiting comet poll
+//   :values handle: The subscription handle
+//   :values message: The notification
+func (q *cometQueue) push(handle string, message interface{}) {
+	q.messages = append(q.messages, map[string]interface{}{"handle": handle, "message": message})
+	q.notify()
+
+}
+
+// Method to take all waiting notifications
+func (q *cometQueue) take() []interface{} {
+	messages := q.messages
+	q.messages = nil
+
+	if messages == nil {
+		messages = []interface{}{}
+	}
+
+	return messages
+
+}
+
+// subscription holds a comet subscription
+type subscription struct {
+	kind        string
+	path        string
+	cometID     string
+	sess        *session
+	started     bool
+	skipLocal   bool
+	hideChanges bool
+	hideValues  bool
+}
+
+// Method to get the cometQueue of a comet id, it is added when missing
+//   :values sess: The session of the request
+//   :values cometID: The comet id
+func (sess *session) comet(cometID string) *cometQueue {
+	queue, ok := sess.comets[cometID]
+
+	if !ok {
+		queue = newCometQueue()
+		sess.comets[cometID] = queue
+	}
+
+	return queue
+
+}
+
+// Method to long-poll for notifications, it waits until there is one or the poll timeout
+//   :values r: The *http.Request
+//   :values params: The request params
+func (s *Server) comet(r *http.Request, params map[string]interface{}) (interface{}, *rpcError) {
+	cometID, rpcErr := stringParam(params, "comet_id")
+
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+
+	s.lock.Lock()
+
+	sess, _, rpcErr := s.session(r)
+
+	if rpcErr != nil {
+		s.lock.Unlock()
+		return nil, rpcErr
+	}
+
+	queue := sess.comet(cometID)
+
+	if len(queue.messages) == 0 {
+		s.lock.Unlock()
+
+		timer := time.NewTimer(s.pollTimeout)
+
+		select {
+		case <-queue.wake:
+		case <-timer.C:
+		case <-r.Context().Done():
+		}
+		timer.Stop()
+
+		s.lock.Lock()
+
+		// The session may have expired while waiting
+		_, _, rpcErr = s.session(r)
+
+		if rpcErr != nil {
+			s.lock.Unlock()
+			return nil, rpcErr
+		}
+	}
+
+	defer s.lock.Unlock()
+
+	return queue.take(), nil
+
+}
+
+// Method to add a subscription
+// a handle given in the params is used instead of a new one
+//   :values sess: The session of the request
+//   :values params: The request params
+//   :values kind: changes, poll_leaf, or messages
+func (s *Server) subscribe(sess *session, params map[string]interface{}, kind string) (interface{}, *rpcError) {
+	cometID, rpcErr := stringParam(params, "comet_id")
+
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+
+	var path string
+
+	// A messages subscription is not on a path
+	if kind != "messages" {
+		path, rpcErr = pathParam(params)
+
+		if rpcErr != nil {
+			return nil, rpcErr
+		}
+	}
+
+	sess.comet(cometID)
+
+	handle, _ := params["handle"].(string)
+
+	if handle == "" {
+		handle = fmt.Sprintf("%s-%d", cometID, s.newHandle())
+	}
+
+	sub := &subscription{kind: kind, path: path, cometID: cometID, sess: sess}
+	sub.skipLocal, _ = params["skip_local_changes"].(bool)
+	sub.hideChanges, _ = params["hide_changes"].(bool)
+	sub.hideValues, _ = params["hide_values"].(bool)
+
+	s.subscriptions[handle] = sub
+
+	return map[string]interface{}{"handle": handle}, nil
+
+}
+
+// Method to handle subscribe_changes
+func (s *Server) subscribeChanges(sess *session, params map[string]interface{}) (interface{}, *rpcError) {
+	return s.subscribe(sess, params, "changes")
+
+}
+
+// Method to handle subscribe_poll_leaf
+func (s *Server) subscribePollLeaf(sess *session, params map[string]interface{}) (interface{}, *rpcError) {
+	return s.subscribe(sess, params, "poll_leaf")
+
+}
+
+// Method to handle subscribe_messages
+func (s *Server) subscribeMessages(sess *session, params map[string]interface{}) (interface{}, *rpcError) {
+	return s.subscribe(sess, params, "messages")
+
+}
+
+// Method to get the subscription of a request
+//   :values sess: The session of the request
+//   :values params: The request params
+func (s *Server) subscription(sess *session, params map[string]interface{}) (string, *subscription, *rpcError) {
+	handle, rpcErr := stringParam(params, "handle")
+
+	if rpcErr != nil {
+		return "", nil, rpcErr
+	}
+
+	sub, ok := s.subscriptions[handle]
+
+	if !ok || sub.sess != sess {
+		return "", nil, invalidParams("handle")
+	}
+
+	return handle, sub, nil
+
+}
+
+// Method to handle start_subscription
+func (s *Server) startSubscription(sess *session, params map[string]interface{}) (interface{}, *rpcError) {
+	handle, sub, rpcErr := s.subscription(sess, params)
+
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+
+	sub.started = true
+
+	// A poll leaf subscription reports the value it starts with
+	if sub.kind == "poll_leaf" {
+		value, _ := s.running.value(sub.path)
+		sess.comet(sub.cometID).push(handle, map[string]interface{}{"value": value})
+	}
+
+	return map[string]interface{}{}, nil
+
+}
+
+// Method to handle unsubscribe
+func (s *Server) unsubscribe(sess *session, params map[string]interface{}) (interface{}, *rpcError) {
+	handle, _, rpcErr := s.subscription(sess, params)
+
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+
+	delete(s.subscriptions, handle)
+
+	return map[string]interface{}{}, nil
+
+}
+
+// Method to notify the started subscriptions about a commit
+// a poll leaf subscription is notified when its value changes instead of on an interval
+//   :values sess: The session that made the commit
+//   :values db: The datastore committed to
+//   :values old: The running datastore before the commit
+//   :values changes: The committed changes
+func (s *Server) notifyChanges(sess *session, db string, old *datastore, changes []change) {
+	for handle, sub := range s.subscriptions {
+		if !sub.started {
+			continue
+		}
+
+		queue := sub.sess.comet(sub.cometID)
+
+		switch sub.kind {
+		case "changes":
+			if sub.skipLocal && sub.sess == sess {
+				continue
+			}
+
+			var matched []change
+
+			for _, c := range changes {
+				if isUnder(c.Keypath, sub.path) || isUnder(sub.path, c.Keypath) {
+					if sub.hideValues {
+						c.Value = ""
+					}
+
+					matched = append(matched, c)
+				}
+			}
+
+			if len(matched) == 0 {
+				continue
+			}
+
+			message := map[string]interface{}{"db": db, "user": sess.user}
+
+			if !sub.hideChanges {
+				message["changes"] = matched
+			}
+
+			queue.push(handle, message)
+
+		case "poll_leaf":
+			before, _ := old.value(sub.path)
+			after, _ := s.running.value(sub.path)
+
+			if before != after {
+				queue.push(handle, map[string]interface{}{"value": after})
+			}
+		}
+	}
+
+}
+
+// Method to send a system message to the started messages subscriptions
+//   :values message: The message text
+func (s *Server) SendMessage(message string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for handle, sub := range s.subscriptions {
+		if sub.started && sub.kind == "messages" {
+			sub.sess.comet(sub.cometID).push(handle, map[string]interface{}{"type": "sys_message", "user": "system", "message": message})
+		}
+	}
+
+}
@@ -0,0 +1,391 @@
+package nsomock
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// keyedElement matches a list entry element like device{r1}
+var keyedElement = regexp.MustCompile(`^([^{]+)\{(.*)\}$`)
+
+// change holds a single change made in a transaction
+type change struct {
+	Keypath string `json:"keypath"`
+	Op      string `json:"op"`
+	Value   string `json:"value,omitempty"`
+}
+
+// datastore holds configuration as leaf keypaths and the nodes, like list entries, that exist
+type datastore struct {
+	leaves map[string]string
+	nodes  map[string]bool
+}
+
+// Constructor for an empty datastore
+func newDatastore() *datastore {
+
+	return &datastore{leaves: make(map[string]string), nodes: make(map[string]bool)}
+
+}
+
+// Method to copy the datastore, a transaction works on a copy
+func (d *datastore) copy() *datastore {
+	c := newDatastore()
+
+	for path, value := range d.leaves {
+		c.leaves[path] = value
+	}
+
+	for path := range d.nodes {
+		c.nodes[path] = true
+	}
+
+	return c
+
+}
+
+// splitKeypath splits a keypath into elements, a / inside a list key does not split
+//   :values path: A keypath like /ncs:devices/device{r1}/address
+func splitKeypath(path string) []string {
+	var elements []string
+	var current strings.Builder
+
+	depth := 0
+
+	for _, r := range path {
+		switch {
+		case r == '{':
+			depth++
+		case r == '}':
+			depth--
+		case r == '/' && depth == 0:
+			if current.Len() > 0 {
+				elements = append(elements, current.String())
+				current.Reset()
+			}
+			continue
+		}
+		current.WriteRune(r)
+	}
+
+	if current.Len() > 0 {
+		elements = append(elements, current.String())
+	}
+
+	return elements
+
+}
+
+// normalizeKeypath removes module prefixes so /ncs:devices and /devices are the same
+//   :values path: A keypath
+func normalizeKeypath(path string) string {
+	elements := splitKeypath(path)
+
+	for i, element := range elements {
+		name := element
+		key := ""
+
+		if match := keyedElement.FindStringSubmatch(element); match != nil {
+			name = match[1]
+			key = "{" + match[2] + "}"
+		}
+
+		if colon := strings.Index(name, ":"); colon > -1 {
+			name = name[colon+1:]
+		}
+
+		elements[i] = name + key
+	}
+
+	return "/" + strings.Join(elements, "/")
+
+}
+
+// parentKeypaths gets the keypaths of all the parents of a keypath
+//   :values path: A normalized keypath
+func parentKeypaths(path string) []string {
+	elements := splitKeypath(path)
+	var parents []string
+
+	for i := 1; i < len(elements); i++ {
+		parents = append(parents, "/"+strings.Join(elements[:i], "/"))
+	}
+
+	return parents
+
+}
+
+// isUnder checks if a keypath is a keypath or one of its children
+//   :values path: A normalized keypath
+//   :values parent: A normalized keypath
+func isUnder(path string, parent string) bool {
+	return parent == "/" || path == parent || strings.HasPrefix(path, parent+"/")
+
+}
+
+// Method to check if a keypath exists
+//   :values path: A normalized keypath
+func (d *datastore) exists(path string) bool {
+	if _, ok := d.leaves[path]; ok {
+		return true
+	}
+
+	for node := range d.nodes {
+		if isUnder(node, path) {
+			return true
+		}
+	}
+
+	for leaf := range d.leaves {
+		if isUnder(leaf, path) {
+			return true
+		}
+	}
+
+	return false
+
+}
+
+// Method to set a leaf, the parents are created when they are missing
+//   :values path: A normalized keypath
+//   :values value: The value
+func (d *datastore) setValue(path string, value string) {
+	for _, parent := range parentKeypaths(path) {
+		d.nodes[parent] = true
+	}
+
+	d.leaves[path] = value
+
+}
+
+// Method to create a node like a list entry
+//   :values path: A normalized keypath
+func (d *datastore) create(path string) {
+	for _, parent := range parentKeypaths(path) {
+		d.nodes[parent] = true
+	}
+
+	d.nodes[path] = true
+
+}
+
+// Method to delete a keypath and all its children
+//   :values path: A normalized keypath
+func (d *datastore) delete(path string) {
+	for leaf := range d.leaves {
+		if isUnder(leaf, path) {
+			delete(d.leaves, leaf)
+		}
+	}
+
+	for node := range d.nodes {
+		if isUnder(node, path) {
+			delete(d.nodes, node)
+		}
+	}
+
+}
+
+// Method to get the sorted leaf keypaths under a keypath
+//   :values path: A normalized keypath
+func (d *datastore) leafPaths(path string) []string {
+	var paths []string
+
+	for leaf := range d.leaves {
+		if isUnder(leaf, path) {
+			paths = append(paths, leaf)
+		}
+	}
+	sort.Strings(paths)
+
+	return paths
+
+}
+
+// Method to get the value of a leaf, the key leaves of a list entry are found from the keypath
+//   :values path: A normalized keypath
+func (d *datastore) value(path string) (string, bool) {
+	if value, ok := d.leaves[path]; ok {
+		return value, true
+	}
+
+	// A list key like /devices/device{r1}/name
+	elements := splitKeypath(path)
+	if len(elements) > 1 && d.exists("/"+strings.Join(elements[:len(elements)-1], "/")) {
+		if match := keyedElement.FindStringSubmatch(elements[len(elements)-2]); match != nil && keyLeaves[elements[len(elements)-1]] {
+			return strings.Fields(match[2])[0], true
+		}
+	}
+
+	return "", false
+
+}
+
+// keyLeaves are the leaf names the mock treats as the key of a list
+var keyLeaves = map[string]bool{"name": true, "id": true, "key": true}
+
+// Method to get the sorted list entries of a list
+//   :values path: A normalized keypath to a list like /devices/device
+func (d *datastore) listEntries(path string) []string {
+	found := make(map[string]bool)
+
+	add := func(keypath string) {
+		elements := splitKeypath(keypath)
+
+		for i, element := range elements {
+			match := keyedElement.FindStringSubmatch(element)
+
+			if match == nil {
+				continue
+			}
+
+			list := "/" + strings.Join(append(append([]string{}, elements[:i]...), match[1]), "/")
+
+			if list == path {
+				found["/"+strings.Join(elements[:i+1], "/")] = true
+				return
+			}
+		}
+	}
+
+	for leaf := range d.leaves {
+		add(leaf)
+	}
+
+	for node := range d.nodes {
+		add(node)
+	}
+
+	return sortedKeys(found)
+
+}
+
+// Method to get the changes between this datastore and a newer one
+// like NSO only the top deleted keypath and only created list entries or empty nodes are reported
+//   :values newer: The datastore after the changes
+func (d *datastore) diff(newer *datastore) []change {
+	var changes []change
+
+	deleted := make(map[string]bool)
+
+	for _, path := range sortedKeys(d.nodes) {
+		if !newer.nodes[path] {
+			deleted[path] = true
+
+			if !hasParentIn(path, deleted) {
+				changes = append(changes, change{Keypath: path, Op: "deleted"})
+			}
+		}
+	}
+
+	for _, path := range sortedKeys(newer.nodes) {
+		if d.nodes[path] {
+			continue
+		}
+
+		elements := splitKeypath(path)
+		if keyedElement.MatchString(elements[len(elements)-1]) || !newer.hasChildren(path) {
+			changes = append(changes, change{Keypath: path, Op: "created"})
+		}
+	}
+
+	for _, path := range sortedKeys(d.leaves) {
+		if _, ok := newer.leaves[path]; !ok && !hasParentIn(path, deleted) {
+			changes = append(changes, change{Keypath: path, Op: "deleted"})
+		}
+	}
+
+	for _, path := range sortedKeys(newer.leaves) {
+		if old, ok := d.leaves[path]; !ok || old != newer.leaves[path] {
+			changes = append(changes, change{Keypath: path, Op: "value_set", Value: newer.leaves[path]})
+		}
+	}
+
+	return changes
+
+}
+
+// hasParentIn checks if one of the parents of a keypath is in a set
+//   :values path: A normalized keypath
+//   :values set: A set of keypaths
+func hasParentIn(path string, set map[string]bool) bool {
+	for _, parent := range parentKeypaths(path) {
+		if set[parent] {
+			return true
+		}
+	}
+
+	return false
+
+}
+
+// Method to check if a node has any children
+//   :values path: A normalized keypath
+func (d *datastore) hasChildren(path string) bool {
+	for node := range d.nodes {
+		if node != path && isUnder(node, path) {
+			return true
+		}
+	}
+
+	for leaf := range d.leaves {
+		if isUnder(leaf, path) {
+			return true
+		}
+	}
+
+	return false
+
+}
+
+// Method to apply changes made in a transaction
+//   :values changes: The changes from diff
+func (d *datastore) apply(changes []change) {
+	for _, c := range changes {
+		switch c.Op {
+		case "deleted":
+			d.delete(c.Keypath)
+		case "created":
+			d.create(c.Keypath)
+		case "value_set":
+			d.setValue(c.Keypath, c.Value)
+		}
+	}
+
+}
+
+// Method to show the configuration under a keypath with one leaf per line
+//   :values path: A normalized keypath
+func (d *datastore) showConfig(path string) string {
+	var sb strings.Builder
+
+	for _, leaf := range d.leafPaths(path) {
+		sb.WriteString(fmt.Sprintf("%s %s\n", leaf, d.leaves[leaf]))
+	}
+
+	return sb.String()
+
+}
+
+// sortedKeys gets the keys of a map sorted
+//   :values m: A map with string keys
+func sortedKeys(m interface{}) []string {
+	var keys []string
+
+	switch typed := m.(type) {
+	case map[string]bool:
+		for key := range typed {
+			keys = append(keys, key)
+		}
+	case map[string]string:
+		for key := range typed {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	return keys
+
+}
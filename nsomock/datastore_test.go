@@ -0,0 +1,122 @@
+package nsomock
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_normalizeKeypath(t *testing.T) {
+	scenarios := []struct {
+		path   string
+		expect string
+	}{
+		{path: "/ncs:devices/device{r1}/address", expect: "/devices/device{r1}/address"},
+		{path: "/devices/device{r1}/config/ios:hostname", expect: "/devices/device{r1}/config/hostname"},
+		{path: "/ncs:devices/device{a/b}/address", expect: "/devices/device{a/b}/address"},
+		{path: "/", expect: "/"},
+	}
+
+	for _, scenario := range scenarios {
+		rcv := normalizeKeypath(scenario.path)
+		if rcv != scenario.expect {
+			t.Errorf("expected %v got %v", scenario.expect, rcv)
+		}
+	}
+
+}
+
+func Test_datastore_value(t *testing.T) {
+	ds := newDatastore()
+	ds.setValue("/devices/device{r1}/address", "10.0.0.1")
+
+	scenarios := []struct {
+		path   string
+		expect string
+		found  bool
+	}{
+		{path: "/devices/device{r1}/address", expect: "10.0.0.1", found: true},
+		{path: "/devices/device{r1}/name", expect: "r1", found: true},
+		{path: "/devices/device{r2}/name", expect: "", found: false},
+		{path: "/devices/device{r1}/port", expect: "", found: false},
+	}
+
+	for _, scenario := range scenarios {
+		rcv, found := ds.value(scenario.path)
+		if rcv != scenario.expect || found != scenario.found {
+			t.Errorf("expected %v %v got %v %v", scenario.expect, scenario.found, rcv, found)
+		}
+	}
+
+}
+
+func Test_datastore_diff(t *testing.T) {
+	old := newDatastore()
+	old.setValue("/devices/device{r1}/address", "10.0.0.1")
+	old.setValue("/devices/device{r2}/address", "10.0.0.2")
+
+	newer := old.copy()
+	newer.setValue("/devices/device{r1}/address", "10.0.0.10")
+	newer.delete("/devices/device{r2}")
+	newer.create("/devices/device{r3}")
+
+	expect := []change{
+		{Keypath: "/devices/device{r2}", Op: "deleted"},
+		{Keypath: "/devices/device{r3}", Op: "created"},
+		{Keypath: "/devices/device{r1}/address", Op: "value_set", Value: "10.0.0.10"},
+	}
+
+	rcv := old.diff(newer)
+	if !reflect.DeepEqual(rcv, expect) {
+		t.Errorf("expected %v got %v", expect, rcv)
+	}
+
+	old.apply(rcv)
+	if len(old.diff(newer)) != 0 {
+		t.Errorf("expected no changes got %v", old.diff(newer))
+	}
+
+}
+
+func Test_evaluate(t *testing.T) {
+	ds := newDatastore()
+	ds.setValue("/devices/device{r1}/address", "10.0.0.1")
+	ds.setValue("/devices/device{r2}/address", "10.0.0.2")
+	ds.setValue("/devices/device{r2}/port", "830")
+
+	scenarios := []struct {
+		expression string
+		expect     []string
+	}{
+		{expression: "/ncs:devices/device", expect: []string{"/devices/device{r1}", "/devices/device{r2}"}},
+		{expression: "/devices/device[name='r2']", expect: []string{"/devices/device{r2}"}},
+		{expression: "/devices/device[address=\"10.0.0.1\"]/address", expect: []string{"/devices/device{r1}/address"}},
+		{expression: "/devices/device[port='830']", expect: []string{"/devices/device{r2}"}},
+		{expression: "/devices/device{r1}", expect: []string{"/devices/device{r1}"}},
+		{expression: "/devices/device[name='r9']", expect: nil},
+	}
+
+	for _, scenario := range scenarios {
+		rcv := evaluate(ds, scenario.expression)
+		if !reflect.DeepEqual(rcv, scenario.expect) {
+			t.Errorf("expected %v got %v", scenario.expect, rcv)
+		}
+	}
+
+}
+
+func Test_cliDiff(t *testing.T) {
+	base := newDatastore()
+	base.setValue("/devices/device{r1}/config/hostname", "old")
+
+	changes := []change{
+		{Keypath: "/devices/device{r1}/config/hostname", Op: "value_set", Value: "new"},
+	}
+
+	expect := " devices {\n     device r1 {\n         config {\n-            hostname old;\n+            hostname new;\n         }\n     }\n }\n"
+
+	rcv := cliDiff(base, changes)
+	if rcv != expect {
+		t.Errorf("expected %q got %q", expect, rcv)
+	}
+
+}
@@ -0,0 +1,295 @@
+package nsomock
+
+import (
+	"regexp"
+	"strings"
+)
+
+// xpathStep matches a step of a simple XPATH expression like device[name='r1']
+var xpathStep = regexp.MustCompile(`^([^\[]+)((?:\[[^\]]*\])*)$`)
+
+// xpathPredicate matches a predicate like [name='r1']
+var xpathPredicate = regexp.MustCompile(`\[\s*([^=\s]+)\s*=\s*['"]([^'"]*)['"]\s*\]`)
+
+// query holds a started query
+type query struct {
+	trans         *transaction
+	matches       []string
+	selection     []string
+	resultAs      string
+	chunkSize     int
+	initialOffset int
+	position      int
+}
+
+// splitXPath splits a XPATH expression into steps, a / inside a predicate does not split
+//   :values expression: A XPATH expression
+func splitXPath(expression string) []string {
+	var steps []string
+	var current strings.Builder
+
+	depth := 0
+
+	for _, r := range expression {
+		switch {
+		case r == '[' || r == '{':
+			depth++
+		case r == ']' || r == '}':
+			depth--
+		case r == '/' && depth == 0:
+			if current.Len() > 0 {
+				steps = append(steps, current.String())
+				current.Reset()
+			}
+			continue
+		}
+		current.WriteRune(r)
+	}
+
+	if current.Len() > 0 {
+		steps = append(steps, current.String())
+	}
+
+	return steps
+
+}
+
+// evaluate finds the keypaths matching a simple XPATH expression
+// only absolute paths with name and [leaf='value'] steps are understood
+//   :values ds: The datastore
+//   :values expression: A XPATH expression, or a keypath
+func evaluate(ds *datastore, expression string) []string {
+	current := []string{""}
+
+	for _, step := range splitXPath(expression) {
+		match := xpathStep.FindStringSubmatch(step)
+
+		if match == nil {
+			return nil
+		}
+
+		name := normalizeKeypath(match[1])[1:]
+		predicates := xpathPredicate.FindAllStringSubmatch(match[2], -1)
+
+		var next []string
+
+		for _, path := range current {
+			candidates := ds.listEntries(path + "/" + name)
+
+			if len(candidates) == 0 && ds.exists(path+"/"+name) {
+				candidates = []string{path + "/" + name}
+			}
+
+			for _, candidate := range candidates {
+				if matchesPredicates(ds, candidate, predicates) {
+					next = append(next, candidate)
+				}
+			}
+		}
+
+		current = next
+	}
+
+	return current
+
+}
+
+// matchesPredicates checks the [leaf='value'] predicates of a step
+//   :values ds: The datastore
+//   :values path: A keypath
+//   :values predicates: The matched predicates
+func matchesPredicates(ds *datastore, path string, predicates [][]string) bool {
+	for _, predicate := range predicates {
+		value, ok := ds.value(path + "/" + normalizeKeypath(predicate[1])[1:])
+
+		if !ok || value != predicate[2] {
+			return false
+		}
+	}
+
+	return true
+
+}
+
+// Method to get the results of the query from a position
+//   :values position: The position of the first result
+//   :values count: The number of results, 0 for all
+func (q *query) results(position int, count int) []interface{} {
+	results := []interface{}{}
+
+	for i := position; i < len(q.matches) && (count == 0 || i < position+count); i++ {
+		match := q.matches[i]
+
+		// A query without a selection gets the keypath of each match
+		selection := q.selection
+		if len(selection) == 0 {
+			selection = []string{"."}
+		}
+
+		var row []interface{}
+
+		for _, selected := range selection {
+			path, value, ok := match, match, true
+
+			if selected != "." {
+				path = match + "/" + normalizeKeypath(selected)[1:]
+				value, ok = q.trans.work.value(path)
+			}
+
+			switch {
+			case q.resultAs == "keypath-value":
+				row = append(row, map[string]interface{}{"keypath": path, "value": value})
+			case ok:
+				row = append(row, value)
+			default:
+				row = append(row, nil)
+			}
+		}
+
+		results = append(results, row)
+	}
+
+	return results
+
+}
+
+// Method to run the query from its position and move the position on
+func (q *query) run() map[string]interface{} {
+	results := q.results(q.position, q.chunkSize)
+	q.position += len(results)
+
+	return map[string]interface{}{
+		"current_position":        q.position,
+		"total_number_of_results": len(q.matches),
+		"number_of_results":       len(results),
+		"results":                 results,
+	}
+
+}
+
+// newQuery creates a query from the request params
+//   :values trans: The transaction to query
+//   :values params: The request params
+func newQuery(trans *transaction, params map[string]interface{}) (*query, *rpcError) {
+	expression, _ := params["xpath_expr"].(string)
+
+	if expression == "" {
+		expression, _ = params["path"].(string)
+	}
+
+	if expression == "" {
+		return nil, invalidParams("xpath_expr")
+	}
+
+	q := &query{
+		trans:         trans,
+		matches:       evaluate(trans.work, expression),
+		chunkSize:     intParam(params, "chunk_size"),
+		initialOffset: intParam(params, "initial_offset"),
+	}
+
+	q.resultAs, _ = params["result_as"].(string)
+	q.position = q.initialOffset
+
+	selection, _ := params["selection"].([]interface{})
+	for _, selected := range selection {
+		if name, ok := selected.(string); ok {
+			q.selection = append(q.selection, name)
+		}
+	}
+
+	return q, nil
+
+}
+
+// Method to handle query
+func (s *Server) query(sess *session, params map[string]interface{}) (interface{}, *rpcError) {
+	trans, rpcErr := s.transaction(sess, params)
+
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+
+	q, rpcErr := newQuery(trans, params)
+
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+
+	return q.run(), nil
+
+}
+
+// Method to handle start_query
+func (s *Server) startQuery(sess *session, params map[string]interface{}) (interface{}, *rpcError) {
+	trans, rpcErr := s.transaction(sess, params)
+
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+
+	q, rpcErr := newQuery(trans, params)
+
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+
+	qh := s.newHandle()
+	s.queries[qh] = q
+
+	return map[string]interface{}{"qh": qh}, nil
+
+}
+
+// Method to get the started query of a request
+//   :values params: The request params
+func (s *Server) startedQuery(params map[string]interface{}) (*query, *rpcError) {
+	q, ok := s.queries[intParam(params, "qh")]
+
+	if !ok {
+		return nil, invalidParams("qh")
+	}
+
+	return q, nil
+
+}
+
+// Method to handle run_query
+func (s *Server) runQuery(sess *session, params map[string]interface{}) (interface{}, *rpcError) {
+	q, rpcErr := s.startedQuery(params)
+
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+
+	return q.run(), nil
+
+}
+
+// Method to handle reset_query
+func (s *Server) resetQuery(sess *session, params map[string]interface{}) (interface{}, *rpcError) {
+	q, rpcErr := s.startedQuery(params)
+
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+
+	q.position = q.initialOffset
+
+	return map[string]interface{}{}, nil
+
+}
+
+// Method to handle stop_query
+func (s *Server) stopQuery(sess *session, params map[string]interface{}) (interface{}, *rpcError) {
+	_, rpcErr := s.startedQuery(params)
+
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+
+	delete(s.queries, intParam(params, "qh"))
+
+	return map[string]interface{}{}, nil
+
+}
@@ -0,0 +1,465 @@
+// Package nsomock is an in-memory NSO JSON-RPC server for tests
+// it answers on /jsonrpc like a NSO Server and keeps configuration as keypaths
+package nsomock
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// rpcError is the error member of a JSON-RPC response
+type rpcError struct {
+	Code    int                    `json:"code"`
+	Type    string                 `json:"type"`
+	Message string                 `json:"message"`
+	Data    map[string]interface{} `json:"data,omitempty"`
+}
+
+// Method to get the error as a string
+func (e *rpcError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Type, e.Message)
+
+}
+
+// newRpcError creates a NSO method error
+//   :values errType: The NSO error type like data.not_found
+//   :values message: The error message
+func newRpcError(errType string, message string) *rpcError {
+	return &rpcError{Code: -32000, Type: errType, Message: message}
+
+}
+
+// invalidParams creates the error for a missing or wrong parameter
+//   :values param: The name of the parameter
+func invalidParams(param string) *rpcError {
+	return &rpcError{Code: -32602, Type: "rpc.request.params.invalid", Message: "Invalid parameters", Data: map[string]interface{}{"param": param}}
+
+}
+
+// rpcRequest is a decoded JSON-RPC request
+type rpcRequest struct {
+	ID     json.RawMessage        `json:"id"`
+	Method string                 `json:"method"`
+	Params map[string]interface{} `json:"params"`
+}
+
+// session holds what belongs to a logged in user
+type session struct {
+	user         string
+	transactions map[int]*transaction
+	comets       map[string]*cometQueue
+}
+
+// Server is an in-memory NSO JSON-RPC server
+type Server struct {
+	server        *httptest.Server
+	lock          sync.Mutex
+	username      string
+	password      string
+	pollTimeout   time.Duration
+	running       *datastore
+	sessions      map[string]*session
+	subscriptions map[string]*subscription
+	queries       map[int]*query
+	failures      map[string][]*rpcError
+	calls         []string
+	nextHandle    int
+}
+
+// Option sets a Server option
+type Option func(*Server)
+
+// Option to set the username and password the Server accepts, the default is admin admin
+//   :values username: A username
+//   :values password: A password
+func WithUser(username string, password string) Option {
+	return func(s *Server) {
+		s.username = username
+		s.password = password
+	}
+
+}
+
+// Option to set how long a comet poll waits for a notification, the default is 200ms
+//   :values timeout: A time.Duration
+func WithPollTimeout(timeout time.Duration) Option {
+	return func(s *Server) {
+		s.pollTimeout = timeout
+	}
+
+}
+
+// Constructor for a started Server, call Close when done
+//   :values opts: Options for the Server
+func NewServer(opts ...Option) *Server {
+	s := &Server{
+		username:      "admin",
+		password:      "admin",
+		pollTimeout:   200 * time.Millisecond,
+		running:       newDatastore(),
+		sessions:      make(map[string]*session),
+		subscriptions: make(map[string]*subscription),
+		queries:       make(map[int]*query),
+		failures:      make(map[string][]*rpcError),
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	s.server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+
+	return s
+
+}
+
+// Method to stop the Server
+func (s *Server) Close() {
+	s.server.Close()
+
+}
+
+// Method to get the base URL of the Server like http://127.0.0.1:12345
+func (s *Server) URL() string {
+	return s.server.URL
+
+}
+
+// Method to get the IP address the Server listens on
+func (s *Server) Host() string {
+	host, _, _ := net.SplitHostPort(s.server.Listener.Addr().String())
+
+	return host
+
+}
+
+// Method to get the port the Server listens on
+func (s *Server) Port() int {
+	_, port, _ := net.SplitHostPort(s.server.Listener.Addr().String())
+	number, _ := strconv.Atoi(port)
+
+	return number
+
+}
+
+// Method to set a leaf in the running datastore
+//   :values path: A keypath like /ncs:devices/device{r1}/address
+//   :values value: The value
+func (s *Server) SetValue(path string, value string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.running.setValue(normalizeKeypath(path), value)
+
+}
+
+// Method to create a node like a list entry in the running datastore
+//   :values path: A keypath like /ncs:devices/device{r1}
+func (s *Server) Create(path string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.running.create(normalizeKeypath(path))
+
+}
+
+// Method to delete a keypath and all its children from the running datastore
+//   :values path: A keypath
+func (s *Server) Delete(path string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.running.delete(normalizeKeypath(path))
+
+}
+
+// Method to get a leaf from the running datastore
+//   :values path: A keypath
+func (s *Server) Value(path string) (string, bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return s.running.value(normalizeKeypath(path))
+
+}
+
+// Method to get the methods called so far in order
+func (s *Server) Calls() []string {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return append([]string(nil), s.calls...)
+
+}
+
+// Method to make the next call of a method fail with a NSO error
+// each call queues one failure
+//   :values method: The JSON-RPC method like commit
+//   :values errType: The NSO error type like db.locked
+func (s *Server) FailNext(method string, errType string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.failures[method] = append(s.failures[method], newRpcError(errType, "Injected failure"))
+
+}
+
+// Method to expire all sessions, like a NSO restart
+// the running datastore is kept
+func (s *Server) ExpireSessions() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for _, sess := range s.sessions {
+		for _, queue := range sess.comets {
+			queue.notify()
+		}
+	}
+
+	s.sessions = make(map[string]*session)
+	s.subscriptions = make(map[string]*subscription)
+	s.queries = make(map[int]*query)
+
+}
+
+// Method to get a new handle number
+func (s *Server) newHandle() int {
+	s.nextHandle++
+
+	return s.nextHandle
+
+}
+
+// Method to serve a JSON-RPC request, or a batch of them
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/jsonrpc" {
+		http.NotFound(w, r)
+		return
+	}
+
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := ioutil.ReadAll(r.Body)
+
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	// A batch is an array of requests answered with an array of responses
+	if trimmed := bytes.TrimSpace(body); len(trimmed) > 0 && trimmed[0] == '[' {
+		var requests []rpcRequest
+
+		err = json.Unmarshal(trimmed, &requests)
+
+		if err != nil || len(requests) == 0 {
+			writeJSON(w, newResponse(nil, nil, &rpcError{Code: -32600, Type: "rpc.request.invalid", Message: "Invalid request"}))
+			return
+		}
+
+		responses := make([]map[string]interface{}, 0, len(requests))
+
+		for _, request := range requests {
+			responses = append(responses, s.handle(w, r, request))
+		}
+
+		writeJSON(w, responses)
+		return
+	}
+
+	var request rpcRequest
+
+	err = json.Unmarshal(body, &request)
+
+	if err != nil {
+		writeJSON(w, newResponse(nil, nil, &rpcError{Code: -32700, Type: "rpc.request.parse_error", Message: "Parse error"}))
+		return
+	}
+
+	writeJSON(w, s.handle(w, r, request))
+
+}
+
+// Method to handle a single JSON-RPC request
+//   :values w: The http.ResponseWriter, login sets the session cookie on it
+//   :values r: The *http.Request
+//   :values request: The decoded rpcRequest
+func (s *Server) handle(w http.ResponseWriter, r *http.Request, request rpcRequest) map[string]interface{} {
+	s.lock.Lock()
+
+	s.calls = append(s.calls, request.Method)
+
+	if failures := s.failures[request.Method]; len(failures) > 0 {
+		s.failures[request.Method] = failures[1:]
+		s.lock.Unlock()
+		return newResponse(request.ID, nil, failures[0])
+	}
+
+	// comet waits for notifications without holding the lock
+	if request.Method == "comet" {
+		s.lock.Unlock()
+		result, rpcErr := s.comet(r, request.Params)
+		return newResponse(request.ID, result, rpcErr)
+	}
+
+	defer s.lock.Unlock()
+
+	if request.Method == "login" {
+		result, rpcErr := s.login(w, request.Params)
+		return newResponse(request.ID, result, rpcErr)
+	}
+
+	sess, sessionID, rpcErr := s.session(r)
+
+	if rpcErr != nil {
+		return newResponse(request.ID, nil, rpcErr)
+	}
+
+	var result interface{}
+
+	switch request.Method {
+	case "logout":
+		delete(s.sessions, sessionID)
+		result = map[string]interface{}{}
+	case "abort":
+		result = map[string]interface{}{}
+	default:
+		handler, ok := methods[request.Method]
+
+		if !ok {
+			return newResponse(request.ID, nil, &rpcError{Code: -32601, Type: "rpc.request.method.not_found", Message: "Method not found"})
+		}
+
+		result, rpcErr = handler(s, sess, request.Params)
+	}
+
+	return newResponse(request.ID, result, rpcErr)
+
+}
+
+// methods maps the JSON-RPC methods that need a session to their handlers
+var methods = map[string]func(s *Server, sess *session, params map[string]interface{}) (interface{}, *rpcError){
+	"new_trans":           (*Server).newTrans,
+	"delete_trans":        (*Server).deleteTrans,
+	"get_value":           (*Server).getValue,
+	"set_value":           (*Server).setValue,
+	"create":              (*Server).create,
+	"delete":              (*Server).delete,
+	"exists":              (*Server).exists,
+	"show_config":         (*Server).showConfig,
+	"is_trans_modified":   (*Server).isTransModified,
+	"get_trans_changes":   (*Server).getTransChanges,
+	"validate_commit":     (*Server).validateCommit,
+	"commit":              (*Server).commit,
+	"query":               (*Server).query,
+	"start_query":         (*Server).startQuery,
+	"run_query":           (*Server).runQuery,
+	"reset_query":         (*Server).resetQuery,
+	"stop_query":          (*Server).stopQuery,
+	"subscribe_changes":   (*Server).subscribeChanges,
+	"subscribe_messages":  (*Server).subscribeMessages,
+	"subscribe_poll_leaf": (*Server).subscribePollLeaf,
+	"start_subscription":  (*Server).startSubscription,
+	"unsubscribe":         (*Server).unsubscribe,
+}
+
+// Method to log in and set the sessionid cookie
+//   :values w: The http.ResponseWriter
+//   :values params: The request params
+func (s *Server) login(w http.ResponseWriter, params map[string]interface{}) (interface{}, *rpcError) {
+	user, _ := params["user"].(string)
+	passwd, _ := params["passwd"].(string)
+
+	if user != s.username || passwd != s.password {
+		return nil, newRpcError("rpc.method.failed", "Method failed")
+	}
+
+	sessionID := fmt.Sprintf("sess%d", rand.Int63())
+	s.sessions[sessionID] = &session{user: user, transactions: make(map[int]*transaction), comets: make(map[string]*cometQueue)}
+
+	http.SetCookie(w, &http.Cookie{Name: "sessionid", Value: sessionID, Path: "/"})
+
+	return map[string]interface{}{}, nil
+
+}
+
+// Method to find the session of a request
+//   :values r: The *http.Request
+func (s *Server) session(r *http.Request) (*session, string, *rpcError) {
+	cookie, err := r.Cookie("sessionid")
+
+	if err != nil {
+		return nil, "", newRpcError("session.missing_sessionid", "Missing sessionid")
+	}
+
+	sess, ok := s.sessions[cookie.Value]
+
+	if !ok {
+		return nil, "", newRpcError("session.invalid_sessionid", "Invalid sessionid")
+	}
+
+	return sess, cookie.Value, nil
+
+}
+
+// newResponse creates a JSON-RPC response
+//   :values id: The id of the request
+//   :values result: The result, used when rpcErr is nil
+//   :values rpcErr: The error or nil
+func newResponse(id json.RawMessage, result interface{}, rpcErr *rpcError) map[string]interface{} {
+	response := map[string]interface{}{"jsonrpc": "2.0", "id": id}
+
+	if rpcErr != nil {
+		response["error"] = rpcErr
+	} else {
+		response["result"] = result
+	}
+
+	return response
+
+}
+
+// writeJSON writes a JSON body
+//   :values w: The http.ResponseWriter
+//   :values body: The body
+func writeJSON(w http.ResponseWriter, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(body)
+
+}
+
+// stringParam gets a string parameter
+//   :values params: The request params
+//   :values name: The name of the parameter
+func stringParam(params map[string]interface{}, name string) (string, *rpcError) {
+	value, ok := params[name].(string)
+
+	if !ok {
+		return "", invalidParams(name)
+	}
+
+	return value, nil
+
+}
+
+// intParam gets a number parameter, a missing one is 0
+//   :values params: The request params
+//   :values name: The name of the parameter
+func intParam(params map[string]interface{}, name string) int {
+	value, _ := params[name].(float64)
+
+	return int(value)
+
+}
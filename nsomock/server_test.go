@@ -0,0 +1,264 @@
+package nsomock
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/cookiejar"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// testClient sends JSON-RPC requests to a Server keeping the session cookie
+type testClient struct {
+	t      *testing.T
+	url    string
+	client *http.Client
+	id     int
+}
+
+// newTestClient creates a testClient for a Server
+func newTestClient(t *testing.T, server *Server) *testClient {
+	jar, _ := cookiejar.New(nil)
+
+	return &testClient{t: t, url: server.URL() + "/jsonrpc", client: &http.Client{Jar: jar}}
+}
+
+// call sends a request and returns the result and error members
+func (c *testClient) call(method string, params map[string]interface{}) (map[string]interface{}, *rpcError) {
+	c.id++
+
+	body, _ := json.Marshal(map[string]interface{}{"jsonrpc": "2.0", "id": c.id, "method": method, "params": params})
+
+	response, err := c.client.Post(c.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		c.t.Fatalf("unexpected error %v", err)
+	}
+	defer response.Body.Close()
+
+	var decoded struct {
+		ID     int                    `json:"id"`
+		Result map[string]interface{} `json:"result"`
+		Error  *rpcError              `json:"error"`
+	}
+	_ = json.NewDecoder(response.Body).Decode(&decoded)
+
+	if decoded.ID != c.id {
+		c.t.Errorf("expected id %v got %v", c.id, decoded.ID)
+	}
+
+	return decoded.Result, decoded.Error
+}
+
+func Test_Server_login(t *testing.T) {
+	server := NewServer(WithUser("oper", "secret"))
+	defer server.Close()
+
+	scenarios := []struct {
+		user, passwd string
+		expect       string
+	}{
+		{user: "oper", passwd: "wrong", expect: "rpc.method.failed"},
+		{user: "admin", passwd: "admin", expect: "rpc.method.failed"},
+		{user: "oper", passwd: "secret", expect: ""},
+	}
+
+	for _, scenario := range scenarios {
+		client := newTestClient(t, server)
+
+		_, rpcErr := client.call("login", map[string]interface{}{"user": scenario.user, "passwd": scenario.passwd})
+		if rpcErr != nil && rpcErr.Type != scenario.expect || rpcErr == nil && scenario.expect != "" {
+			t.Errorf("expected %v got %v", scenario.expect, rpcErr)
+		}
+	}
+
+}
+
+func Test_Server_session(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+
+	client := newTestClient(t, server)
+
+	_, rpcErr := client.call("new_trans", map[string]interface{}{"mode": "read"})
+	if rpcErr == nil || rpcErr.Type != "session.missing_sessionid" {
+		t.Errorf("expected %v got %v", "session.missing_sessionid", rpcErr)
+	}
+
+	_, _ = client.call("login", map[string]interface{}{"user": "admin", "passwd": "admin"})
+
+	_, rpcErr = client.call("new_trans", map[string]interface{}{"mode": "read"})
+	if rpcErr != nil {
+		t.Errorf("unexpected error %v", rpcErr)
+	}
+
+	_, rpcErr = client.call("new_trans", map[string]interface{}{"mode": "read", "conf_mode": "running"})
+	if rpcErr == nil || rpcErr.Type != "rpc.request.params.invalid" {
+		t.Errorf("expected %v got %v", "rpc.request.params.invalid", rpcErr)
+	}
+
+	server.ExpireSessions()
+
+	_, rpcErr = client.call("new_trans", map[string]interface{}{"mode": "read"})
+	if rpcErr == nil || rpcErr.Type != "session.invalid_sessionid" {
+		t.Errorf("expected %v got %v", "session.invalid_sessionid", rpcErr)
+	}
+
+}
+
+func Test_Server_FailNext(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+
+	client := newTestClient(t, server)
+	_, _ = client.call("login", map[string]interface{}{"user": "admin", "passwd": "admin"})
+
+	server.FailNext("new_trans", "db.locked")
+
+	_, rpcErr := client.call("new_trans", map[string]interface{}{"mode": "read"})
+	if rpcErr == nil || rpcErr.Type != "db.locked" || rpcErr.Code != -32000 {
+		t.Errorf("expected %v got %v", "db.locked", rpcErr)
+	}
+
+	_, rpcErr = client.call("new_trans", map[string]interface{}{"mode": "read"})
+	if rpcErr != nil {
+		t.Errorf("unexpected error %v", rpcErr)
+	}
+
+	expect := []string{"login", "new_trans", "new_trans"}
+	if !reflect.DeepEqual(server.Calls(), expect) {
+		t.Errorf("expected %v got %v", expect, server.Calls())
+	}
+
+}
+
+func Test_Server_transaction(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+
+	server.SetValue("/ncs:devices/device{r1}/address", "10.0.0.1")
+
+	client := newTestClient(t, server)
+	_, _ = client.call("login", map[string]interface{}{"user": "admin", "passwd": "admin"})
+
+	result, _ := client.call("new_trans", map[string]interface{}{"mode": "read_write"})
+	th := result["th"]
+
+	scenarios := []struct {
+		method  string
+		params  map[string]interface{}
+		expect  map[string]interface{}
+		errType string
+	}{
+		{method: "get_value", params: map[string]interface{}{"path": "/ncs:devices/device{r1}/address"}, expect: map[string]interface{}{"value": "10.0.0.1"}},
+		{method: "get_value", params: map[string]interface{}{"path": "/ncs:devices/device{r2}/address"}, errType: "data.not_found"},
+		{method: "set_value", params: map[string]interface{}{"path": "/ncs:devices/device{r1}/address", "value": "10.0.0.10"}, expect: map[string]interface{}{}},
+		{method: "create", params: map[string]interface{}{"path": "/ncs:devices/device{r2}"}, expect: map[string]interface{}{}},
+		{method: "create", params: map[string]interface{}{"path": "/ncs:devices/device{r2}"}, errType: "data.already_exists"},
+		{method: "exists", params: map[string]interface{}{"path": "/ncs:devices/device{r2}"}, expect: map[string]interface{}{"exists": true}},
+		{method: "is_trans_modified", params: map[string]interface{}{}, expect: map[string]interface{}{"modified": true}},
+		{method: "delete", params: map[string]interface{}{"path": "/ncs:devices/device{r9}"}, errType: "data.not_found"},
+		{method: "show_config", params: map[string]interface{}{"path": "/ncs:devices/device{r1}"}, expect: map[string]interface{}{"config": "/devices/device{r1}/address 10.0.0.10\n"}},
+		{method: "commit", params: map[string]interface{}{}, expect: map[string]interface{}{}},
+		{method: "is_trans_modified", params: map[string]interface{}{}, expect: map[string]interface{}{"modified": false}},
+		{method: "get_value", params: map[string]interface{}{"path": "/ncs:devices/device{r1}/address", "th": 999.0}, errType: "rpc.request.params.invalid"},
+		{method: "no_such_method", params: map[string]interface{}{}, errType: "rpc.request.method.not_found"},
+	}
+
+	for _, scenario := range scenarios {
+		if _, ok := scenario.params["th"]; !ok {
+			scenario.params["th"] = th
+		}
+
+		rcv, rpcErr := client.call(scenario.method, scenario.params)
+
+		if scenario.errType != "" {
+			if rpcErr == nil || rpcErr.Type != scenario.errType {
+				t.Errorf("expected %v got %v", scenario.errType, rpcErr)
+			}
+			continue
+		}
+
+		if rpcErr != nil || !reflect.DeepEqual(rcv, scenario.expect) {
+			t.Errorf("expected %v got %v %v", scenario.expect, rcv, rpcErr)
+		}
+	}
+
+	value, _ := server.Value("/ncs:devices/device{r1}/address")
+	if value != "10.0.0.10" {
+		t.Errorf("expected %v got %v", "10.0.0.10", value)
+	}
+
+}
+
+func Test_Server_comet(t *testing.T) {
+	server := NewServer(WithPollTimeout(50 * time.Millisecond))
+	defer server.Close()
+
+	client := newTestClient(t, server)
+	_, _ = client.call("login", map[string]interface{}{"user": "admin", "passwd": "admin"})
+
+	result, _ := client.call("subscribe_changes", map[string]interface{}{"comet_id": "c1", "path": "/ncs:devices"})
+	handle := result["handle"]
+	_, _ = client.call("start_subscription", map[string]interface{}{"handle": handle})
+
+	result, _ = client.call("new_trans", map[string]interface{}{"mode": "read_write"})
+	_, _ = client.call("set_value", map[string]interface{}{"th": result["th"], "path": "/ncs:devices/device{r1}/address", "value": "10.0.0.1"})
+	_, _ = client.call("commit", map[string]interface{}{"th": result["th"]})
+
+	body, _ := json.Marshal(map[string]interface{}{"jsonrpc": "2.0", "id": 1, "method": "comet", "params": map[string]interface{}{"comet_id": "c1"}})
+
+	for _, expect := range []int{1, 0} {
+		response, err := client.client.Post(client.url, "application/json", bytes.NewReader(body))
+		if err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+
+		var decoded struct {
+			Result []struct {
+				Handle  string `json:"handle"`
+				Message struct {
+					Changes []change `json:"changes"`
+				} `json:"message"`
+			} `json:"result"`
+		}
+		_ = json.NewDecoder(response.Body).Decode(&decoded)
+		response.Body.Close()
+
+		if len(decoded.Result) != expect {
+			t.Fatalf("expected %v got %v", expect, decoded.Result)
+		}
+
+		if expect == 1 && (decoded.Result[0].Handle != handle || len(decoded.Result[0].Message.Changes) != 2) {
+			t.Errorf("expected %v got %v", handle, decoded.Result[0])
+		}
+	}
+
+}
+
+func Test_Server_batch(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+
+	client := newTestClient(t, server)
+
+	body := []byte(`[{"jsonrpc": "2.0", "id": 7, "method": "login", "params": {"user": "admin", "passwd": "admin"}}, {"jsonrpc": "2.0", "id": 8, "method": "no_such_method"}]`)
+
+	response, err := client.client.Post(client.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	defer response.Body.Close()
+
+	var decoded []struct {
+		ID    int       `json:"id"`
+		Error *rpcError `json:"error"`
+	}
+	_ = json.NewDecoder(response.Body).Decode(&decoded)
+
+	if len(decoded) != 2 || decoded[0].ID != 7 || decoded[0].Error != nil || decoded[1].ID != 8 || decoded[1].Error == nil {
+		t.Errorf("expected %v got %v", "two responses", decoded)
+	}
+
+}
@@ -0,0 +1,471 @@
+package nsomock
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// transaction holds a transaction, changes are made on work and base is the running datastore it started from
+type transaction struct {
+	db   string
+	mode string
+	base *datastore
+	work *datastore
+}
+
+// Method to get the transaction of a request
+//   :values sess: The session of the request
+//   :values params: The request params
+func (s *Server) transaction(sess *session, params map[string]interface{}) (*transaction, *rpcError) {
+	trans, ok := sess.transactions[intParam(params, "th")]
+
+	if !ok {
+		return nil, invalidParams("th")
+	}
+
+	return trans, nil
+
+}
+
+// Method to get the transaction of a request that must be read_write
+//   :values sess: The session of the request
+//   :values params: The request params
+func (s *Server) writeTransaction(sess *session, params map[string]interface{}) (*transaction, *rpcError) {
+	trans, rpcErr := s.transaction(sess, params)
+
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+
+	if trans.mode != "read_write" {
+		return nil, newRpcError("rpc.method.failed", "Transaction is read-only")
+	}
+
+	return trans, nil
+
+}
+
+// Method to get the normalized path of a request
+//   :values params: The request params
+func pathParam(params map[string]interface{}) (string, *rpcError) {
+	path, rpcErr := stringParam(params, "path")
+
+	if rpcErr != nil {
+		return "", rpcErr
+	}
+
+	return normalizeKeypath(path), nil
+
+}
+
+// Method to handle new_trans
+func (s *Server) newTrans(sess *session, params map[string]interface{}) (interface{}, *rpcError) {
+	db, _ := params["db"].(string)
+	mode, _ := params["mode"].(string)
+	confMode, _ := params["conf_mode"].(string)
+
+	if db == "" {
+		db = "running"
+	}
+
+	if mode != "read" && mode != "read_write" {
+		return nil, invalidParams("mode")
+	}
+
+	// An empty conf_mode is the NSO default of private
+	if confMode != "" && confMode != "private" && confMode != "shared" && confMode != "exclusive" {
+		return nil, invalidParams("conf_mode")
+	}
+
+	th := s.newHandle()
+	sess.transactions[th] = &transaction{db: db, mode: mode, base: s.running.copy(), work: s.running.copy()}
+
+	return map[string]interface{}{"th": th}, nil
+
+}
+
+// Method to handle delete_trans
+func (s *Server) deleteTrans(sess *session, params map[string]interface{}) (interface{}, *rpcError) {
+	_, rpcErr := s.transaction(sess, params)
+
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+
+	delete(sess.transactions, intParam(params, "th"))
+
+	return map[string]interface{}{}, nil
+
+}
+
+// Method to handle get_value
+func (s *Server) getValue(sess *session, params map[string]interface{}) (interface{}, *rpcError) {
+	trans, rpcErr := s.transaction(sess, params)
+
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+
+	path, rpcErr := pathParam(params)
+
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+
+	value, ok := trans.work.value(path)
+
+	if !ok {
+		return nil, newRpcError("data.not_found", "Element does not exist")
+	}
+
+	return map[string]interface{}{"value": value}, nil
+
+}
+
+// Method to handle set_value
+func (s *Server) setValue(sess *session, params map[string]interface{}) (interface{}, *rpcError) {
+	trans, rpcErr := s.writeTransaction(sess, params)
+
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+
+	path, rpcErr := pathParam(params)
+
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+
+	value, ok := params["value"]
+
+	if !ok {
+		return nil, invalidParams("value")
+	}
+
+	if dryRun, _ := params["dryrun"].(bool); !dryRun {
+		trans.work.setValue(path, fmt.Sprintf("%v", value))
+	}
+
+	return map[string]interface{}{}, nil
+
+}
+
+// Method to handle create
+func (s *Server) create(sess *session, params map[string]interface{}) (interface{}, *rpcError) {
+	trans, rpcErr := s.writeTransaction(sess, params)
+
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+
+	path, rpcErr := pathParam(params)
+
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+
+	if trans.work.exists(path) {
+		return nil, newRpcError("data.already_exists", "Element already exists")
+	}
+
+	trans.work.create(path)
+
+	return map[string]interface{}{}, nil
+
+}
+
+// Method to handle delete
+func (s *Server) delete(sess *session, params map[string]interface{}) (interface{}, *rpcError) {
+	trans, rpcErr := s.writeTransaction(sess, params)
+
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+
+	path, rpcErr := pathParam(params)
+
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+
+	if !trans.work.exists(path) {
+		return nil, newRpcError("data.not_found", "Element does not exist")
+	}
+
+	trans.work.delete(path)
+
+	return map[string]interface{}{}, nil
+
+}
+
+// Method to handle exists
+func (s *Server) exists(sess *session, params map[string]interface{}) (interface{}, *rpcError) {
+	trans, rpcErr := s.transaction(sess, params)
+
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+
+	path, rpcErr := pathParam(params)
+
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+
+	return map[string]interface{}{"exists": trans.work.exists(path)}, nil
+
+}
+
+// Method to handle show_config
+func (s *Server) showConfig(sess *session, params map[string]interface{}) (interface{}, *rpcError) {
+	trans, rpcErr := s.transaction(sess, params)
+
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+
+	path, rpcErr := pathParam(params)
+
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+
+	if resultAs, _ := params["result_as"].(string); resultAs != "" && resultAs != "string" {
+		return nil, invalidParams("result_as")
+	}
+
+	return map[string]interface{}{"config": trans.work.showConfig(path)}, nil
+
+}
+
+// Method to handle is_trans_modified
+func (s *Server) isTransModified(sess *session, params map[string]interface{}) (interface{}, *rpcError) {
+	trans, rpcErr := s.transaction(sess, params)
+
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+
+	return map[string]interface{}{"modified": len(trans.base.diff(trans.work)) > 0}, nil
+
+}
+
+// Method to handle get_trans_changes
+func (s *Server) getTransChanges(sess *session, params map[string]interface{}) (interface{}, *rpcError) {
+	trans, rpcErr := s.transaction(sess, params)
+
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+
+	changes := trans.base.diff(trans.work)
+
+	if changes == nil {
+		changes = []change{}
+	}
+
+	return map[string]interface{}{"changes": changes}, nil
+
+}
+
+// Method to handle validate_commit
+func (s *Server) validateCommit(sess *session, params map[string]interface{}) (interface{}, *rpcError) {
+	_, rpcErr := s.writeTransaction(sess, params)
+
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+
+	return map[string]interface{}{}, nil
+
+}
+
+// Method to handle commit
+func (s *Server) commit(sess *session, params map[string]interface{}) (interface{}, *rpcError) {
+	trans, rpcErr := s.writeTransaction(sess, params)
+
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+
+	changes := trans.base.diff(trans.work)
+
+	flags, _ := params["flags"].([]interface{})
+
+	for _, flag := range flags {
+		flagString, _ := flag.(string)
+
+		if !strings.HasPrefix(flagString, "dry-run=") {
+			continue
+		}
+
+		format := strings.TrimPrefix(flagString, "dry-run=")
+
+		switch format {
+		case "cli":
+			data := map[string]interface{}{"local-node": map[string]interface{}{"data": cliDiff(trans.base, changes)}}
+			return map[string]interface{}{"dry_run_result": map[string]interface{}{"cli": data}}, nil
+		case "native":
+			data := map[string]interface{}{"device": nativeDiff(changes)}
+			return map[string]interface{}{"dry_run_result": map[string]interface{}{"native": data}}, nil
+		default:
+			return nil, invalidParams("flags")
+		}
+	}
+
+	old := s.running.copy()
+	s.running.apply(changes)
+	s.notifyChanges(sess, trans.db, old, changes)
+
+	// The transaction carries on from the new running datastore
+	trans.base = s.running.copy()
+	trans.work = s.running.copy()
+
+	return map[string]interface{}{}, nil
+
+}
+
+// diffNode is a node of a cli dry-run diff
+type diffNode struct {
+	marker   string
+	lines    []string
+	children map[string]*diffNode
+}
+
+// Method to get a child node, it is added when missing
+//   :values name: The name of the child like device r1
+func (n *diffNode) child(name string) *diffNode {
+	if n.children == nil {
+		n.children = make(map[string]*diffNode)
+	}
+
+	if _, ok := n.children[name]; !ok {
+		n.children[name] = &diffNode{marker: " "}
+	}
+
+	return n.children[name]
+
+}
+
+// Method to write the node in the curly brace cli style
+//   :values sb: The strings.Builder to write to
+//   :values depth: The depth of the node
+func (n *diffNode) render(sb *strings.Builder, depth int) {
+	indent := strings.Repeat(" ", 4*depth)
+
+	for _, line := range n.lines {
+		sb.WriteString(line[:1] + indent + line[1:] + "\n")
+	}
+
+	names := make([]string, 0, len(n.children))
+	for name := range n.children {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		child := n.children[name]
+		sb.WriteString(child.marker + indent + name + " {\n")
+		child.render(sb, depth+1)
+		sb.WriteString(child.marker + indent + "}\n")
+	}
+
+}
+
+// cliElement turns a keypath element into its cli form, device{r1} is device r1
+//   :values element: A keypath element
+func cliElement(element string) string {
+	if match := keyedElement.FindStringSubmatch(element); match != nil {
+		return match[1] + " " + match[2]
+	}
+
+	return element
+
+}
+
+// cliDiff renders changes like a cli dry-run
+//   :values base: The datastore the changes were made on
+//   :values changes: The changes
+func cliDiff(base *datastore, changes []change) string {
+	root := &diffNode{}
+
+	for _, c := range changes {
+		elements := splitKeypath(c.Keypath)
+		node := root
+
+		for _, element := range elements[:len(elements)-1] {
+			node = node.child(cliElement(element))
+		}
+
+		last := cliElement(elements[len(elements)-1])
+		old, hasOld := base.leaves[c.Keypath]
+
+		switch c.Op {
+		case "created":
+			node.child(last).marker = "+"
+		case "deleted":
+			if hasOld {
+				node.lines = append(node.lines, fmt.Sprintf("-%s %s;", last, old))
+			} else {
+				node.child(last).marker = "-"
+			}
+		case "value_set":
+			if hasOld {
+				node.lines = append(node.lines, fmt.Sprintf("-%s %s;", last, old))
+			}
+			node.lines = append(node.lines, fmt.Sprintf("+%s %s;", last, c.Value))
+		}
+	}
+
+	var sb strings.Builder
+
+	root.render(&sb, 0)
+
+	return sb.String()
+
+}
+
+// nativeDiff renders changes under /devices/device{name}/config like a native dry-run
+//   :values changes: The changes
+func nativeDiff(changes []change) []map[string]interface{} {
+	devices := make(map[string]string)
+
+	for _, c := range changes {
+		elements := splitKeypath(c.Keypath)
+
+		if len(elements) < 4 || elements[0] != "devices" || elements[2] != "config" {
+			continue
+		}
+
+		match := keyedElement.FindStringSubmatch(elements[1])
+
+		if match == nil {
+			continue
+		}
+
+		var words []string
+		for _, element := range elements[3:] {
+			words = append(words, cliElement(element))
+		}
+		command := strings.Join(words, " ")
+
+		switch c.Op {
+		case "deleted":
+			command = "no " + command
+		case "value_set":
+			command += " " + c.Value
+		}
+
+		devices[match[2]] += command + "\n"
+	}
+
+	result := []map[string]interface{}{}
+
+	for _, name := range sortedKeys(devices) {
+		result = append(result, map[string]interface{}{"name": name, "data": devices[name]})
+	}
+
+	return result
+
+}
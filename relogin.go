@@ -0,0 +1,245 @@
+package nsojsonrpcrequestergo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrTransactionLost is returned when a read_write transaction was lost with an expired session
+// the changes in it are gone so a new transaction has to be started
+var ErrTransactionLost = errors.New("nso read_write transaction was lost when the session expired")
+
+// idempotentMethods are the JSON-RPC methods that only read, so sending them again is safe
+var idempotentMethods = map[string]bool{
+	"deref":                     true,
+	"eval_xpath":                true,
+	"exists":                    true,
+	"exists_running_to_startup": true,
+	"get_case":                  true,
+	"get_leafref_values":        true,
+	"get_list_keys":             true,
+	"get_rollback":              true,
+	"get_rollbacks":             true,
+	"get_schema":                true,
+	"get_service_points":        true,
+	"get_subscriptions":         true,
+	"get_system_setting":        true,
+	"get_template_variables":    true,
+	"get_trans":                 true,
+	"get_trans_changes":         true,
+	"get_trans_conflicts":       true,
+	"get_value":                 true,
+	"get_values":                true,
+	"is_trans_modified":         true,
+	"query":                     true,
+	"show_config":               true,
+}
+
+// isIdempotent checks if a JSON-RPC method only reads
+//   :values method: The JSON-RPC method
+func isIdempotent(method string) bool {
+	return idempotentMethods[method]
+
+}
+
+// Method to set if the session logs in again when NSO says it is invalid
+// read transactions are started again and idempotent requests are sent once more
+// a request on a lost read_write transaction returns ErrTransactionLost
+//   :values enabled: true to login again false to not
+func (nsoJson *nsoJsonConnection) SetAutoRelogin(enabled bool) {
+	nsoJson.autoRelogin = enabled
+
+}
+
+// Method to keep track of a transaction so it can be started again after a re-login
+//   :values trans: The *Transaction
+func (nsoJson *nsoJsonConnection) registerTransaction(trans *Transaction) {
+	nsoJson.sessionLock.Lock()
+	defer nsoJson.sessionLock.Unlock()
+
+	nsoJson.transactions[trans] = true
+
+}
+
+// Method to stop keeping track of a transaction
+//   :values trans: The *Transaction
+func (nsoJson *nsoJsonConnection) unregisterTransaction(trans *Transaction) {
+	nsoJson.sessionLock.Lock()
+	defer nsoJson.sessionLock.Unlock()
+
+	delete(nsoJson.transactions, trans)
+
+}
+
+// Method to check if a request is sent on a lost read_write transaction
+//   :values trans: The *Transaction the request is sent on, nil if it is not on one
+func (nsoJson *nsoJsonConnection) checkTransactionLost(trans *Transaction) error {
+	if trans == nil {
+		return nil
+	}
+
+	nsoJson.sessionLock.Lock()
+	defer nsoJson.sessionLock.Unlock()
+
+	if trans.lost {
+		return fmt.Errorf("%w, handle %v", ErrTransactionLost, trans.th)
+	}
+
+	return nil
+
+}
+
+// Method to check if a failed request should cause a re-login
+//   :values param: The rpcParam that failed
+//   :values err: The error it failed with
+func (nsoJson *nsoJsonConnection) canRelogin(param rpcParam, err error) bool {
+	if nsoJson.autoRelogin != true || !errors.Is(err, ErrInvalidSession) {
+		return false
+	}
+
+	return param["method"] != "login" && param["method"] != "logout"
+
+}
+
+// Method to login again, start the read transactions again, and retry an idempotent request once
+//   :values ctx: A context.Context to cancel the request
+//   :values trans: The *Transaction the request was sent on, nil if it was not on one
+//   :values param: The rpcParam that failed
+//   :values generation: The session generation the request was sent with
+//   :values sendErr: The error the request failed with
+func (nsoJson *nsoJsonConnection) reloginAndRetry(ctx context.Context, trans *Transaction, param rpcParam, generation int, sendErr error) (*NsoJsonResponse, error) {
+	err := nsoJson.relogin(ctx, generation)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// A read_write transaction lost with the session is reported even to the request that found it
+	err = nsoJson.checkTransactionLost(trans)
+
+	if err != nil {
+		return nil, err
+	}
+
+	method, _ := param["method"].(string)
+
+	// A new_trans rejected for the session started nothing so it is safe to send again
+	if !isIdempotent(method) && method != "new_trans" {
+		return nil, sendErr
+	}
+
+	retryParam := param
+
+	if trans != nil {
+		retryParam = nsoJson.withTransactionHandle(param, trans)
+	}
+
+	nsoJson.logger.Info("retrying nso json-rpc request after a re-login", "method", method)
+
+	response, err := nsoJson.sendPostOnce(ctx, retryParam)
+
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+
+}
+
+// Method to login again unless another request already did
+//   :values ctx: A context.Context to cancel the request
+//   :values generation: The session generation the failed request was sent with
+func (nsoJson *nsoJsonConnection) relogin(ctx context.Context, generation int) error {
+	nsoJson.reloginLock.Lock()
+	defer nsoJson.reloginLock.Unlock()
+
+	if nsoJson.sessionGeneration() != generation {
+		return nil
+	}
+
+	nsoJson.logger.Info("nso session is invalid, logging in again")
+
+	err := nsoJson.NsoLoginContext(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	err = nsoJson.restartTransactions(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+
+}
+
+// Method to start the read transactions again after a re-login
+// read_write transactions can not be started again, they are marked as lost
+//   :values ctx: A context.Context to cancel the request
+func (nsoJson *nsoJsonConnection) restartTransactions(ctx context.Context) error {
+	nsoJson.sessionLock.Lock()
+	var transactions []*Transaction
+	for trans := range nsoJson.transactions {
+		transactions = append(transactions, trans)
+	}
+	nsoJson.sessionLock.Unlock()
+
+	for _, trans := range transactions {
+		if trans.mode != "read" {
+			nsoJson.logger.Warn("nso read_write transaction was lost", "th", trans.handle())
+
+			nsoJson.sessionLock.Lock()
+			trans.lost = true
+			delete(nsoJson.transactions, trans)
+			nsoJson.sessionLock.Unlock()
+
+			continue
+		}
+
+		response, err := nsoJson.sendPostOnce(ctx, newTransactionParam(trans.db, trans.mode, trans.confMode, trans.tag, trans.onPendingChanges))
+
+		if err != nil {
+			return err
+		}
+
+		trans.setHandle(response.GetTransactionHandle())
+	}
+
+	return nil
+
+}
+
+// Method to copy a rpcParam with the current handle of the transaction it is sent on
+//   :values param: A rpcParam
+//   :values trans: The *Transaction the request is sent on
+func (nsoJson *nsoJsonConnection) withTransactionHandle(param rpcParam, trans *Transaction) rpcParam {
+	params, ok := param["params"].(map[string]interface{})
+
+	if !ok {
+		return param
+	}
+
+	if _, ok := params["th"]; !ok {
+		return param
+	}
+
+	th := trans.handle()
+
+	retryParams := make(map[string]interface{})
+	for key, value := range params {
+		retryParams[key] = value
+	}
+	retryParams["th"] = th
+
+	retryParam := make(rpcParam)
+	for key, value := range param {
+		retryParam[key] = value
+	}
+	retryParam["params"] = retryParams
+
+	return retryParam
+
+}
@@ -0,0 +1,326 @@
+package nsojsonrpcrequestergo
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+// testSessionServer is a JSON-RPC server with a session that can be expired
+// with thPerSession the transaction handles start at 1 again in each session like after a NSO restart
+type testSessionServer struct {
+	lock         sync.Mutex
+	session      int
+	th           int
+	thPerSession bool
+	called       []string
+}
+
+// Method to expire the current session
+func (s *testSessionServer) expire() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.session++
+
+	if s.thPerSession {
+		s.th = 0
+	}
+}
+
+func (s *testSessionServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	var body struct {
+		ID     int                    `json:"id"`
+		Method string                 `json:"method"`
+		Params map[string]interface{} `json:"params"`
+	}
+	_ = json.NewDecoder(r.Body).Decode(&body)
+
+	call := body.Method
+	if th, ok := body.Params["th"]; ok {
+		call = fmt.Sprintf("%s %v", body.Method, th)
+	}
+	s.called = append(s.called, call)
+
+	sessionID := fmt.Sprintf("s%d", s.session)
+	result := "{}"
+
+	switch body.Method {
+	case "login":
+		http.SetCookie(w, &http.Cookie{Name: "sessionid", Value: sessionID})
+	default:
+		if cookie, err := r.Cookie("sessionid"); err != nil || cookie.Value != sessionID {
+			_, _ = w.Write([]byte(fmt.Sprintf(`{"jsonrpc": "2.0", "id": %d, "error": {"code": -32000, "type": "session.invalid_sessionid", "message": "Invalid sessionid"}}`, body.ID)))
+			return
+		}
+		if body.Method == "new_trans" {
+			s.th++
+			result = fmt.Sprintf(`{"th": %d}`, s.th)
+		}
+	}
+
+	_, _ = w.Write([]byte(fmt.Sprintf(`{"jsonrpc": "2.0", "id": %d, "result": %s}`, body.ID, result)))
+}
+
+func Test_SetAutoRelogin(t *testing.T) {
+	sessionServer := &testSessionServer{}
+	server := httptest.NewServer(sessionServer)
+	defer server.Close()
+
+	config, _ := NewNsoJsonRpcConfig("http", "127.0.0.1", testServerPort(server), "admin", "admin", false)
+	config.SetAutoRelogin(true)
+
+	_ = config.NsoLogin()
+	readTrans, _ := config.NewTransaction("read", "private", "", "reuse")
+	writeTrans, _ := config.NewTransaction("read_write", "private", "", "reuse")
+
+	sessionServer.expire()
+
+	_, err := readTrans.GetValue("/ncs:devices/device{r1}/address", false)
+	if err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+	if readTrans.Handle() != 3 {
+		t.Errorf("expected %v got %v", 3, readTrans.Handle())
+	}
+
+	_, err = writeTrans.SetValue("/ncs:devices/device{r1}/address", "10.0.0.1", false)
+	if !errors.Is(err, ErrTransactionLost) {
+		t.Errorf("expected error %v got %v", ErrTransactionLost, err)
+	}
+
+	sessionServer.expire()
+
+	// commit is not idempotent so it is not sent again
+	_, err = readTrans.Commit(false, "", false)
+	if !errors.Is(err, ErrInvalidSession) {
+		t.Errorf("expected error %v got %v", ErrInvalidSession, err)
+	}
+
+	expect := []string{"login", "new_trans", "new_trans", "get_value 1", "login", "new_trans", "get_value 3", "commit 3", "login", "new_trans"}
+	if !reflect.DeepEqual(sessionServer.called, expect) {
+		t.Errorf("expected %v got %v", expect, sessionServer.called)
+	}
+
+}
+
+func Test_SetAutoRelogin_disabled(t *testing.T) {
+	sessionServer := &testSessionServer{}
+	server := httptest.NewServer(sessionServer)
+	defer server.Close()
+
+	config, _ := NewNsoJsonRpcConfig("http", "127.0.0.1", testServerPort(server), "admin", "admin", false)
+
+	_ = config.NsoLogin()
+	trans, _ := config.NewTransaction("read", "private", "", "reuse")
+
+	sessionServer.expire()
+
+	_, err := trans.GetValue("/ncs:devices/device{r1}/address", false)
+	if !errors.Is(err, ErrInvalidSession) {
+		t.Errorf("expected error %v got %v", ErrInvalidSession, err)
+	}
+
+	expect := []string{"login", "new_trans", "get_value 1"}
+	if !reflect.DeepEqual(sessionServer.called, expect) {
+		t.Errorf("expected %v got %v", expect, sessionServer.called)
+	}
+
+}
+
+func Test_SetAutoRelogin_readWriteFirst(t *testing.T) {
+	sessionServer := &testSessionServer{}
+	server := httptest.NewServer(sessionServer)
+	defer server.Close()
+
+	config, _ := NewNsoJsonRpcConfig("http", "127.0.0.1", testServerPort(server), "admin", "admin", false)
+	config.SetAutoRelogin(true)
+
+	_ = config.NsoLogin()
+	writeTrans, _ := config.NewTransaction("read_write", "private", "", "reuse")
+
+	sessionServer.expire()
+
+	// The request that finds the expired session gets ErrTransactionLost too
+	_, err := writeTrans.SetValue("/ncs:devices/device{r1}/address", "10.0.0.1", false)
+	if !errors.Is(err, ErrTransactionLost) {
+		t.Errorf("expected error %v got %v", ErrTransactionLost, err)
+	}
+
+	_, err = writeTrans.SetValue("/ncs:devices/device{r1}/address", "10.0.0.1", false)
+	if !errors.Is(err, ErrTransactionLost) {
+		t.Errorf("expected error %v got %v", ErrTransactionLost, err)
+	}
+
+	expect := []string{"login", "new_trans", "set_value 1", "login"}
+	if !reflect.DeepEqual(sessionServer.called, expect) {
+		t.Errorf("expected %v got %v", expect, sessionServer.called)
+	}
+
+}
+
+func Test_SetAutoRelogin_newTransactionSameHandle(t *testing.T) {
+	sessionServer := &testSessionServer{thPerSession: true}
+	server := httptest.NewServer(sessionServer)
+	defer server.Close()
+
+	config, _ := NewNsoJsonRpcConfig("http", "127.0.0.1", testServerPort(server), "admin", "admin", false)
+	config.SetAutoRelogin(true)
+
+	_ = config.NsoLogin()
+	lostTrans, _ := config.NewTransaction("read_write", "private", "", "reuse")
+
+	sessionServer.expire()
+
+	_, err := lostTrans.SetValue("/ncs:devices/device{r1}/address", "10.0.0.1", false)
+	if !errors.Is(err, ErrTransactionLost) {
+		t.Errorf("expected error %v got %v", ErrTransactionLost, err)
+	}
+
+	// The new session hands out handle 1 again
+	trans, err := config.NewTransaction("read_write", "private", "", "reuse")
+	if err != nil || trans.Handle() != lostTrans.Handle() {
+		t.Fatalf("expected handle %v got %v %v", lostTrans.Handle(), trans.Handle(), err)
+	}
+
+	_, err = trans.SetValue("/ncs:devices/device{r1}/address", "10.0.0.1", false)
+	if err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	_, err = lostTrans.SetValue("/ncs:devices/device{r1}/address", "10.0.0.1", false)
+	if !errors.Is(err, ErrTransactionLost) {
+		t.Errorf("expected error %v got %v", ErrTransactionLost, err)
+	}
+
+}
+
+func Test_SetAutoRelogin_lostTransactionMethods(t *testing.T) {
+	sessionServer := &testSessionServer{}
+	server := httptest.NewServer(sessionServer)
+	defer server.Close()
+
+	config, _ := NewNsoJsonRpcConfig("http", "127.0.0.1", testServerPort(server), "admin", "admin", false)
+	config.SetAutoRelogin(true)
+
+	_ = config.NsoLogin()
+	readTrans, _ := config.NewTransaction("read", "private", "", "reuse")
+	writeTrans, _ := config.NewTransaction("read_write", "private", "", "reuse")
+
+	sessionServer.expire()
+
+	// The read transaction finds the expired session so the read_write one is lost without being used
+	_, _ = readTrans.GetValue("/ncs:devices/device{r1}/address", false)
+
+	sessionServer.lock.Lock()
+	before := len(sessionServer.called)
+	sessionServer.lock.Unlock()
+
+	scenarios := map[string]func() error{
+		"SetValue": func() error {
+			_, err := writeTrans.SetValue("/ncs:devices/device{r1}/address", "10.0.0.1", false)
+			return err
+		},
+		"GetValue": func() error {
+			_, err := writeTrans.GetValue("/ncs:devices/device{r1}/address", false)
+			return err
+		},
+		"Create": func() error {
+			_, err := writeTrans.Create("/ncs:devices/device{r2}")
+			return err
+		},
+		"Load": func() error {
+			_, err := writeTrans.Load("<config/>", "/", "xml", "merge")
+			return err
+		},
+		"IsModified": func() error {
+			_, err := writeTrans.IsModified()
+			return err
+		},
+		"StartQuery": func() error {
+			queryObject, _ := NewQueryObject("/ncs:devices/device", "", []string{"name"}, 10, 0, nil, "", false, "", "string")
+			return writeTrans.StartQuery(queryObject)
+		},
+		"Batch": func() error {
+			batch := writeTrans.NewBatch()
+			batch.Exists("/ncs:devices/device{r1}")
+			_, err := batch.Send()
+			return err
+		},
+		"DeleteTransaction": func() error {
+			return writeTrans.DeleteTransaction()
+		},
+	}
+
+	for name, scenario := range scenarios {
+		err := scenario()
+		if !errors.Is(err, ErrTransactionLost) {
+			t.Errorf("expected error %v for %v got %v", ErrTransactionLost, name, err)
+		}
+	}
+
+	// Nothing is sent with the dead handle
+	sessionServer.lock.Lock()
+	defer sessionServer.lock.Unlock()
+	if len(sessionServer.called) != before {
+		t.Errorf("expected %v got %v", "no requests", sessionServer.called[before:])
+	}
+
+}
+
+func Test_SetAutoRelogin_concurrentHandle(t *testing.T) {
+	sessionServer := &testSessionServer{}
+	server := httptest.NewServer(sessionServer)
+	defer server.Close()
+
+	config, _ := NewNsoJsonRpcConfig("http", "127.0.0.1", testServerPort(server), "admin", "admin", false)
+	config.SetAutoRelogin(true)
+
+	_ = config.NsoLogin()
+	trans, _ := config.NewTransaction("read", "private", "", "reuse")
+
+	done := make(chan struct{})
+	var wait sync.WaitGroup
+	wait.Add(2)
+
+	go func() {
+		defer wait.Done()
+		for j := 0; j < 20; j++ {
+			_, _ = trans.GetValue("/ncs:devices/device{r1}/address", false)
+		}
+	}()
+
+	go func() {
+		defer wait.Done()
+		for {
+			select {
+			case <-done:
+				return
+			default:
+				_ = trans.Handle()
+			}
+		}
+	}()
+
+	// Run with -race, the handle is replaced by the re-logins while it is read
+	for i := 0; i < 5; i++ {
+		sessionServer.expire()
+		_, _ = trans.Exists("/ncs:devices/device{r1}")
+	}
+
+	close(done)
+	wait.Wait()
+
+	_, err := trans.GetValue("/ncs:devices/device{r1}/address", false)
+	if err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+
+}
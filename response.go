@@ -1,19 +1,21 @@
 package nsojsonrpcrequestergo
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
-	"github.com/imroc/req"
 	"strings"
 )
 
 // NsoJsonResponse holds a NSO JSON RPC Response
 // The tags help to convert fields to lowercase
 type NsoJsonResponse struct {
-	Jsonrpc string                 `json:"jsonrpc"`
-	Result  map[string]interface{} `json:"result"`
-	ID      int                    `json:"id"`
-	Error   map[string]interface{} `json:"error"`
+	Jsonrpc    string                 `json:"jsonrpc"`
+	Result     map[string]interface{} `json:"result"`
+	ID         int                    `json:"id"`
+	Error      map[string]interface{} `json:"error"`
+	StatusCode int                    `json:"-"`
+	body       []byte
 }
 
 // Constructor to create a new NsoJsonResponse struct
@@ -23,26 +25,88 @@ func NewNsoJsonResponse() *NsoJsonResponse {
 
 }
 
-// Method to convert JSON Response Body to a map
-//   :values response: *req.Resp
-func (r *NsoJsonResponse) ResponseToStruct(response *req.Resp) (*NsoJsonResponse, error) {
-	err := response.ToJSON(&r)
+// Constructor to create a new NsoJsonResponse struct from a raw response body
+//   :values statusCode: The HTTP status code
+//   :values body: The raw response body
+func newNsoJsonResponseFromBody(statusCode int, body []byte) (*NsoJsonResponse, error) {
+	r := &NsoJsonResponse{StatusCode: statusCode, body: body}
+
+	if len(strings.TrimSpace(string(body))) == 0 {
+		return r, nil
+	}
+
+	var envelope struct {
+		Jsonrpc string                 `json:"jsonrpc"`
+		Result  json.RawMessage        `json:"result"`
+		ID      int                    `json:"id"`
+		Error   map[string]interface{} `json:"error"`
+	}
+
+	err := json.Unmarshal(body, &envelope)
 
 	if err != nil {
 		return r, err
 	}
 
+	r.Jsonrpc = envelope.Jsonrpc
+	r.ID = envelope.ID
+	r.Error = envelope.Error
+
+	// Some methods like comet return an array, use ToJSON to read those
+	if strings.HasPrefix(strings.TrimSpace(string(envelope.Result)), "{") {
+		err = json.Unmarshal(envelope.Result, &r.Result)
+
+		if err != nil {
+			return r, err
+		}
+	}
+
 	return r, nil
 
 }
 
+// Method to get the raw response body
+func (r *NsoJsonResponse) Bytes() []byte {
+	return r.body
+
+}
+
+// Method to get the raw response body as a string
+func (r *NsoJsonResponse) String() string {
+	return string(r.body)
+
+}
+
+// Method to convert the raw response body to a struct or map
+//   :values v: A pointer to decode into
+func (r *NsoJsonResponse) ToJSON(v interface{}) error {
+	return json.Unmarshal(r.body, v)
+
+}
+
+// Method to get the error member as a *NsoRpcError
+// nil is returned if the response holds no error
+func (r *NsoJsonResponse) GetRpcError() *NsoRpcError {
+	if r.Error == nil {
+		return nil
+	}
+
+	raw, err := json.Marshal(r.Error)
+
+	if err != nil {
+		return nil
+	}
+
+	rpcErr, _ := newNsoRpcError("", r.ID, raw)
+
+	return rpcErr
+
+}
+
 // Method to get the transaction handle
-//   :values response: *req.Resp
-func (r *NsoJsonResponse) GetTransactionHandle(response *req.Resp) float64 {
+func (r *NsoJsonResponse) GetTransactionHandle() float64 {
 	var th float64
 
-	_ = response.ToJSON(&r)
-
 	for key, value := range r.Result {
 		if key == "th" {
 			th = value.(float64)
@@ -55,12 +119,9 @@ func (r *NsoJsonResponse) GetTransactionHandle(response *req.Resp) float64 {
 }
 
 // Method to get the query handle
-//   :values response: *req.Resp
-func (r *NsoJsonResponse) GetQueryHandle(response *req.Resp) float64 {
+func (r *NsoJsonResponse) GetQueryHandle() float64 {
 	var qh float64
 
-	_ = response.ToJSON(&r)
-
 	for key, value := range r.Result {
 		if key == "qh" {
 			qh = value.(float64)
@@ -83,13 +144,7 @@ func (r *NsoJsonResponse) fixQueryResults(result string) []string {
 }
 
 // Method to get the query results
-//   :values response: *req.Resp
-func (r *NsoJsonResponse) GetQueryResults(response *req.Resp) ([]string, error) {
-	_, err := r.ResponseToStruct(response)
-	if err != nil {
-		return []string{}, err
-	}
-
+func (r *NsoJsonResponse) GetQueryResults() ([]string, error) {
 	resutlData := r.Result
 	for k, v := range resutlData {
 		if k == "results" {
@@ -102,14 +157,7 @@ func (r *NsoJsonResponse) GetQueryResults(response *req.Resp) ([]string, error)
 }
 
 // Method to get the comet handle
-//   :values response: *req.Resp
-func (r *NsoJsonResponse) GetCometHandle(response *req.Resp) (string, error) {
-	_, err := r.ResponseToStruct(response)
-
-	if err != nil {
-		return "", err
-	}
-
+func (r *NsoJsonResponse) GetCometHandle() (string, error) {
 	resutlData := r.Result
 	for k, v := range resutlData {
 		if k == "handle" {
@@ -0,0 +1,192 @@
+package nsojsonrpcrequestergo
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand"
+	"time"
+)
+
+// RetryOn selects the failures a RetryPolicy retries, values can be combined with |
+type RetryOn int
+
+const (
+	// RetryOnNetworkError retries when the NSO Server could not be reached
+	RetryOnNetworkError RetryOn = 1 << iota
+	// RetryOnServerUnavailable retries on HTTP 429, 502, 503, and 504
+	RetryOnServerUnavailable
+	// RetryOnLockConflict retries when NSO reports a lock conflict
+	RetryOnLockConflict
+)
+
+// retryStatusCodes are the HTTP status codes RetryOnServerUnavailable retries
+var retryStatusCodes = map[int]bool{
+	429: true,
+	502: true,
+	503: true,
+	504: true,
+}
+
+// RetryPolicy holds how failed requests are sent again
+// only idempotent methods are retried unless a method is in Methods
+type RetryPolicy struct {
+	// MaxAttempts is the most times a request is sent, 1 is no retry
+	MaxAttempts int
+	// InitialBackoff is the wait before the first retry
+	InitialBackoff time.Duration
+	// MaxBackoff is the longest wait between retries, 0 for no limit
+	MaxBackoff time.Duration
+	// Multiplier grows the wait after each retry, 0 is the same as 1
+	Multiplier float64
+	// Jitter is the fraction of the wait that is random, 0 to 1
+	Jitter float64
+	// RetryOn is the failures that are retried
+	RetryOn RetryOn
+	// Methods are non-idempotent JSON-RPC methods that are allowed to be retried like commit
+	Methods []string
+}
+
+// Constructor for a RetryPolicy with 3 attempts that retries network errors and an unavailable server
+func DefaultRetryPolicy() *RetryPolicy {
+
+	return &RetryPolicy{
+		MaxAttempts:    3,
+		InitialBackoff: 500 * time.Millisecond,
+		MaxBackoff:     10 * time.Second,
+		Multiplier:     2,
+		Jitter:         0.2,
+		RetryOn:        RetryOnNetworkError | RetryOnServerUnavailable,
+	}
+
+}
+
+// Method to validate the policy
+func (policy *RetryPolicy) Validate() error {
+	if policy.MaxAttempts < 1 {
+		return errors.New("max attempts must be at least 1")
+	}
+
+	if policy.InitialBackoff < 0 || policy.MaxBackoff < 0 {
+		return errors.New("backoff can not be negative")
+	}
+
+	if policy.Multiplier != 0 && policy.Multiplier < 1 {
+		return errors.New("multiplier can not be less than 1")
+	}
+
+	if policy.Jitter < 0 || policy.Jitter > 1 {
+		return errors.New("jitter must be between 0 and 1")
+	}
+
+	return nil
+
+}
+
+// Method to check if a method can be retried
+//   :values method: The JSON-RPC method
+func (policy *RetryPolicy) allowsMethod(method string) bool {
+	// A login that did not reach NSO can always be sent again
+	if isIdempotent(method) || method == "login" {
+		return true
+	}
+
+	for _, allowed := range policy.Methods {
+		if allowed == method {
+			return true
+		}
+	}
+
+	return false
+
+}
+
+// Method to check if an error is one the policy retries
+//   :values err: The error the request failed with
+func (policy *RetryPolicy) retries(err error) bool {
+	var transportErr *transportError
+	var statusErr *HTTPStatusError
+
+	switch {
+	case errors.As(err, &transportErr):
+		return policy.RetryOn&RetryOnNetworkError != 0 && !isTLSVerifyError(err)
+	case errors.As(err, &statusErr):
+		return policy.RetryOn&RetryOnServerUnavailable != 0 && retryStatusCodes[statusErr.StatusCode]
+	case errors.Is(err, ErrLockConflict):
+		return policy.RetryOn&RetryOnLockConflict != 0
+	}
+
+	return false
+
+}
+
+// Method to get the wait before a retry
+//   :values attempt: The attempt that failed, starting at 1
+func (policy *RetryPolicy) backoff(attempt int) time.Duration {
+	multiplier := policy.Multiplier
+	if multiplier == 0 {
+		multiplier = 1
+	}
+
+	wait := float64(policy.InitialBackoff) * math.Pow(multiplier, float64(attempt-1))
+
+	if policy.MaxBackoff > 0 && wait > float64(policy.MaxBackoff) {
+		wait = float64(policy.MaxBackoff)
+	}
+
+	wait -= wait * policy.Jitter * rand.Float64()
+
+	return time.Duration(wait)
+
+}
+
+// Method to set the RetryPolicy, nil turns retries off
+//   :values policy: A *RetryPolicy
+func (nsoJson *nsoJsonConnection) SetRetryPolicy(policy *RetryPolicy) error {
+	if policy != nil {
+		err := policy.Validate()
+
+		if err != nil {
+			return err
+		}
+	}
+
+	nsoJson.retryPolicy = policy
+
+	return nil
+
+}
+
+// Method to send a POST request and retry it using the RetryPolicy
+//   :values ctx: A context.Context to cancel the request
+//   :values param: A rpcParam
+func (nsoJson *nsoJsonConnection) sendPostRetry(ctx context.Context, param rpcParam) (*NsoJsonResponse, error) {
+	policy := nsoJson.retryPolicy
+	method, _ := param["method"].(string)
+
+	for attempt := 1; ; attempt++ {
+		response, err := nsoJson.sendPostOnce(ctx, param)
+
+		if err == nil {
+			return response, nil
+		}
+
+		if policy == nil || attempt >= policy.MaxAttempts || ctx.Err() != nil || !policy.allowsMethod(method) || !policy.retries(err) {
+			return response, err
+		}
+
+		wait := policy.backoff(attempt)
+
+		nsoJson.logger.Info("retrying nso json-rpc request", "method", method, "attempt", attempt+1, "wait", wait, "error", err)
+
+		timer := time.NewTimer(wait)
+
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return response, err
+		}
+	}
+
+}
@@ -0,0 +1,163 @@
+package nsojsonrpcrequestergo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// flakyTransport fails the first failures requests then answers like fakeTransport
+type flakyTransport struct {
+	lock     sync.Mutex
+	failures int
+	err      error
+	status   int
+	called   []string
+}
+
+func (f *flakyTransport) Send(ctx context.Context, request *TransportRequest) (*TransportResponse, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	var body map[string]interface{}
+	_ = json.Unmarshal(request.Body, &body)
+	f.called = append(f.called, body["method"].(string))
+
+	if f.failures > 0 {
+		f.failures--
+
+		if f.err != nil {
+			return nil, f.err
+		}
+
+		return &TransportResponse{StatusCode: f.status}, nil
+	}
+
+	reply := fmt.Sprintf(`{"jsonrpc": "2.0", "id": %v, "result": {}}`, body["id"])
+
+	return &TransportResponse{StatusCode: 200, Body: []byte(reply)}, nil
+}
+
+func Test_SetRetryPolicy(t *testing.T) {
+	policy := &RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond, RetryOn: RetryOnNetworkError | RetryOnServerUnavailable}
+	commitPolicy := &RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond, RetryOn: RetryOnServerUnavailable, Methods: []string{"commit"}}
+
+	scenarios := []struct {
+		policy    *RetryPolicy
+		transport *flakyTransport
+		method    string
+		expect    []string
+		rcvError  bool
+	}{
+		{policy: policy, transport: &flakyTransport{failures: 2, status: 503}, method: "get_value", expect: []string{"get_value", "get_value", "get_value"}, rcvError: false},
+		{policy: policy, transport: &flakyTransport{failures: 3, status: 503}, method: "get_value", expect: []string{"get_value", "get_value", "get_value"}, rcvError: true},
+		{policy: policy, transport: &flakyTransport{failures: 1, err: errors.New("connection reset")}, method: "get_value", expect: []string{"get_value", "get_value"}, rcvError: false},
+		{policy: policy, transport: &flakyTransport{failures: 1, status: 500}, method: "get_value", expect: []string{"get_value"}, rcvError: true},
+		{policy: policy, transport: &flakyTransport{failures: 1, status: 503}, method: "commit", expect: []string{"commit"}, rcvError: true},
+		{policy: commitPolicy, transport: &flakyTransport{failures: 1, status: 503}, method: "commit", expect: []string{"commit", "commit"}, rcvError: false},
+		{policy: commitPolicy, transport: &flakyTransport{failures: 1, err: errors.New("connection reset")}, method: "commit", expect: []string{"commit"}, rcvError: true},
+		{policy: nil, transport: &flakyTransport{failures: 1, status: 503}, method: "get_value", expect: []string{"get_value"}, rcvError: true},
+	}
+
+	for _, scenario := range scenarios {
+		config, _ := NewNsoJsonRpcConfigWithTransport("http", "192.168.1.1", 8080, "admin", "admin", scenario.transport)
+
+		err := config.SetRetryPolicy(scenario.policy)
+		if err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+
+		_, err = config.nsocon.sendPost(rpcParam{"jsonrpc": "2.0", "method": scenario.method})
+		if (err != nil) != scenario.rcvError {
+			t.Errorf("expected error %v got %v", scenario.rcvError, err)
+		}
+
+		if !reflect.DeepEqual(scenario.transport.called, scenario.expect) {
+			t.Errorf("expected %v got %v", scenario.expect, scenario.transport.called)
+		}
+	}
+
+}
+
+func Test_SetRetryPolicy_lockConflict(t *testing.T) {
+	var called []string
+	var lock sync.Mutex
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+
+		lock.Lock()
+		called = append(called, body["method"].(string))
+		lock.Unlock()
+
+		_, _ = w.Write([]byte(fmt.Sprintf(`{"jsonrpc": "2.0", "id": %v, "error": {"code": -32000, "type": "db.locked", "message": "locked"}}`, body["id"])))
+	}))
+	defer server.Close()
+
+	config, _ := NewNsoJsonRpcConfig("http", "127.0.0.1", testServerPort(server), "admin", "admin", false)
+	_ = config.SetRetryPolicy(&RetryPolicy{MaxAttempts: 2, RetryOn: RetryOnLockConflict, Methods: []string{"lock_db"}})
+
+	_, err := config.LockDB("running")
+	if !errors.Is(err, ErrLockConflict) {
+		t.Errorf("expected error %v got %v", ErrLockConflict, err)
+	}
+
+	expect := []string{"lock_db", "lock_db"}
+	if !reflect.DeepEqual(called, expect) {
+		t.Errorf("expected %v got %v", expect, called)
+	}
+
+}
+
+func Test_RetryPolicy_Validate(t *testing.T) {
+	scenarios := []struct {
+		policy   RetryPolicy
+		rcvError error
+	}{
+		{policy: *DefaultRetryPolicy(), rcvError: nil},
+		{policy: RetryPolicy{MaxAttempts: 0}, rcvError: errors.New("max attempts must be at least 1")},
+		{policy: RetryPolicy{MaxAttempts: 1, InitialBackoff: -1}, rcvError: errors.New("backoff can not be negative")},
+		{policy: RetryPolicy{MaxAttempts: 1, Multiplier: 0.5}, rcvError: errors.New("multiplier can not be less than 1")},
+		{policy: RetryPolicy{MaxAttempts: 1, Jitter: 2}, rcvError: errors.New("jitter must be between 0 and 1")},
+	}
+
+	for _, scenario := range scenarios {
+		err := scenario.policy.Validate()
+		if err != scenario.rcvError {
+			if err == nil || scenario.rcvError == nil || err.Error() != scenario.rcvError.Error() {
+				t.Errorf("expected error %v got %v", scenario.rcvError, err)
+			}
+		}
+	}
+
+}
+
+func Test_RetryPolicy_backoff(t *testing.T) {
+	policy := &RetryPolicy{MaxAttempts: 5, InitialBackoff: 100 * time.Millisecond, MaxBackoff: time.Second, Multiplier: 2, Jitter: 0.5}
+
+	scenarios := []struct {
+		attempt  int
+		min, max time.Duration
+	}{
+		{attempt: 1, min: 50 * time.Millisecond, max: 100 * time.Millisecond},
+		{attempt: 2, min: 100 * time.Millisecond, max: 200 * time.Millisecond},
+		{attempt: 3, min: 200 * time.Millisecond, max: 400 * time.Millisecond},
+		{attempt: 10, min: 500 * time.Millisecond, max: time.Second},
+	}
+
+	for _, scenario := range scenarios {
+		wait := policy.backoff(scenario.attempt)
+		if wait < scenario.min || wait > scenario.max {
+			t.Errorf("expected %v to %v got %v", scenario.min, scenario.max, wait)
+		}
+	}
+
+}
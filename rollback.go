@@ -0,0 +1,85 @@
+package nsojsonrpcrequestergo
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// rollbackDateLayout is the layout NSO uses for the rollback date
+const rollbackDateLayout = "2006-01-02 15:04:05"
+
+// Rollback holds the metadata of a NSO rollback file
+type Rollback struct {
+	Number    int    `json:"nr"`
+	FixedID   int    `json:"fixed_nr"`
+	User      string `json:"creator"`
+	Timestamp string `json:"date"`
+	Via       string `json:"via"`
+	Label     string `json:"label"`
+	Comment   string `json:"comment"`
+}
+
+// Method to parse the rollback timestamp
+func (r *Rollback) Time() (time.Time, error) {
+	return time.Parse(rollbackDateLayout, r.Timestamp)
+
+}
+
+// newRollbacks decodes the result of get_rollbacks
+//   :values response: *NsoJsonResponse from get_rollbacks
+func newRollbacks(response *NsoJsonResponse) ([]Rollback, error) {
+	var body struct {
+		Result struct {
+			Rollbacks []Rollback `json:"rollbacks"`
+		} `json:"result"`
+	}
+
+	err := response.ToJSON(&body)
+
+	if err != nil {
+		return []Rollback{}, err
+	}
+
+	return body.Result.Rollbacks, nil
+
+}
+
+// newRollbackContent decodes the result of get_rollback
+//   :values response: *NsoJsonResponse from get_rollback
+func newRollbackContent(response *NsoJsonResponse) (string, error) {
+	var body struct {
+		Result map[string]json.RawMessage `json:"result"`
+	}
+
+	err := response.ToJSON(&body)
+
+	if err != nil {
+		return "", err
+	}
+
+	var content string
+
+	err = json.Unmarshal(body.Result["rollback"], &content)
+
+	if err != nil {
+		return "", errors.New("could not find rollback")
+	}
+
+	return content, nil
+
+}
+
+// FindRollbackByLabel finds the newest rollback with a label
+//   :values rollbacks: Rollbacks from GetRollbacks, newest first
+//   :values label: The label given to the commit
+func FindRollbackByLabel(rollbacks []Rollback, label string) (Rollback, error) {
+	for _, rollback := range rollbacks {
+		if rollback.Label == label {
+			return rollback, nil
+		}
+	}
+
+	return Rollback{}, errors.New("could not find a rollback with that label")
+
+}
@@ -0,0 +1,60 @@
+package nsojsonrpcrequestergo
+
+import (
+	"testing"
+)
+
+func TestNsoJsonRpcConfig_GetRollbacks(t *testing.T) {
+	server, called := testJsonRpcServer(map[string]string{
+		"get_rollbacks": `{"rollbacks": [{"nr": 0, "fixed_nr": 10012, "creator": "admin", "date": "2026-10-18 10:15:00", "via": "system", "label": "run-42", "comment": "pipeline run 42"}, {"nr": 1, "fixed_nr": 10011, "creator": "oper", "date": "2026-10-17 09:00:00", "via": "cli", "label": "", "comment": ""}]}`,
+		"get_rollback":  `{"rollback": "# Created by: admin\n"}`,
+		"new_trans":     `{"th": 4}`,
+	})
+	defer server.Close()
+
+	config, _ := NewNsoJsonRpcConfig("http", "127.0.0.1", testServerPort(server), "admin", "admin", false)
+	_ = config.NsoLogin()
+
+	rollbacks, err := config.GetRollbacks()
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if len(rollbacks) != 2 {
+		t.Fatalf("expected %v got %v", 2, len(rollbacks))
+	}
+	if rollbacks[0].FixedID != 10012 || rollbacks[0].User != "admin" {
+		t.Errorf("expected %v got %v", "10012 admin", rollbacks[0])
+	}
+
+	timestamp, err := rollbacks[1].Time()
+	if err != nil || timestamp.Day() != 17 {
+		t.Errorf("expected %v got %v %v", 17, timestamp, err)
+	}
+
+	rollback, err := FindRollbackByLabel(rollbacks, "run-42")
+	if err != nil || rollback.Number != 0 {
+		t.Errorf("expected %v got %v %v", 0, rollback.Number, err)
+	}
+
+	_, err = FindRollbackByLabel(rollbacks, "run-43")
+	if err == nil {
+		t.Errorf("expected an error for a missing label")
+	}
+
+	content, err := config.GetRollback(0)
+	if err != nil || content != "# Created by: admin\n" {
+		t.Errorf("expected %q got %q %v", "# Created by: admin\n", content, err)
+	}
+
+	trans, _ := config.NewTransaction("read_write", "private", "", "reuse")
+	_, err = trans.LoadRollback(rollback.Number, true)
+	if err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	last := (*called)[len(*called)-1]
+	if last != "load_rollback" {
+		t.Errorf("expected %v got %v", "load_rollback", last)
+	}
+
+}
@@ -0,0 +1,395 @@
+package nsojsonrpcrequestergo
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+)
+
+// DefaultSinkBufferSize is the most events waiting on a sink unless SinkOptions sets it
+const DefaultSinkBufferSize = 1000
+
+// WebhookSignatureHeader is the header a WebhookSink puts the HMAC-SHA256 signature of the body in
+// the value is sha256= and the hex signature
+const WebhookSignatureHeader = "X-Nso-Signature-256"
+
+// Sink receives comet events forwarded by ForwardEvents
+type Sink interface {
+	// Deliver sends one event, an error is retried using the SinkOptions Retry
+	Deliver(ctx context.Context, event CometEvent) error
+}
+
+// SinkEvent is the JSON form of a CometEvent the built-in sinks send
+type SinkEvent struct {
+	Handle  string          `json:"handle,omitempty"`
+	Kind    string          `json:"kind"`
+	Path    string          `json:"path,omitempty"`
+	Message json.RawMessage `json:"message,omitempty"`
+}
+
+// newSinkEvent creates the JSON body of a CometEvent
+//   :values event: The CometEvent
+func newSinkEvent(event CometEvent) ([]byte, error) {
+	return json.Marshal(SinkEvent{Handle: event.Handle, Kind: event.Kind, Path: event.Path, Message: event.Message})
+
+}
+
+// SinkOptions holds how events are forwarded to each sink
+type SinkOptions struct {
+	// BufferSize is the most events waiting on a sink, more are dropped, 0 is DefaultSinkBufferSize
+	BufferSize int
+	// Retry is how a failed delivery is tried again, every error is retried, nil is no retry
+	Retry *RetryPolicy
+}
+
+// WebhookSink posts each event as JSON to a URL
+type WebhookSink struct {
+	url    string
+	secret []byte
+	client *http.Client
+}
+
+// Constructor for a WebhookSink
+//   :values webhookURL: The http or https URL to post to
+//   :values secret: The key of the HMAC-SHA256 signature, empty for no signature
+//   :values client: The *http.Client to post with, nil for one with a 10 second timeout
+func NewWebhookSink(webhookURL string, secret string, client *http.Client) (*WebhookSink, error) {
+	parsed, err := url.Parse(webhookURL)
+
+	if err != nil {
+		return &WebhookSink{}, err
+	}
+
+	if (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return &WebhookSink{}, fmt.Errorf("webhook url must be http or https with a host, got %s", webhookURL)
+	}
+
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+
+	return &WebhookSink{url: webhookURL, secret: []byte(secret), client: client}, nil
+
+}
+
+// Method to post an event to the webhook, a status that is not 2xx is an error
+//   :values ctx: A context.Context to cancel the request
+//   :values event: The CometEvent
+func (s *WebhookSink) Deliver(ctx context.Context, event CometEvent) error {
+	body, err := newSinkEvent(event)
+
+	if err != nil {
+		return err
+	}
+
+	request, err := http.NewRequest(http.MethodPost, s.url, bytes.NewReader(body))
+
+	if err != nil {
+		return err
+	}
+
+	request = request.WithContext(ctx)
+	request.Header.Set("Content-Type", "application/json")
+
+	if len(s.secret) > 0 {
+		request.Header.Set(WebhookSignatureHeader, "sha256="+signBody(s.secret, body))
+	}
+
+	response, err := s.client.Do(request)
+
+	if err != nil {
+		return err
+	}
+
+	defer response.Body.Close()
+	_, _ = io.Copy(ioutil.Discard, response.Body)
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return fmt.Errorf("webhook returned HTTP %d", response.StatusCode)
+	}
+
+	return nil
+
+}
+
+// signBody gets the hex HMAC-SHA256 of a body
+//   :values secret: The key
+//   :values body: The body to sign
+func signBody(secret []byte, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+
+	return hex.EncodeToString(mac.Sum(nil))
+
+}
+
+// WriterSink writes each event as a JSON line to an io.Writer
+type WriterSink struct {
+	lock   sync.Mutex
+	writer io.Writer
+}
+
+// Constructor for a WriterSink
+//   :values writer: The io.Writer to write to
+func NewWriterSink(writer io.Writer) *WriterSink {
+	return &WriterSink{writer: writer}
+
+}
+
+// Constructor for a WriterSink on stdout
+func NewStdoutSink() *WriterSink {
+	return NewWriterSink(os.Stdout)
+
+}
+
+// Method to write an event as a JSON line
+//   :values ctx: A context.Context, not used
+//   :values event: The CometEvent
+func (s *WriterSink) Deliver(ctx context.Context, event CometEvent) error {
+	body, err := newSinkEvent(event)
+
+	if err != nil {
+		return err
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	_, err = s.writer.Write(append(body, '\n'))
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+
+}
+
+// FileSink appends each event as a JSON line to a file
+type FileSink struct {
+	*WriterSink
+	file *os.File
+}
+
+// Constructor for a FileSink, the file is created when missing and appended to
+//   :values path: The path of the JSONL file
+func NewFileSink(path string) (*FileSink, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+
+	if err != nil {
+		return &FileSink{}, err
+	}
+
+	return &FileSink{WriterSink: NewWriterSink(file), file: file}, nil
+
+}
+
+// Method to close the file
+func (s *FileSink) Close() error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return s.file.Close()
+
+}
+
+// sinkRunner delivers the events buffered for one Sink
+type sinkRunner struct {
+	sink    Sink
+	buffer  chan CometEvent
+	retry   *RetryPolicy
+	logger  Logger
+	lock    sync.Mutex
+	dropped int
+}
+
+// Constructor for a sinkRunner
+//   :values sink: The Sink to deliver to
+//   :values options: The SinkOptions
+//   :values logger: The Logger for failed and dropped events
+func newSinkRunner(sink Sink, options SinkOptions, logger Logger) *sinkRunner {
+	size := options.BufferSize
+	if size == 0 {
+		size = DefaultSinkBufferSize
+	}
+
+	return &sinkRunner{sink: sink, buffer: make(chan CometEvent, size), retry: options.Retry, logger: logger}
+
+}
+
+// Method to buffer an event without waiting, false is returned when the buffer is full and it is dropped
+//   :values event: The CometEvent
+func (r *sinkRunner) enqueue(event CometEvent) bool {
+	select {
+	case r.buffer <- event:
+		return true
+	default:
+	}
+
+	r.lock.Lock()
+	r.dropped++
+	r.lock.Unlock()
+
+	r.logger.Warn("nso comet event dropped, the sink buffer is full", "handle", event.Handle, "kind", event.Kind)
+
+	return false
+
+}
+
+// Method to get how many events were dropped because the buffer was full
+func (r *sinkRunner) droppedEvents() int {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	return r.dropped
+
+}
+
+// Method to deliver the buffered events until the buffer is closed or ctx is cancelled
+//   :values ctx: A context.Context to stop delivering
+func (r *sinkRunner) run(ctx context.Context) {
+	for event := range r.buffer {
+		err := r.deliver(ctx, event)
+
+		if ctx.Err() != nil {
+			return
+		}
+
+		if err != nil {
+			r.logger.Error("nso comet event could not be delivered", "handle", event.Handle, "kind", event.Kind, "error", err)
+		}
+	}
+
+}
+
+// Method to deliver an event and retry it using the RetryPolicy
+//   :values ctx: A context.Context to cancel the delivery
+//   :values event: The CometEvent
+func (r *sinkRunner) deliver(ctx context.Context, event CometEvent) error {
+	for attempt := 1; ; attempt++ {
+		err := r.sink.Deliver(ctx, event)
+
+		if err == nil {
+			return nil
+		}
+
+		if r.retry == nil || attempt >= r.retry.MaxAttempts || ctx.Err() != nil {
+			return err
+		}
+
+		timer := time.NewTimer(r.retry.backoff(attempt))
+
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		}
+	}
+
+}
+
+// Method to forward the comet events to sinks until StopComet is called or ctx is cancelled
+// each sink has its own buffer and goroutine so a slow sink does not stall the comet poll
+// an event is dropped for a sink whose buffer is full, and after its retries fail
+// events of a Subscription with a handler are not forwarded, see DroppedEvents for the dropped events
+// the error the Events loop stopped on is returned, nil when it was stopped
+//   :values ctx: A context.Context to stop forwarding
+//   :values options: The SinkOptions
+//   :values sinks: The sinks to deliver each event to
+func (com *NsoJsonRpcComet) ForwardEvents(ctx context.Context, options SinkOptions, sinks ...Sink) error {
+	if len(sinks) == 0 {
+		return errors.New("at least one sink is required")
+	}
+
+	if options.BufferSize < 0 {
+		return errors.New("sink buffer size can not be negative")
+	}
+
+	if options.Retry != nil {
+		err := options.Retry.Validate()
+
+		if err != nil {
+			return err
+		}
+	}
+
+	events, err := com.Events(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	var wait sync.WaitGroup
+	runners := make([]*sinkRunner, 0, len(sinks))
+
+	for _, sink := range sinks {
+		runners = append(runners, newSinkRunner(sink, options, com.nsocon.logger))
+	}
+
+	com.lock.Lock()
+	com.sinks = runners
+	com.lock.Unlock()
+
+	for _, runner := range runners {
+		runner := runner
+
+		wait.Add(1)
+		go func() {
+			defer wait.Done()
+			runner.run(ctx)
+		}()
+	}
+
+	var loopErr error
+
+	for event := range events {
+		if event.Err != nil {
+			loopErr = event.Err
+			continue
+		}
+
+		for _, runner := range runners {
+			runner.enqueue(event)
+		}
+	}
+
+	// The buffered events are delivered before returning unless ctx is cancelled
+	for _, runner := range runners {
+		close(runner.buffer)
+	}
+
+	wait.Wait()
+
+	return loopErr
+
+}
+
+// Method to get how many events the last ForwardEvents dropped because a sink buffer was full
+// an event dropped for two sinks counts twice, the count starts again with each ForwardEvents
+func (com *NsoJsonRpcComet) DroppedEvents() int {
+	com.lock.Lock()
+	runners := com.sinks
+	com.lock.Unlock()
+
+	dropped := 0
+
+	for _, runner := range runners {
+		dropped += runner.droppedEvents()
+	}
+
+	return dropped
+
+}
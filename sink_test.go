@@ -0,0 +1,296 @@
+package nsojsonrpcrequestergo
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/btr1975/nsojsonrpcrequestergo/nsomock"
+)
+
+// testSink records the events delivered to it and fails the first failures deliveries
+// a sink with a gate waits on it being closed before delivering
+type testSink struct {
+	lock      sync.Mutex
+	failures  int
+	gate      chan struct{}
+	attempts  int
+	delivered []CometEvent
+}
+
+func (s *testSink) Deliver(ctx context.Context, event CometEvent) error {
+	if s.gate != nil {
+		<-s.gate
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.attempts++
+
+	if s.failures > 0 {
+		s.failures--
+		return errors.New("sink is down")
+	}
+
+	s.delivered = append(s.delivered, event)
+
+	return nil
+}
+
+func (s *testSink) events() []CometEvent {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return append([]CometEvent(nil), s.delivered...)
+}
+
+func Test_NewWebhookSink(t *testing.T) {
+	scenarios := []struct {
+		url      string
+		rcvError error
+	}{
+		{url: "https://hooks.example.com/nso", rcvError: nil},
+		{url: "ftp://hooks.example.com/nso", rcvError: errors.New("webhook url must be http or https with a host, got ftp://hooks.example.com/nso")},
+		{url: "http://", rcvError: errors.New("webhook url must be http or https with a host, got http://")},
+	}
+
+	for _, scenario := range scenarios {
+		_, err := NewWebhookSink(scenario.url, "", nil)
+		if err != scenario.rcvError {
+			if err == nil || scenario.rcvError == nil || err.Error() != scenario.rcvError.Error() {
+				t.Errorf("expected error %v got %v", scenario.rcvError, err)
+			}
+		}
+	}
+
+}
+
+func Test_WebhookSink_Deliver(t *testing.T) {
+	var received []byte
+	var signature string
+	status := http.StatusNoContent
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received, _ = ioutil.ReadAll(r.Body)
+		signature = r.Header.Get(WebhookSignatureHeader)
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	sink, _ := NewWebhookSink(server.URL, "hook-secret", nil)
+	event := CometEvent{Handle: "c1-1", Kind: CometKindChanges, Path: "/ncs:devices", Message: json.RawMessage(`{"db":"running"}`)}
+
+	err := sink.Deliver(context.Background(), event)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	expect := `{"handle":"c1-1","kind":"changes","path":"/ncs:devices","message":{"db":"running"}}`
+	if string(received) != expect {
+		t.Errorf("expected %v got %v", expect, string(received))
+	}
+
+	if signature != "sha256="+signBody([]byte("hook-secret"), received) {
+		t.Errorf("expected %v got %v", signBody([]byte("hook-secret"), received), signature)
+	}
+
+	status = http.StatusInternalServerError
+
+	err = sink.Deliver(context.Background(), event)
+	if err == nil || err.Error() != "webhook returned HTTP 500" {
+		t.Errorf("expected error %v got %v", "webhook returned HTTP 500", err)
+	}
+
+}
+
+func Test_FileSink_WriterSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+
+	fileSink, err := NewFileSink(path)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	var buffer bytes.Buffer
+	writerSink := NewWriterSink(&buffer)
+
+	for _, sink := range []Sink{fileSink, writerSink} {
+		_ = sink.Deliver(context.Background(), CometEvent{Kind: CometKindResubscribed})
+		_ = sink.Deliver(context.Background(), CometEvent{Handle: "c1-1", Kind: CometKindPollLeaf, Message: json.RawMessage(`{"value":"10.0.0.1"}`)})
+	}
+	_ = fileSink.Close()
+
+	expect := `{"kind":"resubscribed"}` + "\n" + `{"handle":"c1-1","kind":"poll_leaf","message":{"value":"10.0.0.1"}}` + "\n"
+
+	data, _ := ioutil.ReadFile(path)
+	for _, rcv := range []string{string(data), buffer.String()} {
+		if rcv != expect {
+			t.Errorf("expected %v got %v", expect, rcv)
+		}
+	}
+
+}
+
+func Test_sinkRunner(t *testing.T) {
+	sink := &testSink{failures: 2}
+	runner := newSinkRunner(sink, SinkOptions{BufferSize: 2, Retry: &RetryPolicy{MaxAttempts: 3}}, noopLogger{})
+
+	scenarios := []struct {
+		handle string
+		expect bool
+	}{
+		{handle: "c1-1", expect: true},
+		{handle: "c1-2", expect: true},
+		{handle: "c1-3", expect: false},
+	}
+
+	for _, scenario := range scenarios {
+		rcv := runner.enqueue(CometEvent{Handle: scenario.handle})
+		if rcv != scenario.expect {
+			t.Errorf("expected %v got %v", scenario.expect, rcv)
+		}
+	}
+
+	close(runner.buffer)
+	runner.run(context.Background())
+
+	if sink.attempts != 4 || len(sink.events()) != 2 || runner.droppedEvents() != 1 {
+		t.Errorf("expected %v got %v %v %v", "4 attempts 2 delivered 1 dropped", sink.attempts, sink.events(), runner.droppedEvents())
+	}
+
+}
+
+func Test_NsoJsonRpcComet_ForwardEvents(t *testing.T) {
+	server := nsomock.NewServer(nsomock.WithPollTimeout(50 * time.Millisecond))
+	defer server.Close()
+
+	comet, _ := NewNsoJsonRpcComet("http", server.Host(), server.Port(), "admin", "admin", false)
+	_ = comet.StartComet()
+
+	err := comet.ForwardEvents(context.Background(), SinkOptions{})
+	if err == nil || err.Error() != "at least one sink is required" {
+		t.Errorf("expected error %v got %v", "at least one sink is required", err)
+	}
+
+	subscription, _ := comet.SubscribePollLeaf("/ncs:devices/device{r1}/address", 1)
+
+	fast := &testSink{failures: 1}
+	slow := &testSink{gate: make(chan struct{})}
+
+	done := make(chan error)
+	go func() {
+		done <- comet.ForwardEvents(context.Background(), SinkOptions{Retry: &RetryPolicy{MaxAttempts: 2}}, fast, slow)
+	}()
+
+	for _, address := range []string{"10.0.0.1", "10.0.0.2"} {
+		server.SetValue("/ncs:devices/device{r1}/address", address)
+
+		config, _ := NewNsoJsonRpcConfig("http", server.Host(), server.Port(), "admin", "admin", false)
+		_ = config.NsoLogin()
+		trans, _ := config.NewTransaction("read_write", "private", "", "reuse")
+		_, _ = trans.SetValue("/ncs:devices/device{r1}/address", address+"0", false)
+		_, _ = trans.Commit(false, "", false)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for len(fast.events()) < 3 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// The slow sink does not hold up the fast one
+	if len(fast.events()) != 3 || len(slow.events()) != 0 {
+		t.Errorf("expected %v got %v %v", "3 fast events before the slow ones", fast.events(), slow.events())
+	}
+
+	close(slow.gate)
+	_ = comet.StopComet()
+
+	select {
+	case err = <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timed out waiting on ForwardEvents")
+	}
+
+	if err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	// The buffered events are delivered when the comet stops
+	events := slow.events()
+	if len(events) != 3 || events[0].Handle != subscription.Handle() {
+		t.Errorf("expected %v got %v", 3, events)
+	}
+
+	for _, event := range fast.events() {
+		if !strings.HasPrefix(string(event.Message), `{"value":`) {
+			t.Errorf("expected %v got %v", "a poll leaf value", string(event.Message))
+		}
+	}
+
+}
+
+func Test_NsoJsonRpcComet_DroppedEvents(t *testing.T) {
+	server := nsomock.NewServer(nsomock.WithPollTimeout(50 * time.Millisecond))
+	defer server.Close()
+
+	comet, _ := NewNsoJsonRpcComet("http", server.Host(), server.Port(), "admin", "admin", false)
+	_ = comet.StartComet()
+
+	if comet.DroppedEvents() != 0 {
+		t.Errorf("expected %v got %v", 0, comet.DroppedEvents())
+	}
+
+	_, _ = comet.SubscribePollLeaf("/ncs:devices/device{r1}/address", 1)
+
+	fast := &testSink{}
+	slow := &testSink{gate: make(chan struct{})}
+
+	done := make(chan error)
+	go func() {
+		done <- comet.ForwardEvents(context.Background(), SinkOptions{BufferSize: 1}, fast, slow)
+	}()
+
+	config, _ := NewNsoJsonRpcConfig("http", server.Host(), server.Port(), "admin", "admin", false)
+	_ = config.NsoLogin()
+
+	for _, address := range []string{"10.0.0.1", "10.0.0.2"} {
+		trans, _ := config.NewTransaction("read_write", "private", "", "reuse")
+		_, _ = trans.SetValue("/ncs:devices/device{r1}/address", address, false)
+		_, _ = trans.Commit(false, "", false)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for len(fast.events()) < 3 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// The slow sink holds one event and buffers one so the rest are dropped for it only
+	dropped := comet.DroppedEvents()
+	if len(fast.events()) != 3 || dropped < 1 {
+		t.Errorf("expected %v got %v %v", "3 fast events and a dropped slow one", fast.events(), dropped)
+	}
+
+	close(slow.gate)
+	_ = comet.StopComet()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timed out waiting on ForwardEvents")
+	}
+
+	if len(slow.events())+comet.DroppedEvents() != 3 {
+		t.Errorf("expected %v got %v %v", 3, slow.events(), comet.DroppedEvents())
+	}
+
+}
@@ -0,0 +1,363 @@
+package nsojsonrpcrequestergo
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+)
+
+// ChangeNotification holds one change of a changes or cdboper subscription
+type ChangeNotification struct {
+	// Keypath is the keypath that changed
+	Keypath string `json:"keypath"`
+	// Op is the operation, like created, deleted, modified or value_set
+	Op string `json:"op"`
+	// Value is the new value, empty if the change has none
+	Value string `json:"value"`
+	// DB is the datastore that changed, like running
+	DB string `json:"db"`
+	// User is the user that made the change
+	User string `json:"user"`
+}
+
+// PollLeafValue holds the value of a poll_leaf subscription
+type PollLeafValue struct {
+	// Path is the path of the leaf
+	Path string
+	// Value is the value of the leaf
+	Value string
+}
+
+// UpgradeStatus holds a message of an upgrade subscription
+type UpgradeStatus struct {
+	// State is the upgrade state, like wait_for_init, init, abort or commit
+	State string `json:"upgrade_state"`
+	// Timeout is the seconds to wait for the upgrade, when NSO sends one
+	Timeout int `json:"timeout"`
+}
+
+// BatchResult holds the result of a request sent on a jsonrpc_batch subscription
+type BatchResult struct {
+	// ID is the raw JSON-RPC id of the request
+	ID json.RawMessage `json:"id"`
+	// Result is the raw result member, empty on an error
+	Result json.RawMessage `json:"result"`
+	// Err is the error member, nil if there is none
+	Err *NsoRpcError `json:"-"`
+}
+
+// SystemMessage holds a message of a messages subscription
+type SystemMessage struct {
+	// Type is the type of message, like message, sys_message or prio_message
+	Type string `json:"type"`
+	// User is the user that sent the message
+	User string `json:"user"`
+	// Message is the text of the message
+	Message string `json:"message"`
+}
+
+// Subscription holds a comet subscription
+type Subscription struct {
+	comet    *NsoJsonRpcComet
+	handle   string
+	kind     string
+	path     string
+	params   map[string]interface{}
+	response *NsoJsonResponse
+	lock     sync.Mutex
+	handler  func(CometEvent) error
+}
+
+// Method to get the handle of the subscription, it changes when the comet resubscribes
+func (s *Subscription) Handle() string {
+	s.comet.lock.Lock()
+	defer s.comet.lock.Unlock()
+
+	return s.handle
+
+}
+
+// Method to get the kind of subscription, like CometKindChanges
+func (s *Subscription) Kind() string {
+	return s.kind
+
+}
+
+// Method to get the path the subscription is on, empty if it has none
+func (s *Subscription) Path() string {
+	return s.path
+
+}
+
+// Method to get the response to start_subscription
+func (s *Subscription) Response() *NsoJsonResponse {
+	return s.response
+
+}
+
+// Method to set the handler called with each change of a changes or cdboper subscription
+// the handler runs on the Events loop and the events it handles are not put on the channel
+//   :values handler: A func called with each ChangeNotification
+func (s *Subscription) OnChange(handler func(ChangeNotification)) {
+	s.setHandler(func(event CometEvent) error {
+		changes, err := event.ChangeNotifications()
+
+		if err != nil {
+			return err
+		}
+
+		for _, change := range changes {
+			handler(change)
+		}
+
+		return nil
+	})
+
+}
+
+// Method to set the handler called with each value of a poll_leaf subscription
+// the handler runs on the Events loop and the events it handles are not put on the channel
+//   :values handler: A func called with each PollLeafValue
+func (s *Subscription) OnPollLeaf(handler func(PollLeafValue)) {
+	s.setHandler(func(event CometEvent) error {
+		value, err := event.PollLeafValue()
+
+		if err != nil {
+			return err
+		}
+
+		handler(value)
+
+		return nil
+	})
+
+}
+
+// Method to set the handler called with each message of an upgrade subscription
+// the handler runs on the Events loop and the events it handles are not put on the channel
+//   :values handler: A func called with each UpgradeStatus
+func (s *Subscription) OnUpgrade(handler func(UpgradeStatus)) {
+	s.setHandler(func(event CometEvent) error {
+		status, err := event.UpgradeStatus()
+
+		if err != nil {
+			return err
+		}
+
+		handler(status)
+
+		return nil
+	})
+
+}
+
+// Method to set the handler called with each result of a jsonrpc_batch subscription
+// the handler runs on the Events loop and the events it handles are not put on the channel
+//   :values handler: A func called with each BatchResult
+func (s *Subscription) OnBatchResult(handler func(BatchResult)) {
+	s.setHandler(func(event CometEvent) error {
+		result, err := event.BatchResult()
+
+		if err != nil {
+			return err
+		}
+
+		handler(result)
+
+		return nil
+	})
+
+}
+
+// Method to set the handler called with each message of a messages subscription
+// the handler runs on the Events loop and the events it handles are not put on the channel
+//   :values handler: A func called with each SystemMessage
+func (s *Subscription) OnMessage(handler func(SystemMessage)) {
+	s.setHandler(func(event CometEvent) error {
+		message, err := event.SystemMessage()
+
+		if err != nil {
+			return err
+		}
+
+		handler(message)
+
+		return nil
+	})
+
+}
+
+// Method to set the handler of the subscription
+//   :values handler: A func that decodes and handles an event
+func (s *Subscription) setHandler(handler func(CometEvent) error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.handler = handler
+
+}
+
+// Method to get the handler of the subscription, nil if it has none
+func (s *Subscription) getHandler() func(CometEvent) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return s.handler
+
+}
+
+// Method to stop the subscription
+func (s *Subscription) Unsubscribe() error {
+	err := s.UnsubscribeContext(context.Background())
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Method to stop the subscription using a context
+// the handle is removed from the comet so it is not unsubscribed again on StopComet
+//   :values ctx: A context.Context to cancel the request
+func (s *Subscription) UnsubscribeContext(ctx context.Context) error {
+	handle := s.Handle()
+
+	if !s.comet.hasHandle(handle) {
+		return nil
+	}
+
+	err := s.comet.unsubscribeHandle(ctx, handle)
+
+	if err != nil {
+		return err
+	}
+
+	s.comet.removeHandle(handle)
+
+	return nil
+
+}
+
+// Method to decode the changes of a changes or cdboper notification
+func (e CometEvent) ChangeNotifications() ([]ChangeNotification, error) {
+	var message struct {
+		DB      string `json:"db"`
+		User    string `json:"user"`
+		Changes []struct {
+			Keypath string          `json:"keypath"`
+			Op      string          `json:"op"`
+			Value   json.RawMessage `json:"value"`
+		} `json:"changes"`
+	}
+
+	err := json.Unmarshal(e.Message, &message)
+
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make([]ChangeNotification, 0, len(message.Changes))
+
+	for _, change := range message.Changes {
+		changes = append(changes, ChangeNotification{
+			Keypath: change.Keypath,
+			Op:      change.Op,
+			Value:   messageValue(change.Value),
+			DB:      message.DB,
+			User:    message.User,
+		})
+	}
+
+	return changes, nil
+
+}
+
+// Method to decode the value of a poll_leaf notification
+func (e CometEvent) PollLeafValue() (PollLeafValue, error) {
+	var message struct {
+		Value json.RawMessage `json:"value"`
+	}
+
+	err := json.Unmarshal(e.Message, &message)
+
+	if err != nil {
+		return PollLeafValue{}, err
+	}
+
+	return PollLeafValue{Path: e.Path, Value: messageValue(message.Value)}, nil
+
+}
+
+// Method to decode an upgrade notification
+func (e CometEvent) UpgradeStatus() (UpgradeStatus, error) {
+	var status UpgradeStatus
+
+	err := json.Unmarshal(e.Message, &status)
+
+	if err != nil {
+		return UpgradeStatus{}, err
+	}
+
+	return status, nil
+
+}
+
+// Method to decode a jsonrpc_batch notification
+func (e CometEvent) BatchResult() (BatchResult, error) {
+	var message struct {
+		ID     json.RawMessage `json:"id"`
+		Result json.RawMessage `json:"result"`
+		Error  json.RawMessage `json:"error"`
+	}
+
+	err := json.Unmarshal(e.Message, &message)
+
+	if err != nil {
+		return BatchResult{}, err
+	}
+
+	result := BatchResult{ID: message.ID, Result: message.Result}
+
+	if len(message.Error) > 0 && string(message.Error) != "null" {
+		result.Err, err = newNsoRpcError("batch", 0, message.Error)
+
+		if err != nil {
+			return BatchResult{}, err
+		}
+	}
+
+	return result, nil
+
+}
+
+// Method to decode a messages notification
+func (e CometEvent) SystemMessage() (SystemMessage, error) {
+	var message SystemMessage
+
+	err := json.Unmarshal(e.Message, &message)
+
+	if err != nil {
+		return SystemMessage{}, err
+	}
+
+	return message, nil
+
+}
+
+// messageValue gets a notification value as a string, a value that is not a JSON string is kept as its JSON text
+//   :values raw: The raw value
+func messageValue(raw json.RawMessage) string {
+	var value string
+
+	if len(raw) == 0 || string(raw) == "null" {
+		return ""
+	}
+
+	if json.Unmarshal(raw, &value) == nil {
+		return value
+	}
+
+	return string(raw)
+
+}
@@ -0,0 +1,260 @@
+package nsojsonrpcrequestergo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/btr1975/nsojsonrpcrequestergo/nsomock"
+)
+
+func Test_CometEvent_ChangeNotifications(t *testing.T) {
+	scenarios := []struct {
+		message  string
+		expect   []ChangeNotification
+		rcvError bool
+	}{
+		{
+			message: `{"db": "running", "user": "admin", "changes": [{"keypath": "/devices/device{r1}", "op": "created"}, {"keypath": "/devices/device{r1}/port", "op": "value_set", "value": 22}]}`,
+			expect: []ChangeNotification{
+				{Keypath: "/devices/device{r1}", Op: "created", DB: "running", User: "admin"},
+				{Keypath: "/devices/device{r1}/port", Op: "value_set", Value: "22", DB: "running", User: "admin"},
+			},
+		},
+		{message: `{"changes": []}`, expect: []ChangeNotification{}},
+		{message: `[]`, rcvError: true},
+	}
+
+	for _, scenario := range scenarios {
+		rcv, err := CometEvent{Message: json.RawMessage(scenario.message)}.ChangeNotifications()
+
+		if (err != nil) != scenario.rcvError {
+			t.Errorf("expected error %v got %v", scenario.rcvError, err)
+		}
+
+		if err == nil && !reflect.DeepEqual(rcv, scenario.expect) {
+			t.Errorf("expected %v got %v", scenario.expect, rcv)
+		}
+	}
+
+}
+
+func Test_CometEvent_payloads(t *testing.T) {
+	event := CometEvent{Path: "/ncs:devices/device{r1}/address", Message: json.RawMessage(`{"value": "10.0.0.1"}`)}
+
+	value, err := event.PollLeafValue()
+	if err != nil || value != (PollLeafValue{Path: "/ncs:devices/device{r1}/address", Value: "10.0.0.1"}) {
+		t.Errorf("expected %v got %v %v", "10.0.0.1", value, err)
+	}
+
+	status, err := CometEvent{Message: json.RawMessage(`{"upgrade_state": "wait_for_init", "timeout": 30}`)}.UpgradeStatus()
+	if err != nil || status != (UpgradeStatus{State: "wait_for_init", Timeout: 30}) {
+		t.Errorf("expected %v got %v %v", "wait_for_init", status, err)
+	}
+
+	result, err := CometEvent{Message: json.RawMessage(`{"id": 4, "result": {"value": "r1"}}`)}.BatchResult()
+	if err != nil || string(result.ID) != "4" || string(result.Result) != `{"value": "r1"}` || result.Err != nil {
+		t.Errorf("expected %v got %v %v", "a result", result, err)
+	}
+
+	result, err = CometEvent{Message: json.RawMessage(`{"id": 5, "error": {"code": -32000, "type": "data.not_found", "message": "Not found"}}`)}.BatchResult()
+	if err != nil || result.Err == nil || result.Err.Type != "data.not_found" {
+		t.Errorf("expected %v got %v %v", "data.not_found", result, err)
+	}
+
+}
+
+func Test_Subscription(t *testing.T) {
+	server := nsomock.NewServer(nsomock.WithPollTimeout(50 * time.Millisecond))
+	defer server.Close()
+
+	comet, _ := NewNsoJsonRpcComet("http", server.Host(), server.Port(), "admin", "admin", false)
+	_ = comet.StartComet()
+
+	changes, err := comet.SubscribeChanges("/ncs:devices")
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	if changes.Kind() != CometKindChanges || changes.Path() != "/ncs:devices" || changes.Handle() == "" {
+		t.Errorf("expected %v got %v %v %v", CometKindChanges, changes.Kind(), changes.Path(), changes.Handle())
+	}
+
+	received := make(chan ChangeNotification, 10)
+	changes.OnChange(func(change ChangeNotification) {
+		received <- change
+	})
+
+	leaf, _ := comet.SubscribePollLeaf("/ncs:devices/device{r1}/address", 1)
+
+	events, _ := comet.Events(context.Background())
+
+	config, _ := NewNsoJsonRpcConfig("http", server.Host(), server.Port(), "admin", "admin", false)
+	_ = config.NsoLogin()
+	trans, _ := config.NewTransaction("read_write", "private", "", "reuse")
+	_, _ = trans.SetValue("/ncs:devices/device{r1}/address", "10.0.0.1", false)
+	_, _ = trans.Commit(false, "", false)
+
+	select {
+	case change := <-received:
+		if change.DB != "running" || change.User != "admin" {
+			t.Errorf("expected %v got %v", "running admin", change)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timed out waiting on a change")
+	}
+
+	// The poll leaf has no handler so its events are on the channel
+	event, _ := nextCometEvent(t, events)
+	if event.Handle != leaf.Handle() {
+		t.Errorf("expected %v got %v", leaf.Handle(), event.Handle)
+	}
+
+	err = changes.Unsubscribe()
+	if err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	if comet.hasHandle(changes.Handle()) || !comet.hasHandle(leaf.Handle()) {
+		t.Errorf("expected %v got %v", []string{leaf.Handle()}, comet.handles)
+	}
+
+	err = comet.StopComet()
+	if err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+
+}
+
+func Test_Subscription_startFails(t *testing.T) {
+	server := nsomock.NewServer(nsomock.WithPollTimeout(50 * time.Millisecond))
+	defer server.Close()
+
+	comet, _ := NewNsoJsonRpcComet("http", server.Host(), server.Port(), "admin", "admin", false)
+	_ = comet.StartComet()
+
+	server.FailNext("start_subscription", "rpc.method.failed")
+
+	subscription, err := comet.SubscribeChangesWithOptions("/ncs:devices", SubscribeChangesOptions{SubscribeOptions: SubscribeOptions{Handle: "broken"}})
+	var rpcError *NsoRpcError
+	if !errors.As(err, &rpcError) || rpcError.Method != "start_subscription" {
+		t.Errorf("expected %v got %v", "a start_subscription error", err)
+	}
+
+	if subscription != nil || comet.hasHandle("broken") {
+		t.Errorf("expected %v got %v", "no subscription", comet.handles)
+	}
+
+	before := len(server.Calls())
+
+	err = comet.StopComet()
+	if err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	// The handle that did not start is not unsubscribed
+	for _, call := range server.Calls()[before:] {
+		if call == "unsubscribe" {
+			t.Errorf("expected %v got %v", "no unsubscribe", server.Calls()[before:])
+		}
+	}
+
+}
+
+func Test_SubscribeChangesWithOptions(t *testing.T) {
+	server := nsomock.NewServer(nsomock.WithPollTimeout(50 * time.Millisecond))
+	defer server.Close()
+
+	comet, _ := NewNsoJsonRpcComet("http", server.Host(), server.Port(), "admin", "admin", false)
+	_ = comet.StartComet()
+
+	local, err := comet.SubscribeChangesWithOptions("/ncs:devices", SubscribeChangesOptions{SubscribeOptions: SubscribeOptions{Handle: "skip-local"}, SkipLocalChanges: true})
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	if local.Handle() != "skip-local" {
+		t.Errorf("expected %v got %v", "skip-local", local.Handle())
+	}
+
+	hideValues, _ := comet.SubscribeChangesWithOptions("/ncs:devices", SubscribeChangesOptions{HideValues: true})
+	hideChanges, _ := comet.SubscribeChangesWithOptions("/ncs:devices", SubscribeChangesOptions{HideChanges: true})
+	messages, _ := comet.SubscribeMessages()
+
+	systemMessages := make(chan SystemMessage, 1)
+	messages.OnMessage(func(message SystemMessage) {
+		systemMessages <- message
+	})
+
+	events, _ := comet.Events(context.Background())
+
+	// A change made on the session of the comet is local
+	trans, _ := comet.nsocon.NewTransaction("read_write", "private", "", "reuse")
+	_, _ = trans.SetValue("/ncs:devices/device{r1}/address", "10.0.0.1", false)
+	_, _ = trans.Commit(false, "", false)
+
+	config, _ := NewNsoJsonRpcConfig("http", server.Host(), server.Port(), "admin", "admin", false)
+	_ = config.NsoLogin()
+	trans, _ = config.NewTransaction("read_write", "private", "", "reuse")
+	_, _ = trans.SetValue("/ncs:devices/device{r2}/address", "10.0.0.2", false)
+	_, _ = trans.Commit(false, "", false)
+
+	server.SendMessage("NSO restarts at 17:00")
+
+	scenarios := map[string]struct {
+		events  int
+		changes []ChangeNotification
+	}{
+		local.Handle(): {events: 1, changes: []ChangeNotification{
+			{Keypath: "/devices/device{r2}", Op: "created", DB: "running", User: "admin"},
+			{Keypath: "/devices/device{r2}/address", Op: "value_set", Value: "10.0.0.2", DB: "running", User: "admin"},
+		}},
+		hideValues.Handle(): {events: 2, changes: []ChangeNotification{
+			{Keypath: "/devices/device{r1}", Op: "created", DB: "running", User: "admin"},
+			{Keypath: "/devices/device{r1}/address", Op: "value_set", DB: "running", User: "admin"},
+			{Keypath: "/devices/device{r2}", Op: "created", DB: "running", User: "admin"},
+			{Keypath: "/devices/device{r2}/address", Op: "value_set", DB: "running", User: "admin"},
+		}},
+		hideChanges.Handle(): {events: 2, changes: []ChangeNotification{}},
+	}
+
+	received := make(map[string][]ChangeNotification)
+	counts := make(map[string]int)
+
+	for total := 0; total < 5; total++ {
+		event, _ := nextCometEvent(t, events)
+		changes, _ := event.ChangeNotifications()
+
+		counts[event.Handle]++
+		received[event.Handle] = append(received[event.Handle], changes...)
+	}
+
+	for handle, scenario := range scenarios {
+		if counts[handle] != scenario.events {
+			t.Errorf("expected %v got %v for %v", scenario.events, counts[handle], handle)
+		}
+
+		if len(scenario.changes) > 0 && !reflect.DeepEqual(received[handle], scenario.changes) {
+			t.Errorf("expected %v got %v", scenario.changes, received[handle])
+		}
+
+		if len(scenario.changes) == 0 && len(received[handle]) != 0 {
+			t.Errorf("expected %v got %v", scenario.changes, received[handle])
+		}
+	}
+
+	select {
+	case message := <-systemMessages:
+		if message != (SystemMessage{Type: "sys_message", User: "system", Message: "NSO restarts at 17:00"}) {
+			t.Errorf("expected %v got %v", "NSO restarts at 17:00", message)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timed out waiting on a message")
+	}
+
+	_ = comet.StopComet()
+
+}
@@ -0,0 +1,190 @@
+package nsojsonrpcrequestergo
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"strings"
+)
+
+// ErrCertificatePinMismatch is returned when no certificate of the NSO Server matches a pinned fingerprint
+var ErrCertificatePinMismatch = errors.New("nso server certificate does not match a pinned fingerprint")
+
+// TLSOptions holds the TLS settings used to reach the NSO Server
+type TLSOptions struct {
+	// CAFiles are PEM files with the CAs to trust, they replace the system CAs
+	CAFiles []string
+	// CertFile and KeyFile are the PEM client certificate and key for mTLS
+	CertFile string
+	KeyFile  string
+	// MinVersion is the minimum TLS version like tls.VersionTLS12
+	MinVersion uint16
+	// ServerName overrides the name the server certificate is verified against
+	ServerName string
+	// PinnedSHA256 are SHA-256 certificate fingerprints in hex, colons are allowed
+	// one certificate of the server chain has to match
+	PinnedSHA256 []string
+}
+
+// Method to create a *tls.Config from the TLSOptions
+func (o *TLSOptions) TLSConfig() (*tls.Config, error) {
+	config := &tls.Config{}
+
+	err := o.apply(config)
+
+	if err != nil {
+		return config, err
+	}
+
+	return config, nil
+
+}
+
+// Method to apply the TLSOptions to a *tls.Config
+//   :values config: The *tls.Config to change
+func (o *TLSOptions) apply(config *tls.Config) error {
+	if len(o.CAFiles) > 0 {
+		pool := x509.NewCertPool()
+
+		for _, caFile := range o.CAFiles {
+			pem, err := ioutil.ReadFile(caFile)
+
+			if err != nil {
+				return fmt.Errorf("could not read CA file: %w", err)
+			}
+
+			if !pool.AppendCertsFromPEM(pem) {
+				return fmt.Errorf("no certificates found in CA file %s", caFile)
+			}
+		}
+
+		config.RootCAs = pool
+	}
+
+	if o.CertFile != "" || o.KeyFile != "" {
+		if o.CertFile == "" || o.KeyFile == "" {
+			return errors.New("a client certificate needs both a certificate and a key file")
+		}
+
+		cert, err := tls.LoadX509KeyPair(o.CertFile, o.KeyFile)
+
+		if err != nil {
+			return fmt.Errorf("could not load client certificate: %w", err)
+		}
+
+		config.Certificates = append(config.Certificates, cert)
+	}
+
+	if o.MinVersion != 0 {
+		switch o.MinVersion {
+		case tls.VersionTLS10, tls.VersionTLS11, tls.VersionTLS12, tls.VersionTLS13:
+			config.MinVersion = o.MinVersion
+		default:
+			return fmt.Errorf("unsupported minimum TLS version %#x", o.MinVersion)
+		}
+	}
+
+	if o.ServerName != "" {
+		config.ServerName = o.ServerName
+	}
+
+	if len(o.PinnedSHA256) > 0 {
+		pins := make(map[string]bool)
+
+		for _, pin := range o.PinnedSHA256 {
+			fingerprint, err := normalizeFingerprint(pin)
+
+			if err != nil {
+				return err
+			}
+
+			pins[fingerprint] = true
+		}
+
+		// VerifyConnection runs even when the chain is not verified
+		config.VerifyConnection = func(state tls.ConnectionState) error {
+			return checkPinnedCertificates(state.PeerCertificates, pins)
+		}
+	}
+
+	return nil
+
+}
+
+// normalizeFingerprint converts a SHA-256 fingerprint to lowercase hex without colons
+//   :values fingerprint: A SHA-256 fingerprint in hex
+func normalizeFingerprint(fingerprint string) (string, error) {
+	normalized := strings.ToLower(strings.NewReplacer(":", "", " ", "").Replace(fingerprint))
+
+	raw, err := hex.DecodeString(normalized)
+
+	if err != nil || len(raw) != sha256.Size {
+		return normalized, fmt.Errorf("pinned fingerprint %s is not a SHA-256 hex digest", fingerprint)
+	}
+
+	return normalized, nil
+
+}
+
+// CertificateFingerprint gets the SHA-256 fingerprint of a certificate in lowercase hex
+//   :values cert: A *x509.Certificate
+func CertificateFingerprint(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.Raw)
+
+	return hex.EncodeToString(sum[:])
+
+}
+
+// checkPinnedCertificates checks one certificate of the chain matches a pin
+//   :values certs: The certificates sent by the server
+//   :values pins: The normalized pinned fingerprints
+func checkPinnedCertificates(certs []*x509.Certificate, pins map[string]bool) error {
+	for _, cert := range certs {
+		if pins[CertificateFingerprint(cert)] {
+			return nil
+		}
+	}
+
+	if len(certs) == 0 {
+		return ErrCertificatePinMismatch
+	}
+
+	return fmt.Errorf("%w: server sent %s", ErrCertificatePinMismatch, CertificateFingerprint(certs[0]))
+
+}
+
+// tlsError explains a TLS verification error from a request
+// other errors are returned as they are
+//   :values err: The error from the request
+func tlsError(err error) error {
+	var unknownAuthority x509.UnknownAuthorityError
+	var hostname x509.HostnameError
+	var invalid x509.CertificateInvalidError
+
+	switch {
+	case errors.As(err, &unknownAuthority):
+		return fmt.Errorf("nso server certificate is signed by an unknown authority, add its CA to the trusted CAs: %w", err)
+	case errors.As(err, &hostname):
+		return fmt.Errorf("nso server certificate is not valid for %s, check the host or server name: %w", hostname.Host, err)
+	case errors.As(err, &invalid):
+		return fmt.Errorf("nso server certificate is not valid: %w", err)
+	}
+
+	return err
+
+}
+
+// isTLSVerifyError checks if an error is a failed certificate check, those do not go away on a retry
+//   :values err: The error the request failed with
+func isTLSVerifyError(err error) bool {
+	var unknownAuthority x509.UnknownAuthorityError
+	var hostname x509.HostnameError
+	var invalid x509.CertificateInvalidError
+
+	return errors.Is(err, ErrCertificatePinMismatch) || errors.As(err, &unknownAuthority) || errors.As(err, &hostname) || errors.As(err, &invalid)
+
+}
@@ -0,0 +1,127 @@
+package nsojsonrpcrequestergo
+
+import (
+	"crypto/tls"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// testTLSServer starts a TLS JSON-RPC server and writes its certificate to a PEM file
+func testTLSServer(t *testing.T) (*httptest.Server, string) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"jsonrpc": "2.0", "result": {}}`))
+	}))
+
+	dir, err := ioutil.TempDir("", "nsotls")
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	caFile := filepath.Join(dir, "ca.pem")
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+
+	err = ioutil.WriteFile(caFile, caPEM, 0600)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	return server, caFile
+
+}
+
+func Test_NewClient_TLS(t *testing.T) {
+	server, caFile := testTLSServer(t)
+	defer server.Close()
+	defer os.RemoveAll(filepath.Dir(caFile))
+
+	fingerprint := CertificateFingerprint(server.Certificate())
+	wrongFingerprint := strings.Repeat("ab", 32)
+
+	scenarios := []struct {
+		opts     []ClientOption
+		rcvError string
+	}{
+		{opts: []ClientOption{WithCAFiles(caFile)}, rcvError: ""},
+		{opts: []ClientOption{WithCAFiles(caFile), WithMinTLSVersion(tls.VersionTLS12)}, rcvError: ""},
+		{opts: []ClientOption{WithCAFiles(caFile), WithServerName("example.com")}, rcvError: ""},
+		{opts: []ClientOption{WithCAFiles(caFile), WithPinnedCertificates(strings.ToUpper(fingerprint))}, rcvError: ""},
+		{opts: []ClientOption{WithSSLVerify(false), WithPinnedCertificates(fingerprint)}, rcvError: ""},
+		{opts: []ClientOption{}, rcvError: "nso server certificate is signed by an unknown authority"},
+		{opts: []ClientOption{WithCAFiles(caFile), WithServerName("nso.example.net")}, rcvError: "nso server certificate is not valid for nso.example.net"},
+		{opts: []ClientOption{WithSSLVerify(false), WithPinnedCertificates(wrongFingerprint)}, rcvError: ErrCertificatePinMismatch.Error()},
+	}
+
+	for _, scenario := range scenarios {
+		client, err := NewClient(server.URL, scenario.opts...)
+		if err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+
+		err = client.NsoLogin()
+		if scenario.rcvError == "" && err != nil {
+			t.Errorf("unexpected error %v", err)
+		}
+		if scenario.rcvError != "" && (err == nil || !strings.Contains(err.Error(), scenario.rcvError)) {
+			t.Errorf("expected error %v got %v", scenario.rcvError, err)
+		}
+	}
+
+}
+
+func Test_NewHTTPTransportWithTLS_pinMismatch(t *testing.T) {
+	server, caFile := testTLSServer(t)
+	defer server.Close()
+	defer os.RemoveAll(filepath.Dir(caFile))
+
+	transport, err := NewHTTPTransportWithTLS(true, &TLSOptions{CAFiles: []string{caFile}, PinnedSHA256: []string{strings.Repeat("00:", 31) + "00"}})
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	config, _ := NewNsoJsonRpcConfigWithTransport("https", "127.0.0.1", testServerPort(server), "admin", "admin", transport)
+
+	err = config.NsoLogin()
+	if !errors.Is(err, ErrCertificatePinMismatch) {
+		t.Errorf("expected error %v got %v", ErrCertificatePinMismatch, err)
+	}
+
+}
+
+func Test_TLSOptions_badOptions(t *testing.T) {
+	dir, err := ioutil.TempDir("", "nsotls")
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	notPEM := filepath.Join(dir, "ca.pem")
+	_ = ioutil.WriteFile(notPEM, []byte("not a certificate"), 0600)
+
+	scenarios := []struct {
+		options  TLSOptions
+		rcvError string
+	}{
+		{options: TLSOptions{CAFiles: []string{filepath.Join(dir, "missing.pem")}}, rcvError: "could not read CA file"},
+		{options: TLSOptions{CAFiles: []string{notPEM}}, rcvError: fmt.Sprintf("no certificates found in CA file %s", notPEM)},
+		{options: TLSOptions{CertFile: "cert.pem"}, rcvError: "a client certificate needs both a certificate and a key file"},
+		{options: TLSOptions{CertFile: notPEM, KeyFile: notPEM}, rcvError: "could not load client certificate"},
+		{options: TLSOptions{MinVersion: 0x0200}, rcvError: "unsupported minimum TLS version 0x200"},
+		{options: TLSOptions{PinnedSHA256: []string{"abcd"}}, rcvError: "pinned fingerprint abcd is not a SHA-256 hex digest"},
+	}
+
+	for _, scenario := range scenarios {
+		_, err := scenario.options.TLSConfig()
+		if err == nil || !strings.HasPrefix(err.Error(), scenario.rcvError) {
+			t.Errorf("expected error %v got %v", scenario.rcvError, err)
+		}
+	}
+
+}
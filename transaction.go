@@ -0,0 +1,1397 @@
+package nsojsonrpcrequestergo
+
+import (
+	"context"
+	"errors"
+)
+
+// Transaction holds a NSO Transaction handle and the data methods that use it
+// more than one Transaction can be open in the same login session
+type Transaction struct {
+	nsocon                                    *nsoJsonConnection
+	th                                        float64
+	db, mode, confMode, tag, onPendingChanges string
+	lost                                      bool
+}
+
+// Constructor for a Transaction
+//   :values nsocon: The nsoJsonConnection the transaction was started on
+//   :values th: The transaction handle NSO returned
+//   :values db: running, startup, or candidate
+//   :values mode: read, or read_write
+//   :values confMode: private, shared, or exclusive
+//   :values tag: "" or a value
+//   :values onPendingChanges: reuse, reject, or discard
+func newTransaction(nsocon *nsoJsonConnection, th float64, db, mode, confMode, tag, onPendingChanges string) *Transaction {
+
+	return &Transaction{nsocon: nsocon, th: th, db: db, mode: mode, confMode: confMode, tag: tag, onPendingChanges: onPendingChanges}
+
+}
+
+// Method to get the transaction handle
+// 0 is returned once the transaction has been deleted
+func (trans *Transaction) Handle() float64 {
+	return trans.handle()
+
+}
+
+// Method to get the transaction handle under the session lock, a re-login changes the handle of a read transaction
+func (trans *Transaction) handle() float64 {
+	trans.nsocon.sessionLock.Lock()
+	defer trans.nsocon.sessionLock.Unlock()
+
+	return trans.th
+
+}
+
+// Method to set the transaction handle under the session lock
+//   :values th: The transaction handle
+func (trans *Transaction) setHandle(th float64) {
+	trans.nsocon.sessionLock.Lock()
+	defer trans.nsocon.sessionLock.Unlock()
+
+	trans.th = th
+
+}
+
+// Method to send a POST request on the transaction using a context
+//   :values ctx: A context.Context to cancel the request
+//   :values param: A rpcParam with the transaction handle
+func (trans *Transaction) sendPostContext(ctx context.Context, param rpcParam) (*NsoJsonResponse, error) {
+	return trans.nsocon.sendTransactionPostContext(ctx, trans, param)
+
+}
+
+// Method to get the datastore the transaction was started on
+func (trans *Transaction) DB() string {
+	return trans.db
+
+}
+
+// Method to get the transaction mode
+func (trans *Transaction) Mode() string {
+	return trans.mode
+
+}
+
+// Method to delete the NSO Transaction
+func (trans *Transaction) DeleteTransaction() error {
+	err := trans.DeleteTransactionContext(context.Background())
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Method to delete the NSO Transaction using a context
+//   :values ctx: A context.Context to cancel the request
+func (trans *Transaction) DeleteTransactionContext(ctx context.Context) error {
+	param := rpcParam{
+		"jsonrpc": "2.0",
+		"method":  "delete_trans",
+		"params": map[string]interface{}{
+			"th": trans.handle(),
+		},
+	}
+
+	_, err := trans.sendPostContext(ctx, param)
+
+	if err != nil {
+		return err
+	}
+
+	trans.nsocon.unregisterTransaction(trans)
+	trans.setHandle(0)
+
+	return nil
+}
+
+// Method to set a comment on the NSO Transaction
+// The comment is shown in the NSO commit log
+//   :values comment: A comment
+func (trans *Transaction) SetComment(comment string) (*NsoJsonResponse, error) {
+	response, err := trans.SetCommentContext(context.Background(), comment)
+
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+// Method to set a comment on the NSO Transaction using a context
+// The comment is shown in the NSO commit log
+//   :values ctx: A context.Context to cancel the request
+//   :values comment: A comment
+func (trans *Transaction) SetCommentContext(ctx context.Context, comment string) (*NsoJsonResponse, error) {
+	param := rpcParam{
+		"jsonrpc": "2.0",
+		"method":  "set_trans_comment",
+		"params": map[string]interface{}{
+			"th":      trans.handle(),
+			"comment": comment,
+		},
+	}
+
+	response, err := trans.sendPostContext(ctx, param)
+
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+// Method to set a label on the NSO Transaction
+// The label is shown in the NSO commit log
+//   :values label: A label
+func (trans *Transaction) SetLabel(label string) (*NsoJsonResponse, error) {
+	response, err := trans.SetLabelContext(context.Background(), label)
+
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+// Method to set a label on the NSO Transaction using a context
+// The label is shown in the NSO commit log
+//   :values ctx: A context.Context to cancel the request
+//   :values label: A label
+func (trans *Transaction) SetLabelContext(ctx context.Context, label string) (*NsoJsonResponse, error) {
+	param := rpcParam{
+		"jsonrpc": "2.0",
+		"method":  "set_trans_label",
+		"params": map[string]interface{}{
+			"th":    trans.handle(),
+			"label": label,
+		},
+	}
+
+	response, err := trans.sendPostContext(ctx, param)
+
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+// Method to check if the NSO Transaction has been modified
+func (trans *Transaction) IsModified() (bool, error) {
+	result, err := trans.IsModifiedContext(context.Background())
+
+	if err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
+
+// Method to check if the NSO Transaction has been modified using a context
+//   :values ctx: A context.Context to cancel the request
+func (trans *Transaction) IsModifiedContext(ctx context.Context) (bool, error) {
+	param := rpcParam{
+		"jsonrpc": "2.0",
+		"method":  "is_trans_modified",
+		"params": map[string]interface{}{
+			"th": trans.handle(),
+		},
+	}
+
+	response, err := trans.sendPostContext(ctx, param)
+
+	if err != nil {
+		return false, err
+	}
+
+	modified, _ := response.Result["modified"].(bool)
+
+	return modified, nil
+}
+
+// Method to get the changes made in the NSO Transaction
+func (trans *Transaction) GetChanges() (*NsoJsonResponse, error) {
+	response, err := trans.GetChangesContext(context.Background())
+
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+// Method to get the changes made in the NSO Transaction using a context
+//   :values ctx: A context.Context to cancel the request
+func (trans *Transaction) GetChangesContext(ctx context.Context) (*NsoJsonResponse, error) {
+	param := rpcParam{
+		"jsonrpc": "2.0",
+		"method":  "get_trans_changes",
+		"params": map[string]interface{}{
+			"th": trans.handle(),
+		},
+	}
+
+	response, err := trans.sendPostContext(ctx, param)
+
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+// Method to get the conflicts registered in the NSO Transaction
+func (trans *Transaction) GetConflicts() (*NsoJsonResponse, error) {
+	response, err := trans.GetConflictsContext(context.Background())
+
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+// Method to get the conflicts registered in the NSO Transaction using a context
+//   :values ctx: A context.Context to cancel the request
+func (trans *Transaction) GetConflictsContext(ctx context.Context) (*NsoJsonResponse, error) {
+	param := rpcParam{
+		"jsonrpc": "2.0",
+		"method":  "get_trans_conflicts",
+		"params": map[string]interface{}{
+			"th": trans.handle(),
+		},
+	}
+
+	response, err := trans.sendPostContext(ctx, param)
+
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+// Method to resolve the conflicts registered in the NSO Transaction
+func (trans *Transaction) ResolveConflicts() (*NsoJsonResponse, error) {
+	response, err := trans.ResolveConflictsContext(context.Background())
+
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+// Method to resolve the conflicts registered in the NSO Transaction using a context
+//   :values ctx: A context.Context to cancel the request
+func (trans *Transaction) ResolveConflictsContext(ctx context.Context) (*NsoJsonResponse, error) {
+	param := rpcParam{
+		"jsonrpc": "2.0",
+		"method":  "resolve_trans_conflicts",
+		"params": map[string]interface{}{
+			"th": trans.handle(),
+		},
+	}
+
+	response, err := trans.sendPostContext(ctx, param)
+
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+// Method to evaluate a xpath expression
+//   :values xpathExpression: An xpath expression
+func (trans *Transaction) EvalXPATH(xpathExpression string) (*NsoJsonResponse, error) {
+	response, err := trans.EvalXPATHContext(context.Background(), xpathExpression)
+
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+// Method to evaluate a xpath expression using a context
+//   :values ctx: A context.Context to cancel the request
+//   :values xpathExpression: An xpath expression
+func (trans *Transaction) EvalXPATHContext(ctx context.Context, xpathExpression string) (*NsoJsonResponse, error) {
+	param := rpcParam{
+		"jsonrpc": "2.0",
+		"method":  "eval_xpath",
+		"params": map[string]interface{}{
+			"th":         trans.handle(),
+			"xpath_expr": xpathExpression,
+		},
+	}
+
+	response, err := trans.sendPostContext(ctx, param)
+
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+// Method to show NSO config
+//   :values path: A key path
+//   :values resultAs: string, or json
+//   :values withOper: true for operational data false for not
+//   :values maxSize: 0 to disable limit any other number to limit
+func (trans *Transaction) ShowConfig(path, resultAs string, withOper bool, maxSize int) (*NsoJsonResponse, error) {
+	response, err := trans.ShowConfigContext(context.Background(), path, resultAs, withOper, maxSize)
+
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+// Method to show NSO config using a context
+//   :values ctx: A context.Context to cancel the request
+//   :values path: A key path
+//   :values resultAs: string, or json
+//   :values withOper: true for operational data false for not
+//   :values maxSize: 0 to disable limit any other number to limit
+func (trans *Transaction) ShowConfigContext(ctx context.Context, path, resultAs string, withOper bool, maxSize int) (*NsoJsonResponse, error) {
+	param := rpcParam{
+		"jsonrpc": "2.0",
+		"method":  "show_config",
+		"params": map[string]interface{}{
+			"th":        trans.handle(),
+			"path":      path,
+			"result_as": resultAs,
+			"with_oper": withOper,
+			"max_size":  maxSize,
+		},
+	}
+
+	response, err := trans.sendPostContext(ctx, param)
+
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+// Method to deref NSO config
+//   :values path: A key path
+//   :values resultAs: paths, target, or list-target
+func (trans *Transaction) Deref(path, resultAs string) (*NsoJsonResponse, error) {
+	response, err := trans.DerefContext(context.Background(), path, resultAs)
+
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+// Method to deref NSO config using a context
+//   :values ctx: A context.Context to cancel the request
+//   :values path: A key path
+//   :values resultAs: paths, target, or list-target
+func (trans *Transaction) DerefContext(ctx context.Context, path, resultAs string) (*NsoJsonResponse, error) {
+	param := rpcParam{
+		"jsonrpc": "2.0",
+		"method":  "deref",
+		"params": map[string]interface{}{
+			"th":        trans.handle(),
+			"path":      path,
+			"result_as": resultAs,
+		},
+	}
+
+	response, err := trans.sendPostContext(ctx, param)
+
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+// Method to get leaf reference values
+//   :values path: A key path
+//   :values skipGrouping: true to skip grouping false to not
+//   :values keys: array of keys
+func (trans *Transaction) GetLeafrefValues(path string, skipGrouping bool, keys []string) (*NsoJsonResponse, error) {
+	response, err := trans.GetLeafrefValuesContext(context.Background(), path, skipGrouping, keys)
+
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+// Method to get leaf reference values using a context
+//   :values ctx: A context.Context to cancel the request
+//   :values path: A key path
+//   :values skipGrouping: true to skip grouping false to not
+//   :values keys: array of keys
+func (trans *Transaction) GetLeafrefValuesContext(ctx context.Context, path string, skipGrouping bool, keys []string) (*NsoJsonResponse, error) {
+	param := rpcParam{
+		"jsonrpc": "2.0",
+		"method":  "get_leafref_values",
+		"params": map[string]interface{}{
+			"th":            trans.handle(),
+			"path":          path,
+			"skip_grouping": skipGrouping,
+			"keys":          keys,
+		},
+	}
+
+	response, err := trans.sendPostContext(ctx, param)
+
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+// Method to run an action
+//   :values path: A key path
+//   :values inputData: A map of data
+func (trans *Transaction) RunAction(path string, inputData map[string]interface{}) (*NsoJsonResponse, error) {
+	response, err := trans.RunActionContext(context.Background(), path, inputData)
+
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+// Method to run an action using a context
+//   :values ctx: A context.Context to cancel the request
+//   :values path: A key path
+//   :values inputData: A map of data
+func (trans *Transaction) RunActionContext(ctx context.Context, path string, inputData map[string]interface{}) (*NsoJsonResponse, error) {
+	param := rpcParam{
+		"jsonrpc": "2.0",
+		"method":  "run_action",
+		"params": map[string]interface{}{
+			"th":     trans.handle(),
+			"path":   path,
+			"params": inputData,
+		},
+	}
+
+	response, err := trans.sendPostContext(ctx, param)
+
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+// Method to get a schema
+//   :values path: A key path
+func (trans *Transaction) GetSchema(path string) (*NsoJsonResponse, error) {
+	response, err := trans.GetSchemaContext(context.Background(), path)
+
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+// Method to get a schema using a context
+//   :values ctx: A context.Context to cancel the request
+//   :values path: A key path
+func (trans *Transaction) GetSchemaContext(ctx context.Context, path string) (*NsoJsonResponse, error) {
+	param := rpcParam{
+		"jsonrpc": "2.0",
+		"method":  "get_schema",
+		"params": map[string]interface{}{
+			"th":   trans.handle(),
+			"path": path,
+		},
+	}
+
+	response, err := trans.sendPostContext(ctx, param)
+
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+// Method to get a list of keys
+//   :values path: A key path
+func (trans *Transaction) GetListKeys(path string) (*NsoJsonResponse, error) {
+	response, err := trans.GetListKeysContext(context.Background(), path)
+
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+// Method to get a list of keys using a context
+//   :values ctx: A context.Context to cancel the request
+//   :values path: A key path
+func (trans *Transaction) GetListKeysContext(ctx context.Context, path string) (*NsoJsonResponse, error) {
+	param := rpcParam{
+		"jsonrpc": "2.0",
+		"method":  "get_list_keys",
+		"params": map[string]interface{}{
+			"th":   trans.handle(),
+			"path": path,
+		},
+	}
+
+	response, err := trans.sendPostContext(ctx, param)
+
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+// Method to get a leaf value
+//   :values path: A key path
+//   :values checkDefault: true to check for default value false to not
+func (trans *Transaction) GetValue(path string, checkDefault bool) (*NsoJsonResponse, error) {
+	response, err := trans.GetValueContext(context.Background(), path, checkDefault)
+
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+// Method to get a leaf value using a context
+//   :values ctx: A context.Context to cancel the request
+//   :values path: A key path
+//   :values checkDefault: true to check for default value false to not
+func (trans *Transaction) GetValueContext(ctx context.Context, path string, checkDefault bool) (*NsoJsonResponse, error) {
+	param := rpcParam{
+		"jsonrpc": "2.0",
+		"method":  "get_value",
+		"params": map[string]interface{}{
+			"th":            trans.handle(),
+			"path":          path,
+			"check_default": checkDefault,
+		},
+	}
+
+	response, err := trans.sendPostContext(ctx, param)
+
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+// Method to get multiple leaf values
+//   :values path: A key path
+//   :values leafs: A array of leafs
+//   :values checkDefault: true to check for default value false to not
+func (trans *Transaction) GetValues(path string, leafs []string, checkDefault bool) (*NsoJsonResponse, error) {
+	response, err := trans.GetValuesContext(context.Background(), path, leafs, checkDefault)
+
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+// Method to get multiple leaf values using a context
+//   :values ctx: A context.Context to cancel the request
+//   :values path: A key path
+//   :values leafs: A array of leafs
+//   :values checkDefault: true to check for default value false to not
+func (trans *Transaction) GetValuesContext(ctx context.Context, path string, leafs []string, checkDefault bool) (*NsoJsonResponse, error) {
+	param := rpcParam{
+		"jsonrpc": "2.0",
+		"method":  "get_values",
+		"params": map[string]interface{}{
+			"th":            trans.handle(),
+			"path":          path,
+			"check_default": checkDefault,
+			"leafs":         leafs,
+		},
+	}
+
+	response, err := trans.sendPostContext(ctx, param)
+
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+// Method to create a leaf
+//   :values path: A key path
+func (trans *Transaction) Create(path string) (*NsoJsonResponse, error) {
+	response, err := trans.CreateContext(context.Background(), path)
+
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+// Method to create a leaf using a context
+//   :values ctx: A context.Context to cancel the request
+//   :values path: A key path
+func (trans *Transaction) CreateContext(ctx context.Context, path string) (*NsoJsonResponse, error) {
+	param := rpcParam{
+		"jsonrpc": "2.0",
+		"method":  "create",
+		"params": map[string]interface{}{
+			"th":   trans.handle(),
+			"path": path,
+		},
+	}
+
+	response, err := trans.sendPostContext(ctx, param)
+
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+// Method to check if a leaf exists
+//   :values path: A key path
+func (trans *Transaction) Exists(path string) (*NsoJsonResponse, error) {
+	response, err := trans.ExistsContext(context.Background(), path)
+
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+// Method to check if a leaf exists using a context
+//   :values ctx: A context.Context to cancel the request
+//   :values path: A key path
+func (trans *Transaction) ExistsContext(ctx context.Context, path string) (*NsoJsonResponse, error) {
+	param := rpcParam{
+		"jsonrpc": "2.0",
+		"method":  "exists",
+		"params": map[string]interface{}{
+			"th":   trans.handle(),
+			"path": path,
+		},
+	}
+
+	response, err := trans.sendPostContext(ctx, param)
+
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+// Method to get a choice/case
+//   :values path: A key path
+//   :values choice: A choice from a case
+func (trans *Transaction) GetCase(path, choice string) (*NsoJsonResponse, error) {
+	response, err := trans.GetCaseContext(context.Background(), path, choice)
+
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+// Method to get a choice/case using a context
+//   :values ctx: A context.Context to cancel the request
+//   :values path: A key path
+//   :values choice: A choice from a case
+func (trans *Transaction) GetCaseContext(ctx context.Context, path, choice string) (*NsoJsonResponse, error) {
+	param := rpcParam{
+		"jsonrpc": "2.0",
+		"method":  "get_case",
+		"params": map[string]interface{}{
+			"th":     trans.handle(),
+			"path":   path,
+			"choice": choice,
+		},
+	}
+
+	response, err := trans.sendPostContext(ctx, param)
+
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+// Method to load data to NSO
+//   :values data: The data to be loaded
+//   :values path: A key path use "/" at the very least
+//   :values dataFormat: json, or xml
+//   :values mode: create, merge, or replace
+func (trans *Transaction) Load(data, path, dataFormat, mode string) (*NsoJsonResponse, error) {
+	response, err := trans.LoadContext(context.Background(), data, path, dataFormat, mode)
+
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+// Method to load data to NSO using a context
+//   :values ctx: A context.Context to cancel the request
+//   :values data: The data to be loaded
+//   :values path: A key path use "/" at the very least
+//   :values dataFormat: json, or xml
+//   :values mode: create, merge, or replace
+func (trans *Transaction) LoadContext(ctx context.Context, data, path, dataFormat, mode string) (*NsoJsonResponse, error) {
+	param := rpcParam{
+		"jsonrpc": "2.0",
+		"method":  "load",
+		"params": map[string]interface{}{
+			"th":     trans.handle(),
+			"data":   data,
+			"path":   path,
+			"format": dataFormat,
+			"mode":   mode,
+		},
+	}
+
+	response, err := trans.sendPostContext(ctx, param)
+
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+// Method to set a value
+//   :values path: A key path
+//   :values value: What you want to set
+//   :values dryRun: true for dryrun false for not
+func (trans *Transaction) SetValue(path string, value interface{}, dryRun bool) (*NsoJsonResponse, error) {
+	response, err := trans.SetValueContext(context.Background(), path, value, dryRun)
+
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+// Method to set a value using a context
+//   :values ctx: A context.Context to cancel the request
+//   :values path: A key path
+//   :values value: What you want to set
+//   :values dryRun: true for dryrun false for not
+func (trans *Transaction) SetValueContext(ctx context.Context, path string, value interface{}, dryRun bool) (*NsoJsonResponse, error) {
+	param := rpcParam{
+		"jsonrpc": "2.0",
+		"method":  "set_value",
+		"params": map[string]interface{}{
+			"th":     trans.handle(),
+			"path":   path,
+			"value":  value,
+			"dryrun": dryRun,
+		},
+	}
+
+	response, err := trans.sendPostContext(ctx, param)
+
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+// Method to validate a commit
+//    In the CLI commits are validated automatically, in JsonRPC
+//    they are not, but only validated commits can be committed
+func (trans *Transaction) ValidateCommit() (*NsoJsonResponse, error) {
+	response, err := trans.ValidateCommitContext(context.Background())
+
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+// Method to validate a commit using a context
+//    In the CLI commits are validated automatically, in JsonRPC
+//    they are not, but only validated commits can be committed
+//   :values ctx: A context.Context to cancel the request
+func (trans *Transaction) ValidateCommitContext(ctx context.Context) (*NsoJsonResponse, error) {
+	param := rpcParam{
+		"jsonrpc": "2.0",
+		"method":  "validate_commit",
+		"params": map[string]interface{}{
+			"th": trans.handle(),
+		},
+	}
+
+	response, err := trans.sendPostContext(ctx, param)
+
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+// Method to commit
+//   :values dryRun: true for dryrun false for not
+//   :values output: cli, native, or xml, empty is cli
+//   :values reverse: true for reverse diff false for forward diff
+//                    config only can be used with native
+func (trans *Transaction) Commit(dryRun bool, output string, reverse bool) (*NsoJsonResponse, error) {
+	response, err := trans.CommitContext(context.Background(), dryRun, output, reverse)
+
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+// Method to commit using a context
+//   :values ctx: A context.Context to cancel the request
+//   :values dryRun: true for dryrun false for not
+//   :values output: cli, native, or xml, empty is cli
+//   :values reverse: true for reverse diff false for forward diff
+//                    config only can be used with native
+func (trans *Transaction) CommitContext(ctx context.Context, dryRun bool, output string, reverse bool) (*NsoJsonResponse, error) {
+	opts := &CommitOptions{}
+
+	if dryRun == true {
+		// An empty output is still a dry run, NSO shows it as cli
+		opts.DryRun = output
+		if output == "" {
+			opts.DryRun = "cli"
+		}
+
+		if output == "native" && reverse == true {
+			opts.DryRunReverse = true
+		}
+
+	}
+
+	response, err := trans.CommitWithOptionsContext(ctx, opts)
+
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+// Method to commit with a set of CommitOptions
+//   :values opts: The CommitOptions, nil for a plain commit
+func (trans *Transaction) CommitWithOptions(opts *CommitOptions) (*NsoJsonResponse, error) {
+	response, err := trans.CommitWithOptionsContext(context.Background(), opts)
+
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+// Method to commit with a set of CommitOptions using a context
+//   :values ctx: A context.Context to cancel the request
+//   :values opts: The CommitOptions, nil for a plain commit
+func (trans *Transaction) CommitWithOptionsContext(ctx context.Context, opts *CommitOptions) (*NsoJsonResponse, error) {
+	if opts == nil {
+		opts = &CommitOptions{}
+	}
+
+	flags, err := opts.Flags()
+
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := trans.commitContext(ctx, flags)
+
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+// Method to do a dry-run commit and parse the result per device
+//   :values output: cli, native, or xml, empty is cli
+//   :values reverse: true for reverse diff false for forward diff
+//                    config only can be used with native
+func (trans *Transaction) DryRun(output string, reverse bool) (*DryRunResult, error) {
+	result, err := trans.DryRunContext(context.Background(), output, reverse)
+
+	if err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
+
+// Method to do a dry-run commit and parse the result per device using a context
+//   :values ctx: A context.Context to cancel the request
+//   :values output: cli, native, or xml, empty is cli
+//   :values reverse: true for reverse diff false for forward diff
+//                    config only can be used with native
+func (trans *Transaction) DryRunContext(ctx context.Context, output string, reverse bool) (*DryRunResult, error) {
+	// An empty output is still a dry run, NSO shows it as cli
+	if output == "" {
+		output = "cli"
+	}
+
+	opts := &CommitOptions{DryRun: output}
+
+	if output == "native" && reverse == true {
+		opts.DryRunReverse = true
+	}
+
+	response, err := trans.CommitWithOptionsContext(ctx, opts)
+
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := NewDryRunResult(output, response)
+
+	if err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
+
+// Method to do a confirmed commit
+// The commit is rolled back by NSO unless ConfirmCommit is called before the timeout
+//   :values timeout: Seconds to wait for ConfirmCommit, must be greater than 0
+func (trans *Transaction) CommitConfirmed(timeout int) (*NsoJsonResponse, error) {
+	response, err := trans.CommitConfirmedContext(context.Background(), timeout)
+
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+// Method to do a confirmed commit using a context
+// The commit is rolled back by NSO unless ConfirmCommit is called before the timeout
+//   :values ctx: A context.Context to cancel the request
+//   :values timeout: Seconds to wait for ConfirmCommit, must be greater than 0
+func (trans *Transaction) CommitConfirmedContext(ctx context.Context, timeout int) (*NsoJsonResponse, error) {
+	if timeout < 1 {
+		return nil, errors.New("confirmed commit timeout must be greater than 0")
+	}
+
+	response, err := trans.CommitWithOptionsContext(ctx, &CommitOptions{Confirmed: true, ConfirmTimeout: timeout})
+
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+// Method to send a commit with a set of flags
+//   :values ctx: A context.Context to cancel the request
+//   :values flags: The commit flags
+func (trans *Transaction) commitContext(ctx context.Context, flags []string) (*NsoJsonResponse, error) {
+	param := rpcParam{
+		"jsonrpc": "2.0",
+		"method":  "commit",
+		"params": map[string]interface{}{
+			"th":    trans.handle(),
+			"flags": flags,
+		},
+	}
+
+	response, err := trans.sendPostContext(ctx, param)
+
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+// Method to load a rollback file into the NSO Transaction
+// The changes are applied when the transaction is committed
+//   :values number: The rollback number
+//   :values selective: true to only undo that one commit, false to undo it and all newer commits
+func (trans *Transaction) LoadRollback(number int, selective bool) (*NsoJsonResponse, error) {
+	response, err := trans.LoadRollbackContext(context.Background(), number, selective)
+
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+// Method to load a rollback file into the NSO Transaction using a context
+// The changes are applied when the transaction is committed
+//   :values ctx: A context.Context to cancel the request
+//   :values number: The rollback number
+//   :values selective: true to only undo that one commit, false to undo it and all newer commits
+func (trans *Transaction) LoadRollbackContext(ctx context.Context, number int, selective bool) (*NsoJsonResponse, error) {
+	param := rpcParam{
+		"jsonrpc": "2.0",
+		"method":  "load_rollback",
+		"params": map[string]interface{}{
+			"th":        trans.handle(),
+			"nr":        number,
+			"selective": selective,
+		},
+	}
+
+	response, err := trans.sendPostContext(ctx, param)
+
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+// Method to install a rollback file into the NSO Transaction
+// The changes are applied when the transaction is committed
+//   :values number: The rollback number
+func (trans *Transaction) InstallRollback(number int) (*NsoJsonResponse, error) {
+	response, err := trans.InstallRollbackContext(context.Background(), number)
+
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+// Method to install a rollback file into the NSO Transaction using a context
+// The changes are applied when the transaction is committed
+//   :values ctx: A context.Context to cancel the request
+//   :values number: The rollback number
+func (trans *Transaction) InstallRollbackContext(ctx context.Context, number int) (*NsoJsonResponse, error) {
+	param := rpcParam{
+		"jsonrpc": "2.0",
+		"method":  "install_rollback",
+		"params": map[string]interface{}{
+			"th": trans.handle(),
+			"nr": number,
+		},
+	}
+
+	response, err := trans.sendPostContext(ctx, param)
+
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+// Method to delete a path
+//   :values path: A key path
+func (trans *Transaction) Delete(path string) (*NsoJsonResponse, error) {
+	response, err := trans.DeleteContext(context.Background(), path)
+
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+// Method to delete a path using a context
+//   :values ctx: A context.Context to cancel the request
+//   :values path: A key path
+func (trans *Transaction) DeleteContext(ctx context.Context, path string) (*NsoJsonResponse, error) {
+	param := rpcParam{
+		"jsonrpc": "2.0",
+		"method":  "delete",
+		"params": map[string]interface{}{
+			"th":   trans.handle(),
+			"path": path,
+		},
+	}
+
+	response, err := trans.sendPostContext(ctx, param)
+
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+// Method to get template variables
+// This is not xml template variables it is templates in NSO
+//   :values name: The name of the template
+func (trans *Transaction) GetTemplateVariables(name string) (*NsoJsonResponse, error) {
+	response, err := trans.GetTemplateVariablesContext(context.Background(), name)
+
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+// Method to get template variables using a context
+// This is not xml template variables it is templates in NSO
+//   :values ctx: A context.Context to cancel the request
+//   :values name: The name of the template
+func (trans *Transaction) GetTemplateVariablesContext(ctx context.Context, name string) (*NsoJsonResponse, error) {
+	param := rpcParam{
+		"jsonrpc": "2.0",
+		"method":  "get_template_variables",
+		"params": map[string]interface{}{
+			"th":   trans.handle(),
+			"name": name,
+		},
+	}
+
+	response, err := trans.sendPostContext(ctx, param)
+
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+// Method for a basic Query in NSO
+// This is a convenience method for calling
+// start_query, run_query and stop_query This method should not be used for paginated
+// results, as it results in performance degradation - use start_query, multiple
+// run_query and stop_query instead.
+//   :values xpathExpression: A XPATH expression
+//   :values resultAs: string, keypath-value, or leaf_value_as_string
+func (trans *Transaction) Query(xpathExpression, resultAs string) (*NsoJsonResponse, error) {
+	response, err := trans.QueryContext(context.Background(), xpathExpression, resultAs)
+
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+// Method for a basic Query in NSO using a context
+// This is a convenience method for calling
+// start_query, run_query and stop_query This method should not be used for paginated
+// results, as it results in performance degradation - use start_query, multiple
+// run_query and stop_query instead.
+//   :values ctx: A context.Context to cancel the request
+//   :values xpathExpression: A XPATH expression
+//   :values resultAs: string, keypath-value, or leaf_value_as_string
+func (trans *Transaction) QueryContext(ctx context.Context, xpathExpression, resultAs string) (*NsoJsonResponse, error) {
+	param := rpcParam{
+		"jsonrpc": "2.0",
+		"method":  "query",
+		"params": map[string]interface{}{
+			"th":         trans.handle(),
+			"xpath_expr": xpathExpression,
+			"result_as":  resultAs,
+		},
+	}
+
+	response, err := trans.sendPostContext(ctx, param)
+
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+// Method to start a complex query
+//   :vaules QueryObject: A QueryObject
+func (trans *Transaction) StartQuery(queryObject *QueryObject) error {
+	err := trans.StartQueryContext(context.Background(), queryObject)
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Method to start a complex query using a context
+//   :vaules QueryObject: A QueryObject
+//   :values ctx: A context.Context to cancel the request
+func (trans *Transaction) StartQueryContext(ctx context.Context, queryObject *QueryObject) error {
+	params := map[string]interface{}{
+		"th": trans.handle(),
+	}
+	if queryObject.xpathExpression != "" {
+		params["xpath_expr"] = queryObject.xpathExpression
+		if len(queryObject.selection) > 0 {
+			params["selection"] = queryObject.selection
+		}
+
+		if len(queryObject.sort) > 0 {
+			params["sort"] = queryObject.sort
+		}
+
+	} else {
+		params["path"] = queryObject.path
+		if queryObject.contextNode != "" {
+			params["context_node"] = queryObject.contextNode
+		}
+
+	}
+	params["chunk_size"] = queryObject.chunkSize
+	params["initial_offset"] = queryObject.initialOffset
+	if queryObject.sortOrder != "" {
+		params["sort_order"] = queryObject.sortOrder
+	}
+
+	params["include_total"] = queryObject.includeTotal
+	params["result_as"] = queryObject.resultAs
+
+	param := rpcParam{
+		"jsonrpc": "2.0",
+		"method":  "start_query",
+		"params":  params,
+	}
+
+	response, err := trans.sendPostContext(ctx, param)
+
+	if err != nil {
+		return err
+	}
+
+	queryObject.qh = response.GetQueryHandle()
+
+	return nil
+}
+
+// Method to run a complex query
+//   :values queryHandle: A Query Handle this comes from using the StartQuery method
+func (trans *Transaction) RunQuery(queryObject *QueryObject) (*NsoJsonResponse, error) {
+	response, err := trans.RunQueryContext(context.Background(), queryObject)
+
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+// Method to run a complex query using a context
+//   :values ctx: A context.Context to cancel the request
+//   :values queryHandle: A Query Handle this comes from using the StartQuery method
+func (trans *Transaction) RunQueryContext(ctx context.Context, queryObject *QueryObject) (*NsoJsonResponse, error) {
+	param := rpcParam{
+		"jsonrpc": "2.0",
+		"method":  "run_query",
+		"params": map[string]interface{}{
+			"qh": queryObject.qh,
+		},
+	}
+
+	response, err := trans.sendPostContext(ctx, param)
+
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+// Method to reset a complex query
+//   :values queryHandle: A Query Handle this comes from using the StartQuery method
+func (trans *Transaction) ResetQuery(queryObject *QueryObject) (*NsoJsonResponse, error) {
+	response, err := trans.ResetQueryContext(context.Background(), queryObject)
+
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+// Method to reset a complex query using a context
+//   :values ctx: A context.Context to cancel the request
+//   :values queryHandle: A Query Handle this comes from using the StartQuery method
+func (trans *Transaction) ResetQueryContext(ctx context.Context, queryObject *QueryObject) (*NsoJsonResponse, error) {
+	param := rpcParam{
+		"jsonrpc": "2.0",
+		"method":  "reset_query",
+		"params": map[string]interface{}{
+			"qh": queryObject.qh,
+		},
+	}
+
+	response, err := trans.sendPostContext(ctx, param)
+
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+// Method to stop a complex query
+//   :values queryHandle: A Query Handle this comes from using the StartQuery method
+func (trans *Transaction) StopQuery(queryObject *QueryObject) error {
+	err := trans.StopQueryContext(context.Background(), queryObject)
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Method to stop a complex query using a context
+//   :values ctx: A context.Context to cancel the request
+//   :values queryHandle: A Query Handle this comes from using the StartQuery method
+func (trans *Transaction) StopQueryContext(ctx context.Context, queryObject *QueryObject) error {
+	param := rpcParam{
+		"jsonrpc": "2.0",
+		"method":  "stop_query",
+		"params": map[string]interface{}{
+			"qh": queryObject.qh,
+		},
+	}
+
+	_, err := trans.sendPostContext(ctx, param)
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,236 @@
+package nsojsonrpcrequestergo
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestTransaction_sideBySide(t *testing.T) {
+	var ths []interface{}
+	nextTh := 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			ID     int                    `json:"id"`
+			Method string                 `json:"method"`
+			Params map[string]interface{} `json:"params"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+
+		result := "{}"
+		switch body.Method {
+		case "new_trans":
+			nextTh++
+			result = fmt.Sprintf(`{"th": %d}`, nextTh)
+		case "get_value", "set_value":
+			ths = append(ths, body.Params["th"])
+		}
+
+		_, _ = w.Write([]byte(fmt.Sprintf(`{"jsonrpc": "2.0", "id": %d, "result": %s}`, body.ID, result)))
+	}))
+	defer server.Close()
+
+	config, err := NewNsoJsonRpcConfig("http", "127.0.0.1", testServerPort(server), "admin", "admin", false)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	_, err = config.GetValue("/ncs:devices/device{ce0}/address", false)
+	if err == nil {
+		t.Errorf("expected an error when no transaction was started")
+	}
+
+	_ = config.NsoLogin()
+	readTrans, _ := config.NewTransaction("read", "private", "", "reuse")
+	writeTrans, _ := config.NewTransaction("read_write", "private", "", "reuse")
+
+	_, _ = readTrans.GetValue("/ncs:devices/device{ce0}/address", false)
+	_, _ = writeTrans.SetValue("/ncs:devices/device{ce0}/address", "10.0.0.1", false)
+	_, _ = config.GetValue("/ncs:devices/device{ce0}/address", false)
+
+	expect := []interface{}{float64(1), float64(2), float64(2)}
+	if fmt.Sprint(ths) != fmt.Sprint(expect) {
+		t.Errorf("expected %v got %v", expect, ths)
+	}
+	if config.TransactionHandle() != 2 {
+		t.Errorf("expected %v got %v", 2, config.TransactionHandle())
+	}
+
+}
+
+func TestNsoJsonRpcConfig_NewTransactionDB(t *testing.T) {
+	scenarios := []struct {
+		db       string
+		rcvError error
+	}{
+		{db: "candidate", rcvError: nil},
+		{db: "startup", rcvError: nil},
+		{db: "operational", rcvError: errors.New("only running, startup, and candidate datastores are supported")},
+	}
+
+	server, _ := testJsonRpcServer(map[string]string{"new_trans": `{"th": 3}`})
+	defer server.Close()
+
+	config, _ := NewNsoJsonRpcConfig("http", "127.0.0.1", testServerPort(server), "admin", "admin", false)
+	_ = config.NsoLogin()
+
+	for _, scenario := range scenarios {
+		trans, err := config.NewTransactionDB(scenario.db, "read_write", "private", "", "reuse")
+		if scenario.rcvError != nil {
+			if err == nil || err.Error() != scenario.rcvError.Error() {
+				t.Errorf("expected error %v got %v", scenario.rcvError, err)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		if trans.DB() != scenario.db {
+			t.Errorf("expected %v got %v", scenario.db, trans.DB())
+		}
+
+	}
+
+	_, err := config.LockDB("running-config")
+	if err == nil {
+		t.Errorf("expected an error for an unknown datastore")
+	}
+
+}
+
+func TestTransaction_Commit_flags(t *testing.T) {
+	var flags interface{}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			ID     int                    `json:"id"`
+			Method string                 `json:"method"`
+			Params map[string]interface{} `json:"params"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+
+		if body.Method == "commit" {
+			flags = body.Params["flags"]
+		}
+
+		_, _ = w.Write([]byte(fmt.Sprintf(`{"jsonrpc": "2.0", "id": %d, "result": {"th": 1}}`, body.ID)))
+	}))
+	defer server.Close()
+
+	config, _ := NewNsoJsonRpcConfig("http", "127.0.0.1", testServerPort(server), "admin", "admin", false)
+	_ = config.NsoLogin()
+	trans, _ := config.NewTransaction("read_write", "private", "", "reuse")
+
+	scenarios := []struct {
+		dryRun  bool
+		output  string
+		reverse bool
+		expect  string
+	}{
+		{dryRun: true, output: "", reverse: false, expect: "[dry-run=cli]"},
+		{dryRun: true, output: "native", reverse: true, expect: "[dry-run=native dry-run-reverse]"},
+		{dryRun: true, output: "xml", reverse: true, expect: "[dry-run=xml]"},
+		{dryRun: false, output: "cli", reverse: false, expect: "<nil>"},
+	}
+
+	for _, scenario := range scenarios {
+		flags = nil
+
+		_, err := trans.Commit(scenario.dryRun, scenario.output, scenario.reverse)
+		if err != nil {
+			t.Errorf("unexpected error %v", err)
+		}
+
+		if fmt.Sprint(flags) != scenario.expect {
+			t.Errorf("expected %v got %v", scenario.expect, flags)
+		}
+	}
+
+}
+
+func TestTransaction_CommitConfirmed(t *testing.T) {
+	var flags interface{}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			ID     int                    `json:"id"`
+			Method string                 `json:"method"`
+			Params map[string]interface{} `json:"params"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+
+		if body.Method == "commit" {
+			flags = body.Params["flags"]
+		}
+
+		_, _ = w.Write([]byte(fmt.Sprintf(`{"jsonrpc": "2.0", "id": %d, "result": {"th": 1}}`, body.ID)))
+	}))
+	defer server.Close()
+
+	config, _ := NewNsoJsonRpcConfig("http", "127.0.0.1", testServerPort(server), "admin", "admin", false)
+	_ = config.NsoLogin()
+	trans, _ := config.NewTransaction("read_write", "private", "", "reuse")
+
+	_, err := trans.CommitConfirmed(0)
+	if err == nil || err.Error() != "confirmed commit timeout must be greater than 0" {
+		t.Errorf("expected error %v got %v", "confirmed commit timeout must be greater than 0", err)
+	}
+
+	_, err = trans.CommitConfirmed(120)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	expect := "[confirmed confirm-timeout=120]"
+	if fmt.Sprint(flags) != expect {
+		t.Errorf("expected %v got %v", expect, flags)
+	}
+
+	_, err = config.ConfirmCommit()
+	if err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+
+}
+
+func TestNsoJsonRpcConfig_CancelConfirmedCommit(t *testing.T) {
+	var method string
+	var params json.RawMessage
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			ID     int             `json:"id"`
+			Method string          `json:"method"`
+			Params json.RawMessage `json:"params"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+
+		method = body.Method
+		params = body.Params
+
+		_, _ = w.Write([]byte(fmt.Sprintf(`{"jsonrpc": "2.0", "id": %d, "result": {}}`, body.ID)))
+	}))
+	defer server.Close()
+
+	config, _ := NewNsoJsonRpcConfig("http", "127.0.0.1", testServerPort(server), "admin", "admin", false)
+	_ = config.NsoLogin()
+
+	_, err := config.CancelConfirmedCommit()
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	if method != "cancel_commit" {
+		t.Errorf("expected %v got %v", "cancel_commit", method)
+	}
+
+	if string(params) != "{}" {
+		t.Errorf("expected %v got %v", "{}", string(params))
+	}
+
+}
@@ -0,0 +1,116 @@
+package nsojsonrpcrequestergo
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"io/ioutil"
+	"net/http"
+	"net/http/cookiejar"
+)
+
+// TransportRequest holds a JSON-RPC envelope ready to be sent to the NSO Server
+type TransportRequest struct {
+	URL    string
+	Header http.Header
+	Body   []byte
+}
+
+// TransportResponse holds the raw reply from the NSO Server
+type TransportResponse struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+}
+
+// Transport sends a JSON-RPC envelope to the NSO Server and returns the raw reply
+// A Transport has to keep the NSO session cookie between requests
+type Transport interface {
+	Send(ctx context.Context, request *TransportRequest) (*TransportResponse, error)
+}
+
+// HTTPTransport is the default Transport using net/http
+type HTTPTransport struct {
+	client *http.Client
+}
+
+// Constructor for a HTTPTransport
+//   :values sslVerify: true to verify SSL, false not to
+func NewHTTPTransport(sslVerify bool) *HTTPTransport {
+	jar, _ := cookiejar.New(nil)
+
+	roundTripper := http.DefaultTransport.(*http.Transport).Clone()
+	roundTripper.TLSClientConfig = &tls.Config{InsecureSkipVerify: !sslVerify}
+
+	return &HTTPTransport{client: &http.Client{Transport: roundTripper, Jar: jar}}
+
+}
+
+// Constructor for a HTTPTransport using TLSOptions
+//   :values sslVerify: true to verify SSL, false not to
+//   :values options: The *TLSOptions like CA files or pinned certificates
+func NewHTTPTransportWithTLS(sslVerify bool, options *TLSOptions) (*HTTPTransport, error) {
+	tlsConfig, err := options.TLSConfig()
+
+	if err != nil {
+		return &HTTPTransport{}, err
+	}
+
+	tlsConfig.InsecureSkipVerify = !sslVerify
+
+	jar, _ := cookiejar.New(nil)
+
+	roundTripper := http.DefaultTransport.(*http.Transport).Clone()
+	roundTripper.TLSClientConfig = tlsConfig
+
+	return &HTTPTransport{client: &http.Client{Transport: roundTripper, Jar: jar}}, nil
+
+}
+
+// Constructor for a HTTPTransport from a http.Client
+// A cookie jar is added to the client if it has none
+//   :values client: A *http.Client
+func NewHTTPTransportFromClient(client *http.Client) *HTTPTransport {
+	if client.Jar == nil {
+		client.Jar, _ = cookiejar.New(nil)
+	}
+
+	return &HTTPTransport{client: client}
+
+}
+
+// Method to send a JSON-RPC envelope to the NSO Server
+//   :values ctx: A context.Context to cancel the request
+//   :values request: A *TransportRequest
+func (t *HTTPTransport) Send(ctx context.Context, request *TransportRequest) (*TransportResponse, error) {
+	httpRequest, err := http.NewRequest(http.MethodPost, request.URL, bytes.NewReader(request.Body))
+
+	if err != nil {
+		return nil, err
+	}
+
+	httpRequest = httpRequest.WithContext(ctx)
+
+	for key, values := range request.Header {
+		for _, value := range values {
+			httpRequest.Header.Add(key, value)
+		}
+	}
+
+	httpResponse, err := t.client.Do(httpRequest)
+
+	if err != nil {
+		return nil, tlsError(err)
+	}
+
+	defer httpResponse.Body.Close()
+
+	body, err := ioutil.ReadAll(httpResponse.Body)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &TransportResponse{StatusCode: httpResponse.StatusCode, Header: httpResponse.Header, Body: body}, nil
+
+}
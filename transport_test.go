@@ -0,0 +1,131 @@
+package nsojsonrpcrequestergo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// fakeTransport answers every request with the result set for its method
+type fakeTransport struct {
+	results  map[string]string
+	requests []*TransportRequest
+}
+
+func (f *fakeTransport) Send(ctx context.Context, request *TransportRequest) (*TransportResponse, error) {
+	var body map[string]interface{}
+
+	if err := json.Unmarshal(request.Body, &body); err != nil {
+		return nil, err
+	}
+
+	f.requests = append(f.requests, request)
+
+	result, ok := f.results[body["method"].(string)]
+	if !ok {
+		result = "{}"
+	}
+
+	reply := fmt.Sprintf(`{"jsonrpc": "2.0", "id": %v, "result": %s}`, body["id"], result)
+
+	return &TransportResponse{StatusCode: 200, Body: []byte(reply)}, nil
+}
+
+func Test_NewNsoJsonRpcConfigWithTransport(t *testing.T) {
+	transport := &fakeTransport{results: map[string]string{
+		"new_trans": `{"th": 7}`,
+		"get_value": `{"value": "ok"}`,
+	}}
+
+	config, err := NewNsoJsonRpcConfigWithTransport("http", "192.168.1.1", 8080, "admin", "admin", transport)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	_ = config.NsoLogin()
+
+	trans, err := config.NewTransaction("read", "private", "", "reuse")
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if trans.Handle() != 7 {
+		t.Errorf("expected %v got %v", 7, trans.Handle())
+	}
+
+	response, err := config.GetValue("/ncs:devices/device{r1}/address", false)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if response.Result["value"] != "ok" {
+		t.Errorf("expected %v got %v", "ok", response.Result["value"])
+	}
+	if response.StatusCode != 200 {
+		t.Errorf("expected %v got %v", 200, response.StatusCode)
+	}
+
+	var body struct {
+		Result map[string]string `json:"result"`
+	}
+	if err := response.ToJSON(&body); err != nil || body.Result["value"] != "ok" {
+		t.Errorf("expected %v got %v", "ok", body.Result["value"])
+	}
+
+	request := transport.requests[0]
+	if request.URL != "http://192.168.1.1:8080/jsonrpc" {
+		t.Errorf("expected %v got %v", "http://192.168.1.1:8080/jsonrpc", request.URL)
+	}
+	if request.Header.Get("Content-Type") != "application/json" {
+		t.Errorf("expected %v got %v", "application/json", request.Header.Get("Content-Type"))
+	}
+
+}
+
+func Test_NewNsoJsonRpcConfigWithTransportNil(t *testing.T) {
+	_, err := NewNsoJsonRpcConfigWithTransport("http", "192.168.1.1", 8080, "admin", "admin", nil)
+	if err == nil || err.Error() != "a transport is required" {
+		t.Errorf("expected error %v got %v", "a transport is required", err)
+	}
+
+}
+
+func Test_HTTPTransport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Errorf("expected %v got %v", http.MethodPost, r.Method)
+		}
+		if r.Header.Get("Accept") != "application/json" {
+			t.Errorf("expected %v got %v", "application/json", r.Header.Get("Accept"))
+		}
+
+		_, err := r.Cookie("sessionid")
+		if r.URL.Query().Get("second") != "" && err != nil {
+			t.Errorf("expected the session cookie to be sent")
+		}
+
+		http.SetCookie(w, &http.Cookie{Name: "sessionid", Value: "abc"})
+		w.WriteHeader(http.StatusAccepted)
+		_, _ = w.Write([]byte(`{"jsonrpc": "2.0"}`))
+	}))
+	defer server.Close()
+
+	transport := NewHTTPTransport(true)
+	header := http.Header{}
+	header.Set("Accept", "application/json")
+
+	for _, url := range []string{server.URL, server.URL + "?second=1"} {
+		response, err := transport.Send(context.Background(), &TransportRequest{URL: url, Header: header, Body: []byte(`{}`)})
+		if err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		if response.StatusCode != http.StatusAccepted {
+			t.Errorf("expected %v got %v", http.StatusAccepted, response.StatusCode)
+		}
+		if string(response.Body) != `{"jsonrpc": "2.0"}` {
+			t.Errorf("expected %v got %v", `{"jsonrpc": "2.0"}`, string(response.Body))
+		}
+	}
+
+}